@@ -1,7 +1,30 @@
 package agent
 
-import "context"
+import (
+	"context"
+	"time"
+)
 
 type DecisionAgent interface {
-	Decide(ctx context.Context, snap Snapshot) (Decision, error)
+	// Decide returns the cycle's decisions, at least one and at most one per symbol, in execution
+	// order, together with the record of the model call behind them. Call is zero when no model was
+	// asked (e.g. a hosted provider without an API key).
+	Decide(ctx context.Context, snap Snapshot) ([]Decision, Call, error)
+}
+
+// Call is what one Decide sent to the model and got back, kept for audits and replays.
+// The repair fields are set only when an invalid answer was sent back for correction; latency
+// and token counts cover both round trips.
+type Call struct {
+	Provider         string
+	Model            string
+	PromptID         int64 // stored prompt version; zero for the built-in templates
+	System           string
+	User             string
+	Response         string
+	RepairPrompt     string
+	RepairResponse   string
+	Latency          time.Duration
+	PromptTokens     int
+	CompletionTokens int
 }
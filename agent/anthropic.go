@@ -0,0 +1,86 @@
+package agent
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+)
+
+const anthropicVersion = "2023-06-01"
+
+// anthropicProvider speaks the Anthropic Messages API. It has no JSON response mode, so JSON
+// requests prefill the assistant turn with "{" and the brace is put back on the answer.
+type anthropicProvider struct {
+	http *http.Client
+	url  string
+	cfg  ProviderConfig
+}
+
+func (p *anthropicProvider) Complete(ctx context.Context, c Completion) (CompletionResult, error) {
+	maxTokens := c.MaxTokens
+	if maxTokens <= 0 {
+		maxTokens = 4096
+	}
+	request := AnthropicRequest{
+		Model:       p.cfg.Model,
+		System:      c.System,
+		Messages:    []RequestMessage{{Role: "user", Content: c.User}},
+		MaxTokens:   maxTokens,
+		Temperature: c.Temperature,
+	}
+	if c.JSON {
+		request.Messages = append(request.Messages, RequestMessage{Role: "assistant", Content: "{"})
+	}
+
+	b, err := json.Marshal(request)
+	if err != nil {
+		return CompletionResult{}, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.url, bytes.NewReader(b))
+	if err != nil {
+		return CompletionResult{}, err
+	}
+	req.Header.Set("x-api-key", p.cfg.APIKey)
+	req.Header.Set("anthropic-version", anthropicVersion)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := p.http.Do(req)
+	if err != nil {
+		return CompletionResult{}, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return CompletionResult{}, httpError(p.cfg.Provider, resp)
+	}
+
+	var out AnthropicResponse
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return CompletionResult{}, err
+	}
+
+	var text strings.Builder
+	for _, block := range out.Content {
+		if block.Type == "text" {
+			text.WriteString(block.Text)
+		}
+	}
+	if text.Len() == 0 {
+		return CompletionResult{}, errors.New("empty completion")
+	}
+	content := text.String()
+	if c.JSON {
+		content = "{" + content
+	}
+
+	return CompletionResult{
+		Content:          content,
+		Model:            out.Model,
+		PromptTokens:     out.Usage.InputTokens,
+		CompletionTokens: out.Usage.OutputTokens,
+	}, nil
+}
@@ -0,0 +1,37 @@
+package agent
+
+import (
+	"deepseek-trader/hyperliquid"
+	"deepseek-trader/indicators"
+)
+
+// IndicatorHistory is how many candles per coin and interval the snapshot builders should feed into
+// Indicators, independent of how many raw candles they pass to the agent.
+const IndicatorHistory = 100
+
+// Indicators computes the features per coin and interval from candle history (oldest first).
+func Indicators(history map[string]map[string][]hyperliquid.Candle) map[string]map[string]indicators.Features {
+	out := make(map[string]map[string]indicators.Features, len(history))
+	for coin, byInterval := range history {
+		features := make(map[string]indicators.Features, len(byInterval))
+		for interval, list := range byInterval {
+			if len(list) > IndicatorHistory {
+				list = list[len(list)-IndicatorHistory:]
+			}
+			features[interval] = indicators.Compute(list)
+		}
+		out[coin] = features
+	}
+	return out
+}
+
+// Books summarizes spread and depth per coin.
+func Books(books []hyperliquid.OrderBookSnapshot) map[string]indicators.Book {
+	out := make(map[string]indicators.Book, len(books))
+	for _, b := range books {
+		if stats, ok := indicators.BookFeatures(b); ok {
+			out[b.Coin] = stats
+		}
+	}
+	return out
+}
@@ -0,0 +1,117 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// LLMAgent asks a language model for a trading decision. The prompts are shared by every provider;
+// only the transport differs. Answers are validated before they are returned.
+type LLMAgent struct {
+	provider Provider
+	cfg      ProviderConfig
+	validate ValidationConfig
+	prompt   *Prompt
+}
+
+// NewLLMAgent builds an agent that renders its prompts from prompt, or the built-in templates when nil.
+func NewLLMAgent(cfg ProviderConfig, validate ValidationConfig, prompt *Prompt) (*LLMAgent, error) {
+	p, err := NewProvider(cfg)
+	if err != nil {
+		return nil, err
+	}
+	if prompt == nil {
+		prompt = DefaultPrompt()
+	}
+	return &LLMAgent{provider: p, cfg: cfg, validate: validate, prompt: prompt}, nil
+}
+
+// Decide returns the validated decisions of one cycle in execution order. When the answer breaks the
+// schema it returns the parsed decisions together with a *ValidationError, after at most one repair
+// round trip if repairs are enabled. The returned Call holds the prompts and answers even when the
+// request failed.
+func (a *LLMAgent) Decide(ctx context.Context, snap Snapshot) ([]Decision, Call, error) {
+	none := []Decision{{Action: "none"}}
+	if a.cfg.RequiresAPIKey() && a.cfg.APIKey == "" {
+		return none, Call{}, nil
+	}
+
+	provider := a.cfg.Provider
+	if provider == "" {
+		provider = ProviderDeepseek
+	}
+	system, user, err := a.prompt.Render(snap, a.validate.MaxDecisions)
+	if err != nil {
+		return none, Call{}, fmt.Errorf("failed to render prompt %d: %w", a.prompt.ID, err)
+	}
+	call := Call{
+		Provider: provider,
+		Model:    a.cfg.Model,
+		PromptID: a.prompt.ID,
+		System:   system,
+		User:     user,
+	}
+	content, err := a.complete(ctx, &call, call.User)
+	if err != nil {
+		return none, call, err
+	}
+	call.Response = content
+
+	decs, err := ParseDecisions(content, snap, a.validate)
+	var verr *ValidationError
+	if !errors.As(err, &verr) || !a.validate.Repair {
+		return decs, call, err
+	}
+
+	call.RepairPrompt = buildRepairPrompt(call.User, content, verr)
+	repaired, rerr := a.complete(ctx, &call, call.RepairPrompt)
+	if rerr != nil {
+		return decs, call, err
+	}
+	call.RepairResponse = repaired
+	decs, err = ParseDecisions(repaired, snap, a.validate)
+	return decs, call, err
+}
+
+// complete sends one user prompt and adds its latency and usage to call.
+func (a *LLMAgent) complete(ctx context.Context, call *Call, user string) (string, error) {
+	start := time.Now()
+	res, err := a.provider.Complete(ctx, Completion{
+		System:      call.System,
+		User:        user,
+		JSON:        true,
+		Temperature: a.cfg.Temperature,
+		MaxTokens:   a.cfg.MaxTokens,
+	})
+	call.Latency += time.Since(start)
+	if err != nil {
+		return "", err
+	}
+	if res.Model != "" {
+		call.Model = res.Model
+	}
+	call.PromptTokens += res.PromptTokens
+	call.CompletionTokens += res.CompletionTokens
+	return res.Content, nil
+}
+
+// describeTimeframes lists the snapshot's timeframes, e.g. "15m candles over the last 3h; 4h candles over the last 5d".
+func describeTimeframes(tfs []Timeframe) string {
+	parts := make([]string, 0, len(tfs))
+	for _, tf := range tfs {
+		parts = append(parts, tf.String())
+	}
+	return strings.Join(parts, "; ")
+}
+
+func buildRepairPrompt(user, answer string, verr *ValidationError) string {
+	return fmt.Sprintf(repairPromptTemplate, user, answer, "- "+strings.Join(verr.Errors, "\n- "))
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'f', 2, 64)
+}
@@ -1,14 +1,38 @@
 package agent
 
-import "deepseek-trader/hyperliquid"
+import (
+	"encoding/json"
+
+	"deepseek-trader/hyperliquid"
+	"deepseek-trader/indicators"
+)
 
 type Decision struct {
-	Action     string  `json:"action"` // buy|sell|none
+	Action     string  `json:"action"` // buy|sell|none|close|reduce|reverse|adjust
 	Symbol     string  `json:"symbol"` // e.g., BTCUSDT
 	Size       float64 `json:"size"`   // in base units
 	Order      string  `json:"order"`  // market|limit
 	LimitPrice float64 `json:"limitPrice"`
-	Targets    Targets `json:"targets"`
+	// Fraction is the share of the open position a reduce closes, between 0 and 1.
+	Fraction float64 `json:"fraction,omitempty"`
+	Targets  Targets `json:"targets"`
+	// Confidence in the decision, 0-100.
+	Confidence float64 `json:"confidence"`
+	// Scores rates every analysed symbol 0-100, keyed like Symbol.
+	Scores    map[string]float64 `json:"scores,omitempty"`
+	Rationale string             `json:"rationale,omitempty"`
+}
+
+// ScoresJSON returns the scores as a JSON object, or nil when there are none.
+func (d Decision) ScoresJSON() json.RawMessage {
+	if len(d.Scores) == 0 {
+		return nil
+	}
+	b, err := json.Marshal(d.Scores)
+	if err != nil {
+		return nil
+	}
+	return b
 }
 
 type Targets struct {
@@ -19,16 +43,35 @@ type Targets struct {
 }
 
 type Snapshot struct {
-	Balance         float64                         `json:"balance"`
-	PnL             float64                         `json:"pnl"`
-	ROE             float64                         `json:"roe"`
-	Trades          []interface{}                   `json:"trades"` // minimal for now
-	CoinsMids       map[string]string               `json:"coinsMids"`
-	Runtime         Runtime                         `json:"runtime,omitempty"`
-	Decisions       []interface{}                   `json:"decisions"`
-	Meta            hyperliquid.ExchangeMeta        `json:"meta"`
-	OrderBooks      []hyperliquid.OrderBookSnapshot `json:"orderBooks"`
-	CandleSnapshots map[string][]hyperliquid.Candle `json:"candleSnapshots"`
+	Balance    float64                         `json:"balance"`
+	PnL        float64                         `json:"pnl"`
+	ROE        float64                         `json:"roe"`
+	MarginUsed float64                         `json:"marginUsed"`
+	Health     hyperliquid.MarginHealth        `json:"marginHealth"`
+	Positions  []hyperliquid.Position          `json:"positions"`
+	OpenOrders []hyperliquid.OpenOrder         `json:"openOrders"`
+	Trades     []interface{}                   `json:"trades"` // minimal for now
+	CoinsMids  map[string]string               `json:"coinsMids"`
+	Runtime    Runtime                         `json:"runtime,omitempty"`
+	Decisions  []interface{}                   `json:"decisions"`
+	Meta       hyperliquid.ExchangeMeta        `json:"meta"`
+	OrderBooks []hyperliquid.OrderBookSnapshot `json:"orderBooks"`
+	Timeframes []Timeframe                     `json:"timeframes"`
+	// CandleSnapshots holds each timeframe's window, keyed by coin and then interval.
+	CandleSnapshots map[string]map[string][]hyperliquid.Candle `json:"candleSnapshots"`
+	// Indicators are precomputed per coin and interval from a longer history than CandleSnapshots.
+	Indicators map[string]map[string]indicators.Features `json:"indicators"`
+	Books      map[string]indicators.Book                `json:"books"`
+}
+
+// Position returns the signed size of the open position in coin, zero when there is none.
+func (s Snapshot) Position(coin string) float64 {
+	for _, p := range s.Positions {
+		if p.Coin == coin {
+			return p.Size
+		}
+	}
+	return 0
 }
 
 type Runtime struct {
@@ -44,11 +87,13 @@ type AgentConfig struct {
 	InitialCash float64 `json:"initialCash,omitempty"`
 }
 
-type DeepseekRequest struct {
+// ChatRequest is an OpenAI-compatible chat-completions request.
+type ChatRequest struct {
 	Model          string           `json:"model"`
 	Messages       []RequestMessage `json:"messages"`
 	Temperature    float64          `json:"temperature"`
-	ResponseFormat ResponseFormat   `json:"response_format"`
+	MaxTokens      int              `json:"max_tokens,omitempty"`
+	ResponseFormat *ResponseFormat  `json:"response_format,omitempty"`
 }
 
 type RequestMessage struct {
@@ -60,8 +105,10 @@ type ResponseFormat struct {
 	Type string `json:"type"`
 }
 
-type DeepseekResponse struct {
-	Choices []Choice `json:"choices"`
+type ChatResponse struct {
+	Model   string    `json:"model"`
+	Choices []Choice  `json:"choices"`
+	Usage   ChatUsage `json:"usage"`
 }
 
 type Choice struct {
@@ -72,6 +119,57 @@ type Message struct {
 	Content string `json:"content"`
 }
 
+type ChatUsage struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+}
+
+// AnthropicRequest is a Messages API request.
+type AnthropicRequest struct {
+	Model       string           `json:"model"`
+	System      string           `json:"system,omitempty"`
+	Messages    []RequestMessage `json:"messages"`
+	MaxTokens   int              `json:"max_tokens"`
+	Temperature float64          `json:"temperature"`
+}
+
+type AnthropicResponse struct {
+	Model   string             `json:"model"`
+	Content []AnthropicContent `json:"content"`
+	Usage   AnthropicUsage     `json:"usage"`
+}
+
+type AnthropicContent struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+type AnthropicUsage struct {
+	InputTokens  int `json:"input_tokens"`
+	OutputTokens int `json:"output_tokens"`
+}
+
+// OllamaRequest is an Ollama /api/chat request.
+type OllamaRequest struct {
+	Model    string           `json:"model"`
+	Messages []RequestMessage `json:"messages"`
+	Stream   bool             `json:"stream"`
+	Format   string           `json:"format,omitempty"`
+	Options  OllamaOptions    `json:"options"`
+}
+
+type OllamaOptions struct {
+	Temperature float64 `json:"temperature"`
+	NumPredict  int     `json:"num_predict,omitempty"`
+}
+
+type OllamaResponse struct {
+	Model           string  `json:"model"`
+	Message         Message `json:"message"`
+	PromptEvalCount int     `json:"prompt_eval_count"`
+	EvalCount       int     `json:"eval_count"`
+}
+
 var Coins = []string{
 	"BTC",
 	"ETH",
@@ -0,0 +1,67 @@
+package agent
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+)
+
+// ollamaProvider talks to a local Ollama server's /api/chat endpoint.
+type ollamaProvider struct {
+	http *http.Client
+	url  string
+	cfg  ProviderConfig
+}
+
+func (p *ollamaProvider) Complete(ctx context.Context, c Completion) (CompletionResult, error) {
+	request := OllamaRequest{
+		Model: p.cfg.Model,
+		Messages: []RequestMessage{
+			{Role: "system", Content: c.System},
+			{Role: "user", Content: c.User},
+		},
+		Stream:  false,
+		Options: OllamaOptions{Temperature: c.Temperature, NumPredict: c.MaxTokens},
+	}
+	if c.JSON {
+		request.Format = "json"
+	}
+
+	b, err := json.Marshal(request)
+	if err != nil {
+		return CompletionResult{}, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.url, bytes.NewReader(b))
+	if err != nil {
+		return CompletionResult{}, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := p.http.Do(req)
+	if err != nil {
+		return CompletionResult{}, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return CompletionResult{}, httpError(p.cfg.Provider, resp)
+	}
+
+	var out OllamaResponse
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return CompletionResult{}, err
+	}
+	if out.Message.Content == "" {
+		return CompletionResult{}, errors.New("empty completion")
+	}
+
+	return CompletionResult{
+		Content:          out.Message.Content,
+		Model:            out.Model,
+		PromptTokens:     out.PromptEvalCount,
+		CompletionTokens: out.EvalCount,
+	}, nil
+}
@@ -0,0 +1,71 @@
+package agent
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+)
+
+// openAIProvider speaks the OpenAI chat-completions protocol: OpenAI, DeepSeek, llama.cpp and
+// any other compatible endpoint.
+type openAIProvider struct {
+	http *http.Client
+	url  string
+	cfg  ProviderConfig
+}
+
+func (p *openAIProvider) Complete(ctx context.Context, c Completion) (CompletionResult, error) {
+	request := ChatRequest{
+		Model: p.cfg.Model,
+		Messages: []RequestMessage{
+			{Role: "system", Content: c.System},
+			{Role: "user", Content: c.User},
+		},
+		Temperature: c.Temperature,
+		MaxTokens:   c.MaxTokens,
+	}
+	if c.JSON {
+		request.ResponseFormat = &ResponseFormat{Type: "json_object"}
+	}
+
+	b, err := json.Marshal(request)
+	if err != nil {
+		return CompletionResult{}, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.url, bytes.NewReader(b))
+	if err != nil {
+		return CompletionResult{}, err
+	}
+	if p.cfg.APIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+p.cfg.APIKey)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := p.http.Do(req)
+	if err != nil {
+		return CompletionResult{}, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return CompletionResult{}, httpError(p.cfg.Provider, resp)
+	}
+
+	var out ChatResponse
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return CompletionResult{}, err
+	}
+	if len(out.Choices) == 0 {
+		return CompletionResult{}, errors.New("empty completion")
+	}
+
+	return CompletionResult{
+		Content:          out.Choices[0].Message.Content,
+		Model:            out.Model,
+		PromptTokens:     out.Usage.PromptTokens,
+		CompletionTokens: out.Usage.CompletionTokens,
+	}, nil
+}
@@ -0,0 +1,130 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Supported LLM providers. ProviderLlamaCpp talks to llama.cpp's OpenAI-compatible server.
+const (
+	ProviderDeepseek  = "deepseek"
+	ProviderOpenAI    = "openai"
+	ProviderAnthropic = "anthropic"
+	ProviderOllama    = "ollama"
+	ProviderLlamaCpp  = "llamacpp"
+)
+
+// ProviderConfig selects and configures the model a bot talks to.
+type ProviderConfig struct {
+	Provider    string        `json:"provider"`
+	BaseURL     string        `json:"baseUrl,omitempty"`
+	Model       string        `json:"model"`
+	APIKey      string        `json:"-"`
+	Temperature float64       `json:"temperature"`
+	MaxTokens   int           `json:"maxTokens,omitempty"`
+	Timeout     time.Duration `json:"timeout,omitempty"`
+}
+
+// Completion is one chat turn sent to a provider.
+type Completion struct {
+	System      string
+	User        string
+	JSON        bool // ask the model for a single JSON object
+	Temperature float64
+	MaxTokens   int
+}
+
+// CompletionResult is the provider's answer plus what it reported about the call.
+type CompletionResult struct {
+	Content          string
+	Model            string
+	PromptTokens     int
+	CompletionTokens int
+}
+
+// Provider sends a system/user prompt pair to a model and returns its text answer.
+type Provider interface {
+	Complete(ctx context.Context, req Completion) (CompletionResult, error)
+}
+
+// NewProvider builds the provider named in cfg.
+func NewProvider(cfg ProviderConfig) (Provider, error) {
+	timeout := cfg.Timeout
+	if timeout <= 0 {
+		timeout = 20 * time.Minute
+	}
+	httpClient := &http.Client{Timeout: timeout}
+
+	switch strings.ToLower(cfg.Provider) {
+	case "", ProviderDeepseek:
+		return &openAIProvider{http: httpClient, url: chatCompletionsURL(cfg.BaseURL, "https://api.deepseek.com"), cfg: cfg}, nil
+	case ProviderOpenAI:
+		return &openAIProvider{http: httpClient, url: chatCompletionsURL(cfg.BaseURL, "https://api.openai.com"), cfg: cfg}, nil
+	case ProviderLlamaCpp:
+		return &openAIProvider{http: httpClient, url: chatCompletionsURL(cfg.BaseURL, "http://localhost:8080"), cfg: cfg}, nil
+	case ProviderAnthropic:
+		return &anthropicProvider{http: httpClient, url: trimURL(cfg.BaseURL, "https://api.anthropic.com") + "/v1/messages", cfg: cfg}, nil
+	case ProviderOllama:
+		return &ollamaProvider{http: httpClient, url: trimURL(cfg.BaseURL, "http://localhost:11434") + "/api/chat", cfg: cfg}, nil
+	default:
+		return nil, fmt.Errorf("unknown llm provider %q", cfg.Provider)
+	}
+}
+
+// Override returns cfg with the non-empty arguments applied. Switching to another provider starts
+// from a clean config so the old provider's URL, model and key are not carried over; apiKey is the
+// key used for the new provider.
+func (cfg ProviderConfig) Override(provider, model, baseURL, apiKey string) ProviderConfig {
+	if provider != "" && provider != cfg.Provider {
+		cfg = ProviderConfig{
+			Provider:    provider,
+			APIKey:      apiKey,
+			Temperature: cfg.Temperature,
+			MaxTokens:   cfg.MaxTokens,
+			Timeout:     cfg.Timeout,
+		}
+	}
+	if model != "" {
+		cfg.Model = model
+	}
+	if baseURL != "" {
+		cfg.BaseURL = baseURL
+	}
+	return cfg
+}
+
+// RequiresAPIKey reports whether the provider is a hosted API that cannot be called without a key.
+func (cfg ProviderConfig) RequiresAPIKey() bool {
+	switch strings.ToLower(cfg.Provider) {
+	case ProviderOllama, ProviderLlamaCpp:
+		return false
+	default:
+		return true
+	}
+}
+
+func trimURL(url, def string) string {
+	if url == "" {
+		url = def
+	}
+	return strings.TrimRight(url, "/")
+}
+
+// chatCompletionsURL accepts base URLs with or without the /v1 suffix.
+func chatCompletionsURL(base, def string) string {
+	url := trimURL(base, def)
+	if strings.HasSuffix(url, "/v1") {
+		return url + "/chat/completions"
+	}
+	return url + "/v1/chat/completions"
+}
+
+// httpError reads a short excerpt of a failed response body for the error message.
+func httpError(provider string, resp *http.Response) error {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	return fmt.Errorf("%s http error: status %d: %s", provider, resp.StatusCode, strings.TrimSpace(string(body)))
+}
@@ -0,0 +1,194 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestProviders(t *testing.T) {
+	completion := Completion{System: "sys", User: "usr", JSON: true, Temperature: 0.2, MaxTokens: 100}
+	tests := []struct {
+		name     string
+		provider string
+		c        Completion
+		status   int
+		answer   string
+		wantPath string
+		// wantBody holds the request fields to check, by top-level JSON key.
+		wantBody   map[string]string
+		wantHeader map[string]string
+		want       CompletionResult
+		wantErr    string
+	}{
+		{
+			name: "openai", provider: ProviderOpenAI, c: completion, status: http.StatusOK,
+			answer:   `{"model":"gpt-x","choices":[{"message":{"content":"{\"a\":1}"}}],"usage":{"prompt_tokens":12,"completion_tokens":3}}`,
+			wantPath: "/v1/chat/completions",
+			wantBody: map[string]string{
+				"model": `"m"`, "temperature": `0.2`, "max_tokens": `100`, "response_format": `{"type":"json_object"}`,
+				"messages": `[{"role":"system","content":"sys"},{"role":"user","content":"usr"}]`,
+			},
+			wantHeader: map[string]string{"Authorization": "Bearer key"},
+			want:       CompletionResult{Content: `{"a":1}`, Model: "gpt-x", PromptTokens: 12, CompletionTokens: 3},
+		},
+		{
+			name: "deepseek as text", provider: ProviderDeepseek, c: Completion{System: "sys", User: "usr"}, status: http.StatusOK,
+			answer:   `{"model":"deepseek-chat","choices":[{"message":{"content":"hi"}}]}`,
+			wantPath: "/v1/chat/completions",
+			wantBody: map[string]string{"response_format": "", "max_tokens": ""},
+			want:     CompletionResult{Content: "hi", Model: "deepseek-chat"},
+		},
+		{
+			name: "openai without choices", provider: ProviderOpenAI, c: completion, status: http.StatusOK,
+			answer: `{"model":"gpt-x","choices":[]}`, wantPath: "/v1/chat/completions", wantErr: "empty completion",
+		},
+		{
+			name: "openai http error", provider: ProviderLlamaCpp, c: completion, status: http.StatusTooManyRequests,
+			answer: `{"error":"rate limited"}`, wantPath: "/v1/chat/completions", wantErr: `llamacpp http error: status 429: {"error":"rate limited"}`,
+		},
+		{
+			name: "openai malformed answer", provider: ProviderOpenAI, c: completion, status: http.StatusOK,
+			answer: `{"choices":`, wantPath: "/v1/chat/completions", wantErr: "unexpected EOF",
+		},
+		{
+			name: "anthropic prefills and restores the brace", provider: ProviderAnthropic, c: completion, status: http.StatusOK,
+			answer:   `{"model":"claude-x","content":[{"type":"text","text":"\"a\":"},{"type":"tool_use"},{"type":"text","text":"1}"}],"usage":{"input_tokens":20,"output_tokens":4}}`,
+			wantPath: "/v1/messages",
+			wantBody: map[string]string{
+				"system": `"sys"`, "max_tokens": `100`,
+				"messages": `[{"role":"user","content":"usr"},{"role":"assistant","content":"{"}]`,
+			},
+			wantHeader: map[string]string{"x-api-key": "key", "anthropic-version": anthropicVersion},
+			want:       CompletionResult{Content: `{"a":1}`, Model: "claude-x", PromptTokens: 20, CompletionTokens: 4},
+		},
+		{
+			name: "anthropic default max tokens", provider: ProviderAnthropic, c: Completion{User: "usr"}, status: http.StatusOK,
+			answer:   `{"model":"claude-x","content":[{"type":"text","text":"hi"}]}`,
+			wantPath: "/v1/messages",
+			wantBody: map[string]string{"max_tokens": `4096`, "system": "", "messages": `[{"role":"user","content":"usr"}]`},
+			want:     CompletionResult{Content: "hi", Model: "claude-x"},
+		},
+		{
+			name: "anthropic without text", provider: ProviderAnthropic, c: completion, status: http.StatusOK,
+			answer: `{"model":"claude-x","content":[]}`, wantPath: "/v1/messages", wantErr: "empty completion",
+		},
+		{
+			name: "ollama", provider: ProviderOllama, c: completion, status: http.StatusOK,
+			answer:   `{"model":"llama3.1","message":{"role":"assistant","content":"{}"},"prompt_eval_count":30,"eval_count":2}`,
+			wantPath: "/api/chat",
+			wantBody: map[string]string{
+				"stream": `false`, "format": `"json"`, "options": `{"temperature":0.2,"num_predict":100}`,
+				"messages": `[{"role":"system","content":"sys"},{"role":"user","content":"usr"}]`,
+			},
+			wantHeader: map[string]string{"Authorization": ""},
+			want:       CompletionResult{Content: "{}", Model: "llama3.1", PromptTokens: 30, CompletionTokens: 2},
+		},
+		{
+			name: "ollama without content", provider: ProviderOllama, c: completion, status: http.StatusOK,
+			answer: `{"model":"llama3.1","message":{"role":"assistant","content":""}}`, wantPath: "/api/chat", wantErr: "empty completion",
+		},
+		{
+			name: "ollama http error", provider: ProviderOllama, c: completion, status: http.StatusNotFound,
+			answer: `model not found`, wantPath: "/api/chat", wantErr: "ollama http error: status 404: model not found",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != tt.wantPath {
+					t.Errorf("path = %s, want %s", r.URL.Path, tt.wantPath)
+				}
+				for k, want := range tt.wantHeader {
+					if got := r.Header.Get(k); got != want {
+						t.Errorf("header %s = %q, want %q", k, got, want)
+					}
+				}
+				var body map[string]json.RawMessage
+				if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+					t.Errorf("request body: %v", err)
+				}
+				for k, want := range tt.wantBody {
+					if got := string(body[k]); got != want {
+						t.Errorf("request %s = %s, want %s", k, got, want)
+					}
+				}
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte(tt.answer))
+			}))
+			defer srv.Close()
+
+			apiKey := "key"
+			if tt.provider == ProviderOllama {
+				apiKey = ""
+			}
+			p, err := NewProvider(ProviderConfig{Provider: tt.provider, BaseURL: srv.URL, Model: "m", APIKey: apiKey})
+			if err != nil {
+				t.Fatalf("NewProvider: %v", err)
+			}
+			got, err := p.Complete(context.Background(), tt.c)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Complete error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Complete: %v", err)
+			}
+			if got != tt.want {
+				t.Fatalf("Complete = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewProviderUnknown(t *testing.T) {
+	if _, err := NewProvider(ProviderConfig{Provider: "gemini"}); err == nil || !strings.Contains(err.Error(), `unknown llm provider "gemini"`) {
+		t.Fatalf("NewProvider = %v, want an unknown provider error", err)
+	}
+}
+
+func TestChatCompletionsURL(t *testing.T) {
+	tests := []struct{ base, want string }{
+		{"", "https://api.openai.com/v1/chat/completions"},
+		{"http://host:8080", "http://host:8080/v1/chat/completions"},
+		{"http://host:8080/", "http://host:8080/v1/chat/completions"},
+		{"http://host:8080/v1", "http://host:8080/v1/chat/completions"},
+		{"http://host:8080/v1/", "http://host:8080/v1/chat/completions"},
+	}
+	for _, tt := range tests {
+		if got := chatCompletionsURL(tt.base, "https://api.openai.com"); got != tt.want {
+			t.Errorf("chatCompletionsURL(%q) = %q, want %q", tt.base, got, tt.want)
+		}
+	}
+}
+
+func TestProviderConfigOverride(t *testing.T) {
+	base := ProviderConfig{Provider: ProviderDeepseek, BaseURL: "https://ds", Model: "deepseek-chat", APIKey: "ds-key", Temperature: 0.3, MaxTokens: 500}
+	tests := []struct {
+		name                             string
+		provider, model, baseURL, apiKey string
+		want                             ProviderConfig
+	}{
+		{name: "nothing", want: base},
+		{name: "model only", model: "deepseek-reasoner",
+			want: ProviderConfig{Provider: ProviderDeepseek, BaseURL: "https://ds", Model: "deepseek-reasoner", APIKey: "ds-key", Temperature: 0.3, MaxTokens: 500}},
+		{name: "same provider keeps the rest", provider: ProviderDeepseek, baseURL: "https://proxy",
+			want: ProviderConfig{Provider: ProviderDeepseek, BaseURL: "https://proxy", Model: "deepseek-chat", APIKey: "ds-key", Temperature: 0.3, MaxTokens: 500}},
+		{name: "other provider starts clean", provider: ProviderOllama, model: "llama3.1",
+			want: ProviderConfig{Provider: ProviderOllama, Model: "llama3.1", Temperature: 0.3, MaxTokens: 500}},
+		{name: "other provider takes its key", provider: ProviderAnthropic, model: "claude-x", apiKey: "an-key",
+			want: ProviderConfig{Provider: ProviderAnthropic, Model: "claude-x", APIKey: "an-key", Temperature: 0.3, MaxTokens: 500}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := base.Override(tt.provider, tt.model, tt.baseURL, tt.apiKey); got != tt.want {
+				t.Fatalf("Override = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
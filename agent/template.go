@@ -0,0 +1,127 @@
+package agent
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"text/template"
+
+	"deepseek-trader/hyperliquid"
+)
+
+// Prompt is a compiled pair of system and user prompt templates (text/template, executed with
+// PromptData). ID is the stored version it was compiled from; zero for the built-in templates.
+type Prompt struct {
+	ID     int64
+	system *template.Template
+	user   *template.Template
+}
+
+// PromptData is what prompt templates are executed with: the typed snapshot plus values that are
+// awkward to derive in a template.
+type PromptData struct {
+	Snapshot      Snapshot
+	Balance       string // two decimals
+	PnL           string // two decimals
+	ROE           string // percent, two decimals
+	Timeframes    string // e.g. "15m candles over the last 3h; 1h candles over the last 1d"
+	EntryInterval string // interval of the shortest timeframe
+	JSON          string // the whole snapshot, indented
+	MaxDecisions  int    // symbols the agent may act on this cycle, at least 1
+}
+
+var promptFuncs = template.FuncMap{
+	"money": formatFloat,
+	"json": func(v any) (string, error) {
+		b, err := json.Marshal(v)
+		return string(b), err
+	},
+	"join": strings.Join,
+	"pct":  func(f float64) float64 { return f * 100 },
+}
+
+// DefaultTemplates returns the built-in system and user templates.
+func DefaultTemplates() (system, user string) {
+	return systemPromptTemplate, userPromptTemplate
+}
+
+// DefaultPrompt compiles the built-in templates.
+func DefaultPrompt() *Prompt {
+	p, err := NewPrompt(0, systemPromptTemplate, userPromptTemplate)
+	if err != nil {
+		panic(err)
+	}
+	return p
+}
+
+// NewPrompt compiles a template pair and renders it once against a sample snapshot with several
+// decisions allowed, so unknown fields and functions are reported here rather than on the next
+// decision cycle.
+func NewPrompt(id int64, system, user string) (*Prompt, error) {
+	if strings.TrimSpace(system) == "" || strings.TrimSpace(user) == "" {
+		return nil, fmt.Errorf("system and user templates are required")
+	}
+	st, err := template.New("system").Funcs(promptFuncs).Option("missingkey=error").Parse(system)
+	if err != nil {
+		return nil, err
+	}
+	ut, err := template.New("user").Funcs(promptFuncs).Option("missingkey=error").Parse(user)
+	if err != nil {
+		return nil, err
+	}
+	p := &Prompt{ID: id, system: st, user: ut}
+	if _, _, err := p.Render(sampleSnapshot(), 2); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// Render executes both templates with the snapshot and the number of decisions allowed per cycle.
+func (p *Prompt) Render(s Snapshot, maxDecisions int) (system, user string, err error) {
+	data, err := promptData(s, maxDecisions)
+	if err != nil {
+		return "", "", err
+	}
+	var sb, ub bytes.Buffer
+	if err := p.system.Execute(&sb, data); err != nil {
+		return "", "", err
+	}
+	if err := p.user.Execute(&ub, data); err != nil {
+		return "", "", err
+	}
+	return sb.String(), ub.String(), nil
+}
+
+func promptData(s Snapshot, maxDecisions int) (PromptData, error) {
+	raw, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return PromptData{}, err
+	}
+	return PromptData{
+		Snapshot:      s,
+		Balance:       formatFloat(s.Balance),
+		PnL:           formatFloat(s.PnL),
+		ROE:           formatFloat(s.ROE * 100),
+		Timeframes:    describeTimeframes(s.Timeframes),
+		EntryInterval: Shortest(s.Timeframes).Interval,
+		JSON:          string(raw),
+		MaxDecisions:  max(maxDecisions, 1),
+	}, nil
+}
+
+// sampleSnapshot has one element in every collection, so a trial render reaches every branch of
+// a template that ranges over them.
+func sampleSnapshot() Snapshot {
+	tfs := DefaultTimeframes()
+	return Snapshot{
+		Trades:          []interface{}{map[string]any{}},
+		Positions:       []hyperliquid.Position{{Coin: "BTC"}},
+		OpenOrders:      []hyperliquid.OpenOrder{{Coin: "BTC"}},
+		CoinsMids:       map[string]string{"BTC": "0"},
+		Decisions:       []interface{}{map[string]any{}},
+		OrderBooks:      []hyperliquid.OrderBookSnapshot{{Coin: "BTC"}},
+		Timeframes:      tfs,
+		CandleSnapshots: map[string]map[string][]hyperliquid.Candle{"BTC": {tfs[0].Interval: {{}}}},
+	}
+}
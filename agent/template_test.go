@@ -0,0 +1,85 @@
+package agent
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNewPrompt(t *testing.T) {
+	tests := []struct {
+		name         string
+		system, user string
+		wantErr      string
+	}{
+		{name: "built-in", system: systemPromptTemplate, user: userPromptTemplate},
+		{name: "plain text", system: "You trade.", user: "Balance {{.Balance}}, up to {{.MaxDecisions}} decisions."},
+		{name: "missing system", system: "  ", user: "{{.Balance}}", wantErr: "system and user templates are required"},
+		{name: "missing user", system: "You trade.", user: "", wantErr: "system and user templates are required"},
+		{name: "syntax error", system: "{{if .Balance}}", user: "x", wantErr: "unexpected EOF"},
+		{name: "unknown function", system: "x", user: "{{upper .Balance}}", wantErr: `function "upper" not defined`},
+		{name: "unknown field", system: "x", user: "{{.Equity}}", wantErr: "can't evaluate field Equity"},
+		{name: "unknown field in a range", system: "x", user: "{{range .Snapshot.Positions}}{{.Symbol}}{{end}}",
+			wantErr: "can't evaluate field Symbol"},
+		{name: "missing map key", system: "{{.Snapshot.CoinsMids.ETH}}", user: "x", wantErr: `map has no entry for key "ETH"`},
+		{name: "function fails", system: "x", user: `{{join .Snapshot.Positions ","}}`, wantErr: "wrong type for value"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := NewPrompt(7, tt.system, tt.user)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("NewPrompt error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("NewPrompt: %v", err)
+			}
+			if p.ID != 7 {
+				t.Errorf("ID = %d, want 7", p.ID)
+			}
+		})
+	}
+}
+
+func TestPromptRender(t *testing.T) {
+	p, err := NewPrompt(0, "{{.Snapshot.CoinsMids.BTC}}", "{{.Balance}} {{.ROE}}% {{.EntryInterval}} max {{.MaxDecisions}}")
+	if err != nil {
+		t.Fatalf("NewPrompt: %v", err)
+	}
+	tests := []struct {
+		name         string
+		snap         Snapshot
+		maxDecisions int
+		wantSystem   string
+		wantUser     string
+		wantErr      string
+	}{
+		{name: "renders", snap: Snapshot{Balance: 1234.5, ROE: 0.0525, CoinsMids: map[string]string{"BTC": "97000"}, Timeframes: DefaultTimeframes()},
+			maxDecisions: 3, wantSystem: "97000", wantUser: "1234.50 5.25% 15m max 3"},
+		{name: "at least one decision", snap: Snapshot{CoinsMids: map[string]string{"BTC": "1"}, Timeframes: DefaultTimeframes()},
+			wantSystem: "1", wantUser: "0.00 0.00% 15m max 1"},
+		{name: "key only the trial render had", snap: Snapshot{CoinsMids: map[string]string{"ETH": "3000"}},
+			wantErr: `map has no entry for key "BTC"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			system, user, err := p.Render(tt.snap, tt.maxDecisions)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Render error = %v, want %q", err, tt.wantErr)
+				}
+				if system != "" || user != "" {
+					t.Fatalf("Render = %q, %q, want nothing on failure", system, user)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Render: %v", err)
+			}
+			if system != tt.wantSystem || user != tt.wantUser {
+				t.Fatalf("Render = %q, %q, want %q, %q", system, user, tt.wantSystem, tt.wantUser)
+			}
+		})
+	}
+}
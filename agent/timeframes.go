@@ -0,0 +1,123 @@
+package agent
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"deepseek-trader/hyperliquid"
+)
+
+// Timeframe is one candle interval in the snapshot and how far back its candles go.
+type Timeframe struct {
+	Interval string        `json:"interval"`
+	Lookback time.Duration `json:"lookback"`
+}
+
+type timeframeJSON struct {
+	Interval string `json:"interval"`
+	Lookback string `json:"lookback"`
+}
+
+// MarshalJSON writes the lookback as a duration string ("3h0m0s") for configs and prompts.
+func (tf Timeframe) MarshalJSON() ([]byte, error) {
+	return json.Marshal(timeframeJSON{Interval: tf.Interval, Lookback: tf.Lookback.String()})
+}
+
+func (tf *Timeframe) UnmarshalJSON(data []byte) error {
+	var raw timeframeJSON
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	d, err := time.ParseDuration(raw.Lookback)
+	if err != nil {
+		return fmt.Errorf("timeframe %s: %w", raw.Interval, err)
+	}
+	*tf = Timeframe{Interval: raw.Interval, Lookback: d}
+	return nil
+}
+
+// DefaultTimeframes is the single 15m/3h window the bot used before timeframes were configurable.
+func DefaultTimeframes() []Timeframe {
+	return []Timeframe{{Interval: "15m", Lookback: 3 * time.Hour}}
+}
+
+// ParseTimeframes parses "interval:lookback" pairs such as "5m:3h,1h:24h,4h:120h,1d:720h".
+func ParseTimeframes(s string) ([]Timeframe, error) {
+	var out []Timeframe
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		interval, lookback, ok := strings.Cut(part, ":")
+		if !ok {
+			return nil, fmt.Errorf("timeframe %q: want interval:lookback", part)
+		}
+		d, err := time.ParseDuration(lookback)
+		if err != nil {
+			return nil, fmt.Errorf("timeframe %q: %w", part, err)
+		}
+		out = append(out, Timeframe{Interval: strings.TrimSpace(interval), Lookback: d})
+	}
+	return out, ValidateTimeframes(out)
+}
+
+// ValidateTimeframes requires at least one timeframe, known intervals without duplicates and
+// lookbacks of at least one bar.
+func ValidateTimeframes(tfs []Timeframe) error {
+	if len(tfs) == 0 {
+		return errors.New("at least one candle timeframe is required")
+	}
+	seen := make(map[string]bool, len(tfs))
+	for _, tf := range tfs {
+		d, ok := hyperliquid.IntervalDuration(tf.Interval)
+		if !ok {
+			return fmt.Errorf("unknown candle interval %q", tf.Interval)
+		}
+		if seen[tf.Interval] {
+			return fmt.Errorf("duplicate candle interval %q", tf.Interval)
+		}
+		seen[tf.Interval] = true
+		if tf.Lookback < d {
+			return fmt.Errorf("lookback of %s candles must cover at least one bar", tf.Interval)
+		}
+	}
+	return nil
+}
+
+// Shortest returns the timeframe with the shortest interval, the one entries are timed on.
+func Shortest(tfs []Timeframe) Timeframe {
+	var best Timeframe
+	var bestD time.Duration
+	for _, tf := range tfs {
+		if d, _ := hyperliquid.IntervalDuration(tf.Interval); best.Interval == "" || d < bestD {
+			best, bestD = tf, d
+		}
+	}
+	return best
+}
+
+// HistoryStart is where candle history must start for the timeframe's window and its indicators.
+func (tf Timeframe) HistoryStart(now time.Time) time.Time {
+	d, _ := hyperliquid.IntervalDuration(tf.Interval)
+	return now.Add(-max(tf.Lookback, IndicatorHistory*d))
+}
+
+// String describes the timeframe for the prompt, e.g. "15m candles over the last 3h".
+func (tf Timeframe) String() string {
+	return tf.Interval + " candles over the last " + formatLookback(tf.Lookback)
+}
+
+func formatLookback(d time.Duration) string {
+	switch {
+	case d >= 24*time.Hour && d%(24*time.Hour) == 0:
+		return fmt.Sprintf("%dd", d/(24*time.Hour))
+	case d >= time.Hour && d%time.Hour == 0:
+		return fmt.Sprintf("%dh", d/time.Hour)
+	default:
+		return d.String()
+	}
+}
@@ -0,0 +1,135 @@
+package agent
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseTimeframes(t *testing.T) {
+	tests := []struct {
+		name    string
+		in      string
+		want    []Timeframe
+		wantErr string
+	}{
+		{name: "several", in: "5m:3h,1h:24h,4h:120h,1d:720h", want: []Timeframe{
+			{Interval: "5m", Lookback: 3 * time.Hour},
+			{Interval: "1h", Lookback: 24 * time.Hour},
+			{Interval: "4h", Lookback: 120 * time.Hour},
+			{Interval: "1d", Lookback: 720 * time.Hour},
+		}},
+		{name: "spaces and empty parts", in: " 15m:3h , ,1h:90m ", want: []Timeframe{
+			{Interval: "15m", Lookback: 3 * time.Hour},
+			{Interval: "1h", Lookback: 90 * time.Minute},
+		}},
+		{name: "lookback of exactly one bar", in: "1d:24h", want: []Timeframe{{Interval: "1d", Lookback: 24 * time.Hour}}},
+		{name: "empty", in: "", wantErr: "at least one candle timeframe"},
+		{name: "only separators", in: " , ", wantErr: "at least one candle timeframe"},
+		{name: "missing lookback", in: "15m", wantErr: `timeframe "15m": want interval:lookback`},
+		{name: "bad lookback", in: "15m:3 hours", wantErr: `timeframe "15m:3 hours"`},
+		{name: "lookback in days", in: "1d:30d", wantErr: `unknown unit "d"`},
+		{name: "unknown interval", in: "10m:3h", wantErr: `unknown candle interval "10m"`},
+		{name: "interval case matters", in: "1H:3h", wantErr: `unknown candle interval "1H"`},
+		{name: "duplicate interval", in: "15m:3h,15m:6h", wantErr: `duplicate candle interval "15m"`},
+		{name: "lookback shorter than a bar", in: "4h:3h", wantErr: "lookback of 4h candles must cover at least one bar"},
+		{name: "zero lookback", in: "1m:0s", wantErr: "must cover at least one bar"},
+		{name: "negative lookback", in: "1m:-1h", wantErr: "must cover at least one bar"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseTimeframes(tt.in)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("ParseTimeframes(%q) error = %v, want %q", tt.in, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseTimeframes(%q): %v", tt.in, err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("ParseTimeframes(%q) = %+v, want %+v", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTimeframeJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		in      string
+		want    Timeframe
+		wantErr string
+	}{
+		{name: "duration string", in: `{"interval":"1h","lookback":"24h"}`, want: Timeframe{Interval: "1h", Lookback: 24 * time.Hour}},
+		{name: "compound duration", in: `{"interval":"15m","lookback":"3h0m0s"}`, want: Timeframe{Interval: "15m", Lookback: 3 * time.Hour}},
+		{name: "nanoseconds are not a lookback", in: `{"interval":"1h","lookback":86400000000000}`, wantErr: "cannot unmarshal number"},
+		{name: "missing lookback", in: `{"interval":"1h"}`, wantErr: "timeframe 1h"},
+		{name: "bad lookback", in: `{"interval":"1h","lookback":"a day"}`, wantErr: "timeframe 1h"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got Timeframe
+			err := json.Unmarshal([]byte(tt.in), &got)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Unmarshal(%s) error = %v, want %q", tt.in, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Fatalf("Unmarshal(%s) = %+v, %v, want %+v", tt.in, got, err, tt.want)
+			}
+			b, err := json.Marshal(got)
+			if err != nil {
+				t.Fatalf("Marshal: %v", err)
+			}
+			var back Timeframe
+			if err := json.Unmarshal(b, &back); err != nil || back != got {
+				t.Fatalf("round trip through %s = %+v, %v", b, back, err)
+			}
+		})
+	}
+}
+
+func TestShortest(t *testing.T) {
+	tfs := []Timeframe{{Interval: "4h", Lookback: 120 * time.Hour}, {Interval: "5m", Lookback: 3 * time.Hour}, {Interval: "1h", Lookback: 24 * time.Hour}}
+	if got := Shortest(tfs); got.Interval != "5m" {
+		t.Fatalf("Shortest = %+v, want 5m", got)
+	}
+}
+
+func TestTimeframeString(t *testing.T) {
+	tests := []struct {
+		tf   Timeframe
+		want string
+	}{
+		{Timeframe{Interval: "15m", Lookback: 3 * time.Hour}, "15m candles over the last 3h"},
+		{Timeframe{Interval: "1d", Lookback: 720 * time.Hour}, "1d candles over the last 30d"},
+		{Timeframe{Interval: "5m", Lookback: 90 * time.Minute}, "5m candles over the last 1h30m0s"},
+	}
+	for _, tt := range tests {
+		if got := tt.tf.String(); got != tt.want {
+			t.Errorf("String = %q, want %q", got, tt.want)
+		}
+	}
+}
+
+func TestHistoryStart(t *testing.T) {
+	now := time.Date(2025, 11, 20, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		tf   Timeframe
+		want time.Time
+	}{
+		{Timeframe{Interval: "15m", Lookback: 3 * time.Hour}, now.Add(-IndicatorHistory * 15 * time.Minute)},
+		{Timeframe{Interval: "1m", Lookback: 3 * time.Hour}, now.Add(-3 * time.Hour)},
+	}
+	for _, tt := range tests {
+		if got := tt.tf.HistoryStart(now); !got.Equal(tt.want) {
+			t.Errorf("HistoryStart(%s) = %v, want %v", tt.tf, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,363 @@
+package agent
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"deepseek-trader/hyperliquid"
+)
+
+// MaxRationale is the longest rationale kept, in characters; longer ones are cut.
+const MaxRationale = 500
+
+// ValidationConfig controls how strictly agent output is checked.
+type ValidationConfig struct {
+	// PriceBand is the maximum distance of a limit price from the mid, as a fraction (0.005 = 0.5%).
+	PriceBand float64 `json:"priceBand"`
+	// Repair sends the validation errors back to the model once and asks for a corrected decision.
+	Repair bool `json:"repair"`
+	// MaxDecisions is how many symbols the agent may act on per cycle; below 2 it answers with one decision.
+	MaxDecisions int `json:"maxDecisions"`
+}
+
+// ValidationError lists every rule a decision broke.
+type ValidationError struct {
+	Errors []string
+}
+
+func (e *ValidationError) Error() string {
+	return "invalid decision: " + strings.Join(e.Errors, "; ")
+}
+
+// answer is a model answer: a single decision, or a list of them under "decisions".
+type answer struct {
+	Decision
+	Decisions []Decision `json:"decisions"`
+}
+
+// ParseDecisions decodes a model answer and validates it against the output schema in the system prompt.
+// The answer is either one decision object or {"decisions": [...]} with at most cfg.MaxDecisions
+// decisions, one per symbol; "none" entries are dropped and an empty list reads as a single "none".
+// The decisions are returned in execution order (see Prioritize), with sizes rounded down to the
+// instruments' szDecimals.
+func ParseDecisions(content string, snap Snapshot, cfg ValidationConfig) ([]Decision, error) {
+	var ans answer
+	if err := decodeStrict(content, &ans); err != nil {
+		return []Decision{{Action: "none"}}, &ValidationError{Errors: []string{"response is not a valid decision JSON object: " + err.Error()}}
+	}
+	if ans.Decisions == nil {
+		dec, err := Validate(ans.Decision, snap, cfg)
+		return []Decision{dec}, err
+	}
+
+	var errs []string
+	if ans.Action != "" {
+		errs = append(errs, "answer must be either a single decision or a decisions list, not both")
+	}
+	decs := make([]Decision, 0, len(ans.Decisions))
+	seen := make(map[string]bool, len(ans.Decisions))
+	for i, d := range ans.Decisions {
+		if d.Action == "none" {
+			continue
+		}
+		if d.Scores == nil {
+			d.Scores = ans.Scores // scores may be given once for the whole answer
+		}
+		dec, err := Validate(d, snap, cfg)
+		var verr *ValidationError
+		if errors.As(err, &verr) {
+			for _, e := range verr.Errors {
+				errs = append(errs, fmt.Sprintf("decisions[%d]: %s", i, e))
+			}
+		}
+		if seen[dec.Symbol] {
+			errs = append(errs, fmt.Sprintf("decisions[%d]: more than one decision for %s", i, dec.Symbol))
+		}
+		seen[dec.Symbol] = true
+		decs = append(decs, dec)
+	}
+	if limit := max(cfg.MaxDecisions, 1); len(decs) > limit {
+		errs = append(errs, fmt.Sprintf("at most %d decisions per cycle are allowed, got %d", limit, len(decs)))
+	}
+	if len(decs) == 0 {
+		decs = append(decs, Decision{Action: "none", Scores: ans.Scores, Rationale: ans.Rationale})
+	}
+	Prioritize(decs)
+
+	if len(errs) > 0 {
+		return decs, &ValidationError{Errors: errs}
+	}
+	return decs, nil
+}
+
+// Prioritize orders a cycle's decisions for execution: exits and target adjustments first, since they
+// cut risk and free margin, then reversals and entries by descending confidence. Ties keep the agent's order.
+func Prioritize(decs []Decision) {
+	rank := func(d Decision) int {
+		switch d.Action {
+		case "close", "reduce", "adjust":
+			return 0
+		case "none":
+			return 2
+		default:
+			return 1
+		}
+	}
+	sort.SliceStable(decs, func(i, j int) bool {
+		if ri, rj := rank(decs[i]), rank(decs[j]); ri != rj {
+			return ri < rj
+		}
+		return decs[i].Confidence > decs[j].Confidence
+	})
+}
+
+// Validate checks a decision against section 7 of the system prompt and the current snapshot.
+// Close and reduce decisions come back with their size taken from the open position.
+func Validate(dec Decision, snap Snapshot, cfg ValidationConfig) (Decision, error) {
+	dec.Rationale = truncate(strings.TrimSpace(dec.Rationale), MaxRationale)
+	errs := scoreErrors(dec)
+
+	switch dec.Action {
+	case "none":
+	case "buy", "sell":
+		if dec.Confidence <= 0 {
+			errs = append(errs, "confidence is required for buy, sell and reverse")
+		}
+		errs = append(errs, entryErrors(&dec, snap, cfg, dec.Action == "buy")...)
+	case "close", "reduce", "reverse", "adjust":
+		errs = append(errs, positionErrors(&dec, snap, cfg)...)
+	default:
+		errs = append(errs, fmt.Sprintf("action must be one of \"buy\", \"sell\", \"none\", \"close\", \"reduce\", \"reverse\" or \"adjust\", got %q", dec.Action))
+	}
+
+	if len(errs) > 0 {
+		return dec, &ValidationError{Errors: errs}
+	}
+	return dec, nil
+}
+
+// entryErrors checks a new position on the given side: symbol, size, order and all four targets.
+func entryErrors(dec *Decision, snap Snapshot, cfg ValidationConfig, isBuy bool) []string {
+	var errs []string
+	fail := func(format string, args ...any) {
+		errs = append(errs, fmt.Sprintf(format, args...))
+	}
+
+	coin, ok := coinOf(dec.Symbol)
+	if !ok {
+		fail("symbol must be an uppercase USDT pair of %s, got %q", strings.Join(Coins, ", "), dec.Symbol)
+	}
+	mid := parseMid(snap.CoinsMids[coin])
+	if ok && mid <= 0 {
+		fail("no mid price for %s in the snapshot", coin)
+	}
+
+	if dec.Size <= 0 {
+		fail("size must be positive, got %v", dec.Size)
+	} else if in, found := snap.Meta.Instrument(coin); found {
+		dec.Size = hyperliquid.FloorSize(dec.Size, in.SzDecimals)
+		if dec.Size <= 0 {
+			fail("size rounds to zero at %d size decimals", in.SzDecimals)
+		}
+	}
+
+	entry, orderErrs := orderErrors(dec, mid, cfg)
+	errs = append(errs, orderErrs...)
+	return append(errs, targetErrors(dec.Targets, entry, isBuy)...)
+}
+
+// positionErrors checks an action on the open position in the decision's symbol.
+func positionErrors(dec *Decision, snap Snapshot, cfg ValidationConfig) []string {
+	coin, ok := coinOf(dec.Symbol)
+	if !ok {
+		return []string{fmt.Sprintf("symbol must be an uppercase USDT pair of %s, got %q", strings.Join(Coins, ", "), dec.Symbol)}
+	}
+	pos := snap.Position(coin)
+	if pos == 0 {
+		return []string{fmt.Sprintf("%s requires an open %s position", dec.Action, coin)}
+	}
+	mid := parseMid(snap.CoinsMids[coin])
+
+	switch dec.Action {
+	case "reverse":
+		var errs []string
+		if dec.Confidence <= 0 {
+			errs = append(errs, "confidence is required for buy, sell and reverse")
+		}
+		return append(errs, entryErrors(dec, snap, cfg, pos < 0)...)
+	case "adjust":
+		dec.Size, dec.Fraction, dec.Order, dec.LimitPrice = 0, 0, "", 0
+		return adjustErrors(dec.Targets, mid, pos > 0)
+	}
+
+	// close and reduce: a reduce-only order against the position, without targets.
+	var errs []string
+	dec.Targets = Targets{}
+	dec.Size = math.Abs(pos)
+	if dec.Action == "reduce" {
+		if dec.Fraction <= 0 || dec.Fraction >= 1 {
+			errs = append(errs, fmt.Sprintf("fraction must be between 0 and 1 (exclusive) for reduce, got %v", dec.Fraction))
+		}
+		dec.Size *= dec.Fraction
+		if in, found := snap.Meta.Instrument(coin); found {
+			dec.Size = hyperliquid.FloorSize(dec.Size, in.SzDecimals)
+		}
+		if len(errs) == 0 && dec.Size <= 0 {
+			errs = append(errs, fmt.Sprintf("fraction %v of the %s position rounds to zero", dec.Fraction, coin))
+		}
+	} else {
+		dec.Fraction = 0
+	}
+	if dec.Order == "" {
+		dec.Order = "market"
+	}
+	_, orderErrs := orderErrors(dec, mid, cfg)
+	return append(errs, orderErrs...)
+}
+
+// orderErrors checks the order type and limit price and returns the expected fill price.
+func orderErrors(dec *Decision, mid float64, cfg ValidationConfig) (float64, []string) {
+	switch dec.Order {
+	case "market":
+		dec.LimitPrice = 0
+		return mid, nil
+	case "limit":
+		if dec.LimitPrice <= 0 {
+			return mid, []string{"limitPrice is required for limit orders"}
+		}
+		if mid > 0 && cfg.PriceBand > 0 && math.Abs(dec.LimitPrice-mid)/mid > cfg.PriceBand {
+			return mid, []string{fmt.Sprintf("limitPrice %v is more than %.2f%% away from mid %v", dec.LimitPrice, cfg.PriceBand*100, mid)}
+		}
+		return dec.LimitPrice, nil
+	default:
+		return mid, []string{fmt.Sprintf("order must be \"market\" or \"limit\", got %q", dec.Order)}
+	}
+}
+
+// targetErrors checks that all targets are set and ordered for the side:
+// longs need sl < entry < tp1 < tp2 < tp3, shorts the mirror image.
+func targetErrors(t Targets, entry float64, isBuy bool) []string {
+	if t.TP1 <= 0 || t.TP2 <= 0 || t.TP3 <= 0 || t.SL <= 0 {
+		return []string{"targets tp1, tp2, tp3 and sl are all required and must be positive"}
+	}
+	if entry <= 0 {
+		return nil
+	}
+
+	if isBuy && !(t.SL < entry && entry < t.TP1 && t.TP1 < t.TP2 && t.TP2 < t.TP3) {
+		return []string{fmt.Sprintf("long targets must satisfy sl < entry < tp1 < tp2 < tp3 (sl=%v entry=%v tp1=%v tp2=%v tp3=%v)",
+			t.SL, entry, t.TP1, t.TP2, t.TP3)}
+	}
+	if !isBuy && !(t.SL > entry && entry > t.TP1 && t.TP1 > t.TP2 && t.TP2 > t.TP3) {
+		return []string{fmt.Sprintf("short targets must satisfy sl > entry > tp1 > tp2 > tp3 (sl=%v entry=%v tp1=%v tp2=%v tp3=%v)",
+			t.SL, entry, t.TP1, t.TP2, t.TP3)}
+	}
+	return nil
+}
+
+// adjustErrors checks the targets an adjust replaces: at least one, none negative, and the given ones
+// ordered around the mid like an entry's (sl < mid < tp1 < tp2 < tp3 for a long).
+func adjustErrors(t Targets, mid float64, isLong bool) []string {
+	if t.TP1 < 0 || t.TP2 < 0 || t.TP3 < 0 || t.SL < 0 {
+		return []string{"targets must not be negative"}
+	}
+	if t.TP1 == 0 && t.TP2 == 0 && t.TP3 == 0 && t.SL == 0 {
+		return []string{"adjust requires at least one of tp1, tp2, tp3 and sl"}
+	}
+	if mid <= 0 {
+		return nil
+	}
+
+	prev := 0.0
+	for _, l := range []float64{t.SL, mid, t.TP1, t.TP2, t.TP3} {
+		if l == 0 {
+			continue
+		}
+		if prev != 0 && ((isLong && l <= prev) || (!isLong && l >= prev)) {
+			side, rule := "long", "sl < mid < tp1 < tp2 < tp3"
+			if !isLong {
+				side, rule = "short", "sl > mid > tp1 > tp2 > tp3"
+			}
+			return []string{fmt.Sprintf("%s targets must satisfy %s (sl=%v mid=%v tp1=%v tp2=%v tp3=%v)",
+				side, rule, t.SL, mid, t.TP1, t.TP2, t.TP3)}
+		}
+		prev = l
+	}
+	return nil
+}
+
+// scoreErrors checks that confidence and every symbol score lie within 0-100 and scores are keyed by traded symbols.
+func scoreErrors(dec Decision) []string {
+	var errs []string
+	if dec.Confidence < 0 || dec.Confidence > 100 {
+		errs = append(errs, fmt.Sprintf("confidence must be between 0 and 100, got %v", dec.Confidence))
+	}
+	symbols := make([]string, 0, len(dec.Scores))
+	for symbol := range dec.Scores {
+		symbols = append(symbols, symbol)
+	}
+	sort.Strings(symbols)
+	for _, symbol := range symbols {
+		if _, ok := coinOf(symbol); !ok {
+			errs = append(errs, fmt.Sprintf("scores must be keyed by USDT pairs of traded coins, got %q", symbol))
+		} else if score := dec.Scores[symbol]; score < 0 || score > 100 {
+			errs = append(errs, fmt.Sprintf("score of %s must be between 0 and 100, got %v", symbol, score))
+		}
+	}
+	return errs
+}
+
+func truncate(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	return string(r[:n])
+}
+
+// coinOf maps "BTCUSDT" to "BTC" and reports whether it is one of the traded Coins.
+func coinOf(symbol string) (string, bool) {
+	if symbol != strings.ToUpper(symbol) || !strings.HasSuffix(symbol, "USDT") {
+		return "", false
+	}
+	coin := strings.TrimSuffix(symbol, "USDT")
+	for _, c := range Coins {
+		if c == coin {
+			return coin, true
+		}
+	}
+	return "", false
+}
+
+// decodeStrict decodes exactly one JSON object, tolerating a surrounding markdown code fence.
+func decodeStrict(content string, out *answer) error {
+	content = strings.TrimSpace(content)
+	content = strings.TrimPrefix(content, "```json")
+	content = strings.TrimPrefix(content, "```")
+	content = strings.TrimSuffix(content, "```")
+
+	dec := json.NewDecoder(bytes.NewReader([]byte(content)))
+	if err := dec.Decode(out); err != nil {
+		return err
+	}
+	if dec.More() {
+		return errors.New("unexpected data after the JSON object")
+	}
+	return nil
+}
+
+// parseMid reads a mid price from the snapshot. ParseFloat accepts "NaN" and "Inf", which would slip
+// through every comparison with the mid, so those read as a missing price.
+func parseMid(s string) float64 {
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil || math.IsNaN(f) || math.IsInf(f, 0) {
+		return 0
+	}
+	return f
+}
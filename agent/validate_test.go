@@ -0,0 +1,233 @@
+package agent
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"deepseek-trader/hyperliquid"
+)
+
+func testSnapshot(positions ...hyperliquid.Position) Snapshot {
+	return Snapshot{
+		CoinsMids: map[string]string{"BTC": "50000", "ETH": "3000"},
+		Meta: hyperliquid.ExchangeMeta{Universe: []hyperliquid.Instrument{
+			{Name: "BTC", SzDecimals: 3},
+			{Name: "ETH", SzDecimals: 2},
+		}},
+		Positions: positions,
+	}
+}
+
+// withMid is the test snapshot with BTC quoted at mid.
+func withMid(mid string) Snapshot {
+	snap := testSnapshot()
+	snap.CoinsMids["BTC"] = mid
+	return snap
+}
+
+func longBTC() Decision {
+	return Decision{
+		Action: "buy", Symbol: "BTCUSDT", Size: 0.1, Order: "market", Confidence: 70,
+		Targets: Targets{TP1: 51000, TP2: 52000, TP3: 53000, SL: 49000},
+	}
+}
+
+func TestValidate(t *testing.T) {
+	cfg := ValidationConfig{PriceBand: 0.005}
+	long := testSnapshot(hyperliquid.Position{Coin: "BTC", Size: 0.5})
+	with := func(f func(*Decision)) Decision {
+		d := longBTC()
+		f(&d)
+		return d
+	}
+
+	tests := []struct {
+		name     string
+		dec      Decision
+		snap     Snapshot
+		wantErr  string // substring of the validation error, empty for a valid decision
+		wantSize float64
+	}{
+		{name: "valid long", dec: longBTC(), snap: testSnapshot(), wantSize: 0.1},
+		{name: "size floored to szDecimals", dec: with(func(d *Decision) { d.Size = 0.12345 }), snap: testSnapshot(), wantSize: 0.123},
+		{name: "size rounds to zero", dec: with(func(d *Decision) { d.Size = 0.0004 }), snap: testSnapshot(), wantErr: "rounds to zero"},
+		{name: "zero size", dec: with(func(d *Decision) { d.Size = 0 }), snap: testSnapshot(), wantErr: "size must be positive"},
+		{name: "negative size", dec: with(func(d *Decision) { d.Size = -1 }), snap: testSnapshot(), wantErr: "size must be positive"},
+		{name: "missing confidence", dec: with(func(d *Decision) { d.Confidence = 0 }), snap: testSnapshot(), wantErr: "confidence is required"},
+		{name: "confidence above 100", dec: with(func(d *Decision) { d.Confidence = 101 }), snap: testSnapshot(), wantErr: "confidence must be between 0 and 100"},
+		{name: "score out of range", dec: with(func(d *Decision) { d.Scores = map[string]float64{"BTCUSDT": 120} }), snap: testSnapshot(), wantErr: "score of BTCUSDT"},
+		{name: "score of untraded symbol", dec: with(func(d *Decision) { d.Scores = map[string]float64{"FOOUSDT": 50} }), snap: testSnapshot(), wantErr: "scores must be keyed"},
+		{name: "lowercase symbol", dec: with(func(d *Decision) { d.Symbol = "btcusdt" }), snap: testSnapshot(), wantErr: "symbol must be an uppercase USDT pair"},
+		{name: "no mid", dec: with(func(d *Decision) { d.Symbol = "SOLUSDT" }), snap: testSnapshot(), wantErr: "no mid price for SOL"},
+		{name: "NaN mid", dec: longBTC(), snap: withMid("NaN"), wantErr: "no mid price for BTC"},
+		{name: "infinite mid", dec: longBTC(), snap: withMid("+Inf"), wantErr: "no mid price for BTC"},
+		{name: "unknown action", dec: with(func(d *Decision) { d.Action = "hold" }), snap: testSnapshot(), wantErr: "action must be one of"},
+		{name: "unknown order type", dec: with(func(d *Decision) { d.Order = "stop" }), snap: testSnapshot(), wantErr: "order must be"},
+		{name: "limit without price", dec: with(func(d *Decision) { d.Order = "limit" }), snap: testSnapshot(), wantErr: "limitPrice is required"},
+		{name: "limit at the band edge", dec: with(func(d *Decision) { d.Order, d.LimitPrice = "limit", 50250 }), snap: testSnapshot(), wantSize: 0.1},
+		{name: "limit outside the band", dec: with(func(d *Decision) { d.Order, d.LimitPrice = "limit", 50251 }), snap: testSnapshot(), wantErr: "away from mid"},
+		{name: "long targets out of order", dec: with(func(d *Decision) { d.Targets.TP2 = 51000 }), snap: testSnapshot(), wantErr: "long targets must satisfy"},
+		{name: "stop above entry", dec: with(func(d *Decision) { d.Targets.SL = 50000 }), snap: testSnapshot(), wantErr: "long targets must satisfy"},
+		{name: "missing target", dec: with(func(d *Decision) { d.Targets.TP3 = 0 }), snap: testSnapshot(), wantErr: "are all required"},
+		{
+			name: "valid short",
+			dec: with(func(d *Decision) {
+				d.Action, d.Targets = "sell", Targets{TP1: 49000, TP2: 48000, TP3: 47000, SL: 51000}
+			}),
+			snap: testSnapshot(), wantSize: 0.1,
+		},
+		{name: "close without position", dec: Decision{Action: "close", Symbol: "BTCUSDT"}, snap: testSnapshot(), wantErr: "requires an open BTC position"},
+		{name: "close takes the position size", dec: Decision{Action: "close", Symbol: "BTCUSDT", Size: 9}, snap: long, wantSize: 0.5},
+		{name: "reduce by half", dec: Decision{Action: "reduce", Symbol: "BTCUSDT", Fraction: 0.5}, snap: long, wantSize: 0.25},
+		{name: "reduce by all", dec: Decision{Action: "reduce", Symbol: "BTCUSDT", Fraction: 1}, snap: long, wantErr: "fraction must be between 0 and 1"},
+		{name: "reduce by nothing", dec: Decision{Action: "reduce", Symbol: "BTCUSDT"}, snap: long, wantErr: "fraction must be between 0 and 1"},
+		{name: "reduce rounds to zero", dec: Decision{Action: "reduce", Symbol: "BTCUSDT", Fraction: 0.001}, snap: long, wantErr: "rounds to zero"},
+		{
+			name: "reverse a long into a short",
+			dec: Decision{Action: "reverse", Symbol: "BTCUSDT", Size: 0.2, Order: "market", Confidence: 60,
+				Targets: Targets{TP1: 49000, TP2: 48000, TP3: 47000, SL: 51000}},
+			snap: long, wantSize: 0.2,
+		},
+		{name: "reverse with long targets", dec: with(func(d *Decision) { d.Action = "reverse" }), snap: long, wantErr: "short targets must satisfy"},
+		{name: "adjust the stop", dec: Decision{Action: "adjust", Symbol: "BTCUSDT", Size: 3, Targets: Targets{SL: 49500}}, snap: long, wantSize: 0},
+		{name: "adjust nothing", dec: Decision{Action: "adjust", Symbol: "BTCUSDT"}, snap: long, wantErr: "requires at least one"},
+		{name: "adjust stop above mid", dec: Decision{Action: "adjust", Symbol: "BTCUSDT", Targets: Targets{SL: 50000}}, snap: long, wantErr: "long targets must satisfy"},
+		{name: "adjust negative target", dec: Decision{Action: "adjust", Symbol: "BTCUSDT", Targets: Targets{TP1: -1}}, snap: long, wantErr: "must not be negative"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Validate(tt.dec, tt.snap, cfg)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("Validate: %v", err)
+				}
+				if got.Size != tt.wantSize {
+					t.Fatalf("size = %v, want %v", got.Size, tt.wantSize)
+				}
+				return
+			}
+			var verr *ValidationError
+			if !errors.As(err, &verr) {
+				t.Fatalf("Validate error = %v, want a ValidationError", err)
+			}
+			if !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("Validate error = %q, want it to mention %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateTruncatesRationale(t *testing.T) {
+	d := longBTC()
+	d.Rationale = "  " + strings.Repeat("é", MaxRationale+10) + "  "
+	got, err := Validate(d, testSnapshot(), ValidationConfig{})
+	if err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+	if n := len([]rune(got.Rationale)); n != MaxRationale {
+		t.Fatalf("rationale has %d characters, want %d", n, MaxRationale)
+	}
+}
+
+func TestParseDecisions(t *testing.T) {
+	cfg := ValidationConfig{PriceBand: 0.005, MaxDecisions: 2}
+	snap := testSnapshot(hyperliquid.Position{Coin: "ETH", Size: 1})
+	buy := `{"action":"buy","symbol":"BTCUSDT","size":0.1,"order":"market","confidence":%s,"targets":{"tp1":51000,"tp2":52000,"tp3":53000,"sl":49000}}`
+	entry := func(confidence string) string { return strings.Replace(buy, "%s", confidence, 1) }
+
+	tests := []struct {
+		name        string
+		content     string
+		cfg         ValidationConfig
+		wantActions []string
+		wantErr     string
+	}{
+		{name: "single decision", content: entry("70"), cfg: cfg, wantActions: []string{"buy"}},
+		{name: "code fence", content: "```json\n" + entry("70") + "\n```", cfg: cfg, wantActions: []string{"buy"}},
+		{name: "not json", content: "buy BTC", cfg: cfg, wantActions: []string{"none"}, wantErr: "not a valid decision JSON"},
+		{name: "unknown field is ignored", content: `{"action":"none","foo":1}`, cfg: cfg, wantActions: []string{"none"}},
+		{name: "number out of range", content: entry("1e999"), cfg: cfg, wantActions: []string{"none"}, wantErr: "not a valid decision JSON"},
+		{name: "NaN is not JSON", content: entry("NaN"), cfg: cfg, wantActions: []string{"none"}, wantErr: "not a valid decision JSON"},
+		{name: "trailing data", content: `{"action":"none"} {"action":"none"}`, cfg: cfg, wantActions: []string{"none"}, wantErr: "unexpected data"},
+		{name: "empty list", content: `{"decisions":[]}`, cfg: cfg, wantActions: []string{"none"}},
+		{
+			name:        "exits run before entries",
+			content:     `{"decisions":[` + entry("70") + `,{"action":"close","symbol":"ETHUSDT"}]}`,
+			cfg:         cfg,
+			wantActions: []string{"close", "buy"},
+		},
+		{
+			name:        "list at the limit",
+			content:     `{"decisions":[` + entry("70") + `,{"action":"none"},{"action":"close","symbol":"ETHUSDT"}]}`,
+			cfg:         cfg,
+			wantActions: []string{"close", "buy"},
+		},
+		{
+			name:    "list over the limit",
+			content: `{"decisions":[` + entry("70") + `,{"action":"close","symbol":"ETHUSDT"}]}`,
+			cfg:     ValidationConfig{MaxDecisions: 1},
+			wantErr: "at most 1 decisions",
+		},
+		{
+			name:    "two decisions for one symbol",
+			content: `{"decisions":[` + entry("70") + `,` + entry("80") + `]}`,
+			cfg:     cfg,
+			wantErr: "more than one decision for BTCUSDT",
+		},
+		{
+			name:    "single and list",
+			content: `{"action":"buy","decisions":[` + entry("70") + `]}`,
+			cfg:     cfg,
+			wantErr: "either a single decision or a decisions list",
+		},
+		{
+			name:    "invalid element is reported by index",
+			content: `{"decisions":[{"action":"close","symbol":"SOLUSDT"}]}`,
+			cfg:     cfg,
+			wantErr: "decisions[0]: close requires an open SOL position",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			decs, err := ParseDecisions(tt.content, snap, tt.cfg)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("ParseDecisions error = %v, want it to mention %q", err, tt.wantErr)
+				}
+			} else if err != nil {
+				t.Fatalf("ParseDecisions: %v", err)
+			}
+			if tt.wantActions == nil {
+				return
+			}
+			var got []string
+			for _, d := range decs {
+				got = append(got, d.Action)
+			}
+			if strings.Join(got, ",") != strings.Join(tt.wantActions, ",") {
+				t.Fatalf("actions = %v, want %v", got, tt.wantActions)
+			}
+		})
+	}
+}
+
+func TestPrioritize(t *testing.T) {
+	decs := []Decision{
+		{Action: "none"},
+		{Action: "buy", Symbol: "A", Confidence: 60},
+		{Action: "reverse", Symbol: "B", Confidence: 90},
+		{Action: "adjust", Symbol: "C"},
+		{Action: "sell", Symbol: "D", Confidence: 60},
+		{Action: "close", Symbol: "E"},
+	}
+	Prioritize(decs)
+	var got []string
+	for _, d := range decs {
+		got = append(got, d.Action+d.Symbol)
+	}
+	want := "adjustC,closeE,reverseB,buyA,sellD,none"
+	if strings.Join(got, ",") != want {
+		t.Fatalf("order = %s, want %s", strings.Join(got, ","), want)
+	}
+}
@@ -0,0 +1,108 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"deepseek-trader/api/middleware"
+	"deepseek-trader/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// @Summary      List agent calls
+// @Description  The user's latest archived agent calls, newest first, without snapshots, prompts or answers
+// @Tags         Agent
+// @Accept       json
+// @Produce      json
+// @Param        limit  query  int  false  "Maximum rows (default 50)"
+// @Success      200  {array}   models.AgentCall
+// @Failure      400  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /agent/calls [get]
+func (h *Handler) AgentCalls(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	limit := 50
+	if s := c.Query("limit"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil || n <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be a positive integer"})
+			return
+		}
+		limit = min(n, 500)
+	}
+	ctx, cancel := context.WithTimeout(c, 15*time.Second)
+	defer cancel()
+
+	calls, err := h.calls.List(ctx, userID, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, calls)
+}
+
+// @Summary      Get an agent call
+// @Description  One archived agent call with the snapshot, rendered prompts, raw answers, model, latency and token usage
+// @Tags         Agent
+// @Accept       json
+// @Produce      json
+// @Param        id  path  int  true  "Agent call id"
+// @Success      200  {object}  models.AgentCall
+// @Failure      400  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /agent/calls/{id} [get]
+func (h *Handler) AgentCall(c *gin.Context) {
+	h.agentCall(c, h.calls.Call)
+}
+
+// @Summary      Get the agent call behind a decision
+// @Description  The archived agent call that produced the decision
+// @Tags         Agent
+// @Accept       json
+// @Produce      json
+// @Param        id  path  int  true  "Decision id"
+// @Success      200  {object}  models.AgentCall
+// @Failure      400  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /decisions/{id}/call [get]
+func (h *Handler) DecisionCall(c *gin.Context) {
+	h.agentCall(c, h.calls.ForDecision)
+}
+
+// agentCall serves a single call looked up by the user and the :id path parameter.
+func (h *Handler) agentCall(c *gin.Context, find func(ctx context.Context, userID, id int64) (models.AgentCall, error)) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	ctx, cancel := context.WithTimeout(c, 15*time.Second)
+	defer cancel()
+
+	call, err := find(ctx, userID, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "agent call not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, call)
+}
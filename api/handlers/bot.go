@@ -1,45 +1,158 @@
 package handlers
 
 import (
+	"context"
+	"errors"
+	"io"
 	"net/http"
+	"time"
+
+	"deepseek-trader/api/middleware"
+	"deepseek-trader/bot"
 
 	"github.com/gin-gonic/gin"
 )
 
 // @Summary      Start the bot
-// @Description  Start the bot
+// @Description  Start the caller's bot on their connected wallet
 // @Tags         Bot
 // @Accept       json
 // @Produce      json
-// @Success      200  {object}  map[string]string
-// @Failure      404  {object}  map[string]string
+// @Success      200  {object}  bot.Status
+// @Failure      400  {object}  map[string]string
+// @Failure      401  {object}  map[string]string
+// @Failure      409  {object}  map[string]string
 // @Router       /bot/start [post]
 func (h *Handler) Start(c *gin.Context) {
-	h.botSvc.Start()
-	c.JSON(http.StatusOK, gin.H{"status": "started", "on": true})
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	ctx, cancel := context.WithTimeout(c, 10*time.Second)
+	defer cancel()
+
+	st, err := h.bots.Start(ctx, userID)
+	if errors.Is(err, bot.ErrNoWallet) || errors.Is(err, bot.ErrNoAPIKey) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if errors.Is(err, bot.ErrTripped) {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, st)
 }
 
 // @Summary      Stop the bot
-// @Description  Stop the bot
+// @Description  Stop the caller's bot
 // @Tags         Bot
 // @Accept       json
 // @Produce      json
 // @Success      200  {object}  map[string]string
-// @Failure      404  {object}  map[string]string
+// @Failure      401  {object}  map[string]string
 // @Router       /bot/stop [post]
 func (h *Handler) Stop(c *gin.Context) {
-	h.botSvc.Stop()
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	ctx, cancel := context.WithTimeout(c, 10*time.Second)
+	defer cancel()
+
+	if err := h.bots.Stop(ctx, userID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
 	c.JSON(http.StatusOK, gin.H{"status": "stopped", "on": false})
 }
 
 // @Summary      Get the bot status
-// @Description  Get the bot status
+// @Description  Get the caller's bot status and config
 // @Tags         Bot
 // @Accept       json
 // @Produce      json
-// @Success      200  {object}  map[string]string
-// @Failure      404  {object}  map[string]string
+// @Success      200  {object}  bot.Status
+// @Failure      401  {object}  map[string]string
 // @Router       /bot/status [get]
 func (h *Handler) Status(c *gin.Context) {
-	c.JSON(http.StatusOK, gin.H{"on": h.botSvc.IsOn()})
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	ctx, cancel := context.WithTimeout(c, 10*time.Second)
+	defer cancel()
+
+	st, err := h.bots.Status(ctx, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, st)
+}
+
+// @Summary      Kill the bot
+// @Description  Stop the caller's bot, cancel all their open orders and optionally close every position at market. The bot stays stopped until it is re-armed
+// @Tags         Bot
+// @Accept       json
+// @Produce      json
+// @Param        request  body      bot.KillRequest  false  "Kill options"
+// @Success      200      {object}  bot.HaltReport
+// @Failure      400      {object}  map[string]string
+// @Failure      401      {object}  map[string]string
+// @Router       /bot/kill [post]
+func (h *Handler) Kill(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	var req bot.KillRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	ctx, cancel := context.WithTimeout(c, 30*time.Second)
+	defer cancel()
+
+	rep, err := h.bots.Kill(ctx, userID, req)
+	if errors.Is(err, bot.ErrNoWallet) || errors.Is(err, bot.ErrNoAPIKey) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error(), "report": rep})
+		return
+	}
+	c.JSON(http.StatusOK, rep)
+}
+
+// @Summary      Re-arm the bot
+// @Description  Clear the trip of a bot stopped by the circuit breaker or kill switch so it can be started again
+// @Tags         Bot
+// @Accept       json
+// @Produce      json
+// @Success      200  {object}  map[string]string
+// @Failure      401  {object}  map[string]string
+// @Router       /bot/rearm [post]
+func (h *Handler) Rearm(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	ctx, cancel := context.WithTimeout(c, 10*time.Second)
+	defer cancel()
+
+	if err := h.bots.Rearm(ctx, userID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "armed"})
 }
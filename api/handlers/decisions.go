@@ -0,0 +1,87 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"deepseek-trader/api/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+// @Summary      List decisions
+// @Description  The bot's latest decisions, newest first, with status, confidence, per-symbol scores and rationale
+// @Tags         Decisions
+// @Accept       json
+// @Produce      json
+// @Param        limit  query  int  false  "Maximum rows (default 50, max 500)"
+// @Success      200  {array}   models.Decision
+// @Failure      400  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /decisions [get]
+func (h *Handler) Decisions(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	limit := 50
+	if s := c.Query("limit"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil || n <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be a positive integer"})
+			return
+		}
+		limit = min(n, 500)
+	}
+	ctx, cancel := context.WithTimeout(c, 15*time.Second)
+	defer cancel()
+
+	items, err := h.trades.LatestDecisions(ctx, userID, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, items)
+}
+
+// @Summary      Get a decision
+// @Description  One of the bot's decisions with its status, confidence, per-symbol scores and rationale
+// @Tags         Decisions
+// @Accept       json
+// @Produce      json
+// @Param        id  path  int  true  "Decision id"
+// @Success      200  {object}  models.Decision
+// @Failure      400  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /decisions/{id} [get]
+func (h *Handler) Decision(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	ctx, cancel := context.WithTimeout(c, 15*time.Second)
+	defer cancel()
+
+	d, err := h.trades.Decision(ctx, id)
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && (d.UserID == nil || *d.UserID != userID)) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "decision not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, d)
+}
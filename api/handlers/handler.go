@@ -3,29 +3,39 @@ package handlers
 import (
 	"deepseek-trader/bot"
 	"deepseek-trader/hyperliquid"
+	"deepseek-trader/replay"
 	"deepseek-trader/services"
 )
 
 type Handler struct {
 	wallet  *services.WalletService
-	botSvc  *bot.Service
+	bots    *bot.Manager
 	stats   *services.StatsService
 	trades  *services.TradesService
+	market  *services.MarketService
+	calls   *services.AgentCallsService
+	prompts *services.PromptsService
+	replay  *replay.Replayer
 	authSvc *services.AuthService
 	hl      *hyperliquid.Client
 }
 
 func New(
 	wallet *services.WalletService,
-	botSvc *bot.Service, stats *services.StatsService,
-	trades *services.TradesService, authSvc *services.AuthService,
+	bots *bot.Manager, stats *services.StatsService,
+	trades *services.TradesService, market *services.MarketService,
+	calls *services.AgentCallsService, prompts *services.PromptsService, replayer *replay.Replayer, authSvc *services.AuthService,
 	hl *hyperliquid.Client,
 ) *Handler {
 	return &Handler{
 		wallet:  wallet,
-		botSvc:  botSvc,
+		bots:    bots,
 		stats:   stats,
 		trades:  trades,
+		market:  market,
+		calls:   calls,
+		prompts: prompts,
+		replay:  replayer,
 		authSvc: authSvc,
 		hl:      hl,
 	}
@@ -0,0 +1,107 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// @Summary      Get stored candles
+// @Description  Candles recorded in the market-data store for a coin and interval, oldest first
+// @Tags         Market
+// @Accept       json
+// @Produce      json
+// @Param        coin      query  string  true   "Coin, e.g. BTC"
+// @Param        interval  query  string  true   "Candle interval, e.g. 15m"
+// @Param        from      query  string  false  "Start time, RFC3339 (default: 24h ago)"
+// @Param        to        query  string  false  "End time, RFC3339 (default: now)"
+// @Param        limit     query  int     false  "Maximum rows (default and cap 5000)"
+// @Success      200  {array}   models.Candle
+// @Failure      400  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /market/candles [get]
+func (h *Handler) MarketCandles(c *gin.Context) {
+	coin, interval := c.Query("coin"), c.Query("interval")
+	if coin == "" || interval == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "coin and interval are required"})
+		return
+	}
+	from, to, limit, err := marketRange(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	ctx, cancel := context.WithTimeout(c, 15*time.Second)
+	defer cancel()
+
+	candles, err := h.market.Candles(ctx, coin, interval, from, to, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, candles)
+}
+
+// @Summary      Get stored order book snapshots
+// @Description  L2 book snapshots recorded in the market-data store for a coin, oldest first
+// @Tags         Market
+// @Accept       json
+// @Produce      json
+// @Param        coin   query  string  true   "Coin, e.g. BTC"
+// @Param        from   query  string  false  "Start time, RFC3339 (default: 24h ago)"
+// @Param        to     query  string  false  "End time, RFC3339 (default: now)"
+// @Param        limit  query  int     false  "Maximum rows (default and cap 5000)"
+// @Success      200  {array}   models.BookSnapshot
+// @Failure      400  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /market/books [get]
+func (h *Handler) MarketBooks(c *gin.Context) {
+	coin := c.Query("coin")
+	if coin == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "coin is required"})
+		return
+	}
+	from, to, limit, err := marketRange(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	ctx, cancel := context.WithTimeout(c, 15*time.Second)
+	defer cancel()
+
+	books, err := h.market.Books(ctx, coin, from, to, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, books)
+}
+
+// marketRange parses the from/to/limit query parameters into unix milliseconds; the range defaults to the last 24h.
+func marketRange(c *gin.Context) (from, to int64, limit int, err error) {
+	end := time.Now()
+	if s := c.Query("to"); s != "" {
+		if end, err = time.Parse(time.RFC3339, s); err != nil {
+			return 0, 0, 0, errors.New("to must be RFC3339")
+		}
+	}
+	start := end.Add(-24 * time.Hour)
+	if s := c.Query("from"); s != "" {
+		if start, err = time.Parse(time.RFC3339, s); err != nil {
+			return 0, 0, 0, errors.New("from must be RFC3339")
+		}
+	}
+	if !start.Before(end) {
+		return 0, 0, 0, errors.New("from must be before to")
+	}
+	if s := c.Query("limit"); s != "" {
+		if limit, err = strconv.Atoi(s); err != nil || limit < 0 {
+			return 0, 0, 0, errors.New("limit must be a non-negative integer")
+		}
+	}
+	return start.UnixMilli(), end.UnixMilli(), limit, nil
+}
@@ -0,0 +1,297 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"deepseek-trader/api/middleware"
+	"deepseek-trader/bot"
+	"deepseek-trader/hyperliquid"
+
+	"github.com/gin-gonic/gin"
+)
+
+// @Summary      Get open positions
+// @Description  Margin summary and open positions of the user's wallet (or paper account), from clearinghouseState
+// @Tags         Positions
+// @Accept       json
+// @Produce      json
+// @Success      200  {object}  hyperliquid.AccountState
+// @Failure      400  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /positions [get]
+func (h *Handler) Positions(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	ctx, cancel := context.WithTimeout(c, 15*time.Second)
+	defer cancel()
+
+	st, err := h.bots.AccountState(ctx, userID)
+	if errors.Is(err, bot.ErrNoWallet) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, st)
+}
+
+// @Summary      Get margin health
+// @Description  Distance of each open position to its liquidation price, graded ok/warning/danger with the bot's thresholds, and the account's maintenance margin ratio
+// @Tags         Positions
+// @Accept       json
+// @Produce      json
+// @Success      200  {object}  hyperliquid.MarginHealth
+// @Failure      400  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /positions/health [get]
+func (h *Handler) MarginHealth(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	ctx, cancel := context.WithTimeout(c, 15*time.Second)
+	defer cancel()
+
+	health, err := h.bots.Health(ctx, userID)
+	if errors.Is(err, bot.ErrNoWallet) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, health)
+}
+
+// @Summary      Get open orders
+// @Description  Resting orders of the user's wallet (or paper account), including TP/SL triggers
+// @Tags         Orders
+// @Accept       json
+// @Produce      json
+// @Success      200  {array}   hyperliquid.OpenOrder
+// @Failure      400  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /orders/open [get]
+func (h *Handler) OpenOrders(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	ctx, cancel := context.WithTimeout(c, 15*time.Second)
+	defer cancel()
+
+	orders, err := h.bots.OpenOrders(ctx, userID)
+	if errors.Is(err, bot.ErrNoWallet) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, orders)
+}
+
+// @Summary      Cancel an order
+// @Description  Cancels one resting order by oid through the running bot
+// @Tags         Orders
+// @Accept       json
+// @Produce      json
+// @Param        request  body  bot.CancelRequest  true  "Coin and order id"
+// @Success      200  {object}  map[string]string
+// @Failure      400  {object}  map[string]string
+// @Failure      409  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /orders/cancel [post]
+func (h *Handler) CancelOrder(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	var req bot.CancelRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.Coin == "" || req.Oid <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "coin and oid are required"})
+		return
+	}
+	ctx, cancel := context.WithTimeout(c, 15*time.Second)
+	defer cancel()
+
+	err := h.bots.CancelOrder(ctx, userID, req.Coin, req.Oid)
+	switch {
+	case errors.Is(err, bot.ErrBotStopped):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case err != nil:
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusOK, gin.H{"status": "canceled"})
+	}
+}
+
+// @Summary      Cancel all orders
+// @Description  Cancels every open order on the coin, or on every coin when coin is empty, through the running bot
+// @Tags         Orders
+// @Accept       json
+// @Produce      json
+// @Param        coin  query  string  false  "Coin, e.g. BTC"
+// @Success      200  {object}  map[string]int
+// @Failure      409  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /orders/cancel-all [post]
+func (h *Handler) CancelAllOrders(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	ctx, cancel := context.WithTimeout(c, 15*time.Second)
+	defer cancel()
+
+	n, err := h.bots.CancelAll(ctx, userID, c.Query("coin"))
+	switch {
+	case errors.Is(err, bot.ErrBotStopped):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error(), "canceled": n})
+	default:
+		c.JSON(http.StatusOK, gin.H{"canceled": n})
+	}
+}
+
+// @Summary      Set leverage
+// @Description  Checks the leverage and margin mode against the margin tier of the position in the coin and sets them through the running bot
+// @Tags         Positions
+// @Accept       json
+// @Produce      json
+// @Param        request  body  bot.LeverageRequest  true  "Coin, leverage and margin mode"
+// @Success      200  {object}  hyperliquid.MarginCheck
+// @Failure      400  {object}  map[string]string
+// @Failure      409  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /positions/leverage [post]
+func (h *Handler) SetLeverage(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	var req bot.LeverageRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.Coin == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "coin is required"})
+		return
+	}
+	ctx, cancel := context.WithTimeout(c, 15*time.Second)
+	defer cancel()
+
+	chk, err := h.bots.SetLeverage(ctx, userID, req.Coin, req.Leverage, req.Isolated)
+	switch {
+	case errors.Is(err, bot.ErrBotStopped):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, bot.ErrMarginCheck), errors.Is(err, hyperliquid.ErrLeverageRejected):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusOK, chk)
+	}
+}
+
+// @Summary      Adjust isolated margin
+// @Description  Adds USD to the isolated position in the coin, or removes it when the amount is negative, through the running bot
+// @Tags         Positions
+// @Accept       json
+// @Produce      json
+// @Param        request  body  bot.MarginRequest  true  "Coin and signed amount"
+// @Success      200  {object}  map[string]string
+// @Failure      400  {object}  map[string]string
+// @Failure      409  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /positions/margin [post]
+func (h *Handler) AdjustMargin(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	var req bot.MarginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.Coin == "" || req.Amount == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "coin and a non-zero amount are required"})
+		return
+	}
+	ctx, cancel := context.WithTimeout(c, 15*time.Second)
+	defer cancel()
+
+	err := h.bots.AdjustMargin(ctx, userID, req.Coin, req.Amount)
+	switch {
+	case errors.Is(err, bot.ErrBotStopped):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, hyperliquid.ErrLeverageRejected):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusOK, gin.H{"status": "ok"})
+	}
+}
+
+// @Summary      Check a position against its margin tier
+// @Description  Validates a position of notional USD in the coin at the given leverage and margin mode against the coin's margin tiers and returns its initial and maintenance margin
+// @Tags         Positions
+// @Accept       json
+// @Produce      json
+// @Param        coin      query  string  true   "Coin, e.g. BTC"
+// @Param        notional  query  number  true   "Position value in USD"
+// @Param        leverage  query  int     true   "Leverage"
+// @Param        isolated  query  bool    false  "Isolated margin"
+// @Success      200  {object}  hyperliquid.MarginCheck
+// @Failure      400  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /positions/margin-check [get]
+func (h *Handler) MarginCheck(c *gin.Context) {
+	coin := c.Query("coin")
+	notional, nerr := strconv.ParseFloat(c.Query("notional"), 64)
+	leverage, lerr := strconv.Atoi(c.Query("leverage"))
+	isolated := c.Query("isolated") == "true"
+	if coin == "" || nerr != nil || lerr != nil || notional < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "coin, notional and leverage are required"})
+		return
+	}
+	ctx, cancel := context.WithTimeout(c, 15*time.Second)
+	defer cancel()
+
+	meta, err := h.hl.Meta(ctx)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	chk, err := meta.CheckPosition(coin, notional, leverage, isolated)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, chk)
+}
@@ -0,0 +1,149 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"deepseek-trader/api/middleware"
+	"deepseek-trader/models"
+	"deepseek-trader/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// @Summary      List prompt versions
+// @Description  Stored prompt template versions of the user's bot, newest first
+// @Tags         Prompts
+// @Accept       json
+// @Produce      json
+// @Success      200  {array}   models.PromptVersion
+// @Failure      500  {object}  map[string]string
+// @Router       /bot/prompts [get]
+func (h *Handler) Prompts(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	ctx, cancel := context.WithTimeout(c, 15*time.Second)
+	defer cancel()
+
+	items, err := h.prompts.List(ctx, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, items)
+}
+
+// @Summary      Get the active prompt version
+// @Description  The prompt version the bot uses; the built-in templates are returned as version 0 when none is active
+// @Tags         Prompts
+// @Accept       json
+// @Produce      json
+// @Success      200  {object}  models.PromptVersion
+// @Failure      500  {object}  map[string]string
+// @Router       /bot/prompts/active [get]
+func (h *Handler) ActivePrompt(c *gin.Context) {
+	h.promptAction(c, func(ctx context.Context, userID int64) (models.PromptVersion, error) {
+		return h.prompts.Active(ctx, userID)
+	})
+}
+
+// @Summary      Create a prompt version
+// @Description  Stores system and user text/template sources as the bot's next prompt version; templates are checked by rendering a sample snapshot
+// @Tags         Prompts
+// @Accept       json
+// @Produce      json
+// @Param        request  body  services.CreatePromptRequest  true  "Prompt templates"
+// @Success      201  {object}  models.PromptVersion
+// @Failure      400  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /bot/prompts [post]
+func (h *Handler) CreatePrompt(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	var req services.CreatePromptRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	ctx, cancel := context.WithTimeout(c, 15*time.Second)
+	defer cancel()
+
+	p, err := h.prompts.Create(ctx, userID, req)
+	if errors.Is(err, services.ErrInvalidPrompt) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, p)
+}
+
+// @Summary      Activate a prompt version
+// @Description  The bot renders its prompts from this version from its next cycle on; id 0 selects the built-in templates
+// @Tags         Prompts
+// @Accept       json
+// @Produce      json
+// @Param        id  path  int  true  "Prompt version id"
+// @Success      200  {object}  models.PromptVersion
+// @Failure      400  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /bot/prompts/{id}/activate [post]
+func (h *Handler) ActivatePrompt(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || id < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	h.promptAction(c, func(ctx context.Context, userID int64) (models.PromptVersion, error) {
+		return h.prompts.Activate(ctx, userID, id)
+	})
+}
+
+// @Summary      Roll back the prompt
+// @Description  Activates the version before the active one, or the built-in templates after the first version
+// @Tags         Prompts
+// @Accept       json
+// @Produce      json
+// @Success      200  {object}  models.PromptVersion
+// @Failure      400  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /bot/prompts/rollback [post]
+func (h *Handler) RollbackPrompt(c *gin.Context) {
+	h.promptAction(c, h.prompts.Rollback)
+}
+
+// promptAction runs fn for the user and responds with the prompt version it returns.
+func (h *Handler) promptAction(c *gin.Context, fn func(ctx context.Context, userID int64) (models.PromptVersion, error)) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	ctx, cancel := context.WithTimeout(c, 15*time.Second)
+	defer cancel()
+
+	p, err := fn(ctx, userID)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		c.JSON(http.StatusNotFound, gin.H{"error": "prompt version not found"})
+	case errors.Is(err, services.ErrNoPromptToRollBack):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusOK, p)
+	}
+}
@@ -0,0 +1,58 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"time"
+
+	"deepseek-trader/api/middleware"
+	"deepseek-trader/replay"
+
+	"github.com/gin-gonic/gin"
+)
+
+// @Summary      Replay archived decision cycles
+// @Description  Re-runs one archived agent call (callId) or the calls in a time range (from/to, RFC3339) with the bot's agent config, the given overrides and prompt version (promptId), and diffs the new decisions against the original ones
+// @Tags         Agent
+// @Accept       json
+// @Produce      json
+// @Param        request  body  replay.Request  true  "Cycles to replay and agent overrides"
+// @Success      200  {object}  replay.Result
+// @Failure      400  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /agent/replay [post]
+func (h *Handler) Replay(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var req replay.Request
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.CallID == 0 && req.From.IsZero() {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "either callId or from is required"})
+		return
+	}
+
+	// Every cycle is a model call, so a replay may take minutes.
+	ctx, cancel := context.WithTimeout(c, 30*time.Minute)
+	defer cancel()
+
+	res, err := h.replay.Run(ctx, userID, req)
+	if errors.Is(err, sql.ErrNoRows) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "agent call or prompt version not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, res)
+}
@@ -37,9 +37,9 @@ func (h *Handler) Stats(c *gin.Context) {
 		return
 	}
 
-	h.hl.SetWalletAddress(w.Address)
+	hl := h.hl.ForWallet(w.Address)
 
-	if live, err := h.hl.GetLiveStats(ctx); err == nil {
+	if live, err := hl.GetLiveStats(ctx); err == nil {
 		st.Balance = live.Balance
 		st.PnL = live.PnL
 		st.ROE = live.ROE
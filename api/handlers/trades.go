@@ -34,9 +34,9 @@ func (h *Handler) TradesHistory(c *gin.Context) {
 		return
 	}
 
-	h.hl.SetWalletAddress(w.Address)
+	hl := h.hl.ForWallet(w.Address)
 
-	raw, err := h.hl.HistoricalOrders(ctx)
+	raw, err := hl.HistoricalOrders(ctx)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -96,15 +96,15 @@ func (h *Handler) TradesSummary(c *gin.Context) {
 		return
 	}
 
-	h.hl.SetWalletAddress(w.Address)
+	hl := h.hl.ForWallet(w.Address)
 
-	raw, err := h.hl.HistoricalOrders(ctx)
+	raw, err := hl.HistoricalOrders(ctx)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
-	fees, _ := h.hl.UserFees(ctx)
+	fees, _ := hl.UserFees(ctx)
 	feeParams := services.FeeParams{Maker: 0.00015, Taker: 0.00045, Discount: 0}
 	if fees != nil {
 		feeParams.Maker = fees.UserAddRate
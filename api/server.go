@@ -70,11 +70,44 @@ func NewRouter(handlers *handlers.Handler, cfg *config.Settings) http.Handler {
 	secured.POST("/bot/start", handlers.Start)
 	secured.POST("/bot/stop", handlers.Stop)
 	secured.GET("/bot/status", handlers.Status)
+	secured.POST("/bot/kill", handlers.Kill)
+	secured.POST("/bot/rearm", handlers.Rearm)
+
+	// Prompt versions
+	secured.GET("/bot/prompts", handlers.Prompts)
+	secured.POST("/bot/prompts", handlers.CreatePrompt)
+	secured.GET("/bot/prompts/active", handlers.ActivePrompt)
+	secured.POST("/bot/prompts/rollback", handlers.RollbackPrompt)
+	secured.POST("/bot/prompts/:id/activate", handlers.ActivatePrompt)
 
 	// User stats
 	secured.GET("/stats", handlers.Stats)
 	secured.GET("/trades/history", handlers.TradesHistory)
 	secured.GET("/trades/summary", handlers.TradesSummary)
 
+	// Account state
+	secured.GET("/positions", handlers.Positions)
+	secured.GET("/orders/open", handlers.OpenOrders)
+	secured.POST("/orders/cancel", handlers.CancelOrder)
+	secured.POST("/orders/cancel-all", handlers.CancelAllOrders)
+	secured.GET("/positions/health", handlers.MarginHealth)
+	secured.POST("/positions/leverage", handlers.SetLeverage)
+	secured.POST("/positions/margin", handlers.AdjustMargin)
+	secured.GET("/positions/margin-check", handlers.MarginCheck)
+
+	// Decisions
+	secured.GET("/decisions", handlers.Decisions)
+	secured.GET("/decisions/:id", handlers.Decision)
+
+	// Agent call archive
+	secured.GET("/agent/calls", handlers.AgentCalls)
+	secured.GET("/agent/calls/:id", handlers.AgentCall)
+	secured.GET("/decisions/:id/call", handlers.DecisionCall)
+	secured.POST("/agent/replay", handlers.Replay)
+
+	// Market data store
+	secured.GET("/market/candles", handlers.MarketCandles)
+	secured.GET("/market/books", handlers.MarketBooks)
+
 	return r
 }
@@ -0,0 +1,285 @@
+package backtest
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"deepseek-trader/agent"
+	"deepseek-trader/hyperliquid"
+	"deepseek-trader/models"
+
+	"go.uber.org/zap"
+)
+
+var t0 = time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// bars builds hourly BTC candles from open, high, low, close rows.
+func bars(rows ...[4]float64) []hyperliquid.Candle {
+	out := make([]hyperliquid.Candle, 0, len(rows))
+	for i, r := range rows {
+		start := t0.Add(time.Duration(i) * time.Hour).UnixMilli()
+		out = append(out, hyperliquid.Candle{
+			StartTime: start,
+			EndTime:   start + time.Hour.Milliseconds() - 1,
+			Symbol:    "BTC",
+			Interval:  "1h",
+			Open:      formatF(r[0]),
+			High:      formatF(r[1]),
+			Low:       formatF(r[2]),
+			Close:     formatF(r[3]),
+		})
+	}
+	return out
+}
+
+// scriptedAgent answers each call with the next script entry and "none" once it runs out.
+type scriptedAgent struct {
+	script [][]agent.Decision
+	snaps  []agent.Snapshot
+}
+
+func (a *scriptedAgent) Decide(_ context.Context, snap agent.Snapshot) ([]agent.Decision, agent.Call, error) {
+	a.snaps = append(a.snaps, snap)
+	if i := len(a.snaps) - 1; i < len(a.script) {
+		return a.script[i], agent.Call{}, nil
+	}
+	return []agent.Decision{{Action: "none", Symbol: "BTC"}}, agent.Call{}, nil
+}
+
+func TestRun(t *testing.T) {
+	ag := &scriptedAgent{script: [][]agent.Decision{{{
+		Action: "buy", Symbol: "BTC", Size: 1, Order: "market",
+		Targets: agent.Targets{TP1: 110, TP2: 120, SL: 95},
+	}}}}
+	e := NewEngine(Config{Timeframes: []agent.Timeframe{{Interval: "1h", Lookback: time.Hour}}}, ag, zap.NewNop())
+
+	// The first bar is warmup; the agent buys 1 BTC at the second close (100), TP1 takes 0.4 at 110 on
+	// the third bar and the stop closes the other 0.6 at 95 on the fourth, cancelling TP2.
+	res, err := e.Run(context.Background(), Series{"BTC": bars(
+		[4]float64{100, 101, 99, 100},
+		[4]float64{100, 101, 99, 100},
+		[4]float64{100, 112, 104, 110},
+		[4]float64{108, 109, 90, 92},
+		[4]float64{92, 93, 91, 92},
+	)})
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+
+	wantTrades := []struct {
+		kind        string
+		side        string
+		size, price float64
+		pnl         float64
+	}{
+		{models.OrderKindEntry, "buy", 1, 100, 0},
+		{models.OrderKindTP1, "sell", 0.4, 110, 4},
+		{models.OrderKindSL, "sell", 0.6, 95, -3},
+	}
+	if len(res.Trades) != len(wantTrades) {
+		t.Fatalf("trades = %+v, want %d", res.Trades, len(wantTrades))
+	}
+	for i, w := range wantTrades {
+		got := res.Trades[i]
+		if got.Kind != w.kind || got.Side != w.side || !near(got.Size, w.size) || !near(got.Price, w.price) || !near(got.ClosedPnL, w.pnl) {
+			t.Errorf("trade %d = %+v, want %s %s %v @ %v pnl %v", i, got, w.kind, w.side, w.size, w.price, w.pnl)
+		}
+	}
+
+	wantEquity := []float64{10000, 10000, 10010, 10001, 10001}
+	for i, w := range wantEquity {
+		if !near(res.Equity[i].Equity, w) {
+			t.Errorf("equity[%d] = %v, want %v", i, res.Equity[i].Equity, w)
+		}
+	}
+
+	m := res.Metrics
+	if !near(m.FinalEquity, 10001) || !near(m.TotalReturn, 1.0/10000) || !near(m.MaxDrawdown, 9.0/10010) {
+		t.Errorf("equity metrics = %+v, want final 10001, return 0.0001, drawdown 9/10010", m)
+	}
+	if m.Fills != 3 || m.ClosedTrades != 2 || !near(m.RealizedPnL, 1) || !near(m.WinRate, 0.5) || !near(m.ProfitFactor, 4.0/3) {
+		t.Errorf("trade metrics = %+v, want 3 fills, 2 closed, pnl 1, win rate 0.5, profit factor 4/3", m)
+	}
+
+	last := ag.snaps[len(ag.snaps)-1]
+	wantDirs := []string{"Close Long", "Close Long", "Open Long"}
+	if len(last.Trades) != len(wantDirs) {
+		t.Fatalf("snapshot trades = %+v, want %d", last.Trades, len(wantDirs))
+	}
+	for i, w := range wantDirs {
+		if f, _ := last.Trades[i].(hyperliquid.UserFill); f.Dir != w {
+			t.Errorf("snapshot trade %d = %+v, want dir %q", i, last.Trades[i], w)
+		}
+	}
+}
+
+func TestMatchBar(t *testing.T) {
+	tests := []struct {
+		name    string
+		pos     float64 // signed BTC position entered at 100
+		orders  []*order
+		bar     [4]float64
+		want    []Trade // kind, side, size and price are compared
+		wantPos float64
+	}{
+		{name: "limit buy fills at its limit",
+			orders: []*order{{coin: "BTC", isBuy: true, kind: models.OrderKindEntry, size: 1, limit: 95}},
+			bar:    [4]float64{98, 99, 94, 96},
+			want:   []Trade{{Kind: models.OrderKindEntry, Side: "buy", Size: 1, Price: 95}}, wantPos: 1},
+		{name: "limit buy opened through fills at the open",
+			orders: []*order{{coin: "BTC", isBuy: true, kind: models.OrderKindEntry, size: 1, limit: 95}},
+			bar:    [4]float64{93, 96, 92, 94},
+			want:   []Trade{{Kind: models.OrderKindEntry, Side: "buy", Size: 1, Price: 93}}, wantPos: 1},
+		{name: "limit not reached", orders: []*order{{coin: "BTC", isBuy: true, kind: models.OrderKindEntry, size: 1, limit: 95}},
+			bar: [4]float64{98, 99, 96, 97}},
+		{name: "take profit fills at its trigger", pos: 1,
+			orders: []*order{{coin: "BTC", kind: models.OrderKindTP1, size: 0.4, trigger: 110}},
+			bar:    [4]float64{105, 111, 104, 108},
+			want:   []Trade{{Kind: models.OrderKindTP1, Side: "sell", Size: 0.4, Price: 110}}, wantPos: 0.6},
+		{name: "take profit opened past fills at the open", pos: 1,
+			orders: []*order{{coin: "BTC", kind: models.OrderKindTP1, size: 0.4, trigger: 110}},
+			bar:    [4]float64{112, 113, 109, 111},
+			want:   []Trade{{Kind: models.OrderKindTP1, Side: "sell", Size: 0.4, Price: 112}}, wantPos: 0.6},
+		{name: "stop gapped through fills at the open", pos: 1,
+			orders: []*order{{coin: "BTC", kind: models.OrderKindSL, size: 1, trigger: 95}},
+			bar:    [4]float64{90, 92, 88, 91},
+			want:   []Trade{{Kind: models.OrderKindSL, Side: "sell", Size: 1, Price: 90}}},
+		{name: "stop of a short", pos: -1,
+			orders: []*order{{coin: "BTC", isBuy: true, kind: models.OrderKindSL, size: 1, trigger: 105}},
+			bar:    [4]float64{101, 106, 100, 104},
+			want:   []Trade{{Kind: models.OrderKindSL, Side: "buy", Size: 1, Price: 105}}},
+		{name: "stop before take profit in the same bar", pos: 1,
+			orders: []*order{
+				{coin: "BTC", kind: models.OrderKindTP1, size: 1, trigger: 110},
+				{coin: "BTC", kind: models.OrderKindSL, size: 1, trigger: 95},
+			},
+			bar:  [4]float64{100, 111, 94, 105},
+			want: []Trade{{Kind: models.OrderKindSL, Side: "sell", Size: 1, Price: 95}}},
+		{name: "exit clamped to the position", pos: 0.5,
+			orders: []*order{{coin: "BTC", kind: models.OrderKindSL, size: 1, trigger: 95}},
+			bar:    [4]float64{100, 101, 94, 96},
+			want:   []Trade{{Kind: models.OrderKindSL, Side: "sell", Size: 0.5, Price: 95}}},
+		{name: "exit without a position is skipped",
+			orders: []*order{{coin: "BTC", kind: models.OrderKindSL, size: 1, trigger: 95}},
+			bar:    [4]float64{100, 101, 94, 96}},
+		{name: "other coins are left alone", pos: 1,
+			orders: []*order{{coin: "ETH", kind: models.OrderKindSL, size: 1, trigger: 95}},
+			bar:    [4]float64{100, 101, 94, 96}, wantPos: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := NewEngine(Config{}, nil, zap.NewNop())
+			e.positions = map[string]*position{"BTC": {size: tt.pos, entry: 100}}
+			e.orders = tt.orders
+
+			e.matchBar("BTC", bars(tt.bar)[0])
+
+			if len(e.trades) != len(tt.want) {
+				t.Fatalf("trades = %+v, want %+v", e.trades, tt.want)
+			}
+			for i, w := range tt.want {
+				got := e.trades[i]
+				if got.Kind != w.Kind || got.Side != w.Side || !near(got.Size, w.Size) || !near(got.Price, w.Price) {
+					t.Errorf("trade %d = %+v, want %+v", i, got, w)
+				}
+			}
+			if got := e.positions["BTC"].size; !near(got, tt.wantPos) {
+				t.Errorf("position = %v, want %v", got, tt.wantPos)
+			}
+		})
+	}
+}
+
+func TestFillSlippageAndFees(t *testing.T) {
+	e := NewEngine(Config{FeeRate: 0.001, Slippage: 0.01}, nil, zap.NewNop())
+	e.positions = map[string]*position{"BTC": {size: 2, entry: 100}}
+	e.cash = 10000
+	e.orders = []*order{{coin: "BTC", kind: models.OrderKindSL, size: 2, trigger: 95}}
+
+	e.matchBar("BTC", bars([4]float64{100, 101, 94, 96})[0])
+
+	if len(e.trades) != 1 {
+		t.Fatalf("trades = %+v, want one stop fill", e.trades)
+	}
+	tr := e.trades[0]
+	px := 95 * 0.99
+	if !near(tr.Price, px) || !near(tr.Fee, 2*px*0.001) || !near(tr.ClosedPnL, 2*(px-100)) {
+		t.Fatalf("stop = %+v, want 2 @ %v paying %v", tr, px, 2*px*0.001)
+	}
+	if !near(e.cash, 10000+tr.ClosedPnL-tr.Fee) {
+		t.Errorf("cash = %v, want %v", e.cash, 10000+tr.ClosedPnL-tr.Fee)
+	}
+}
+
+func TestComputeMetrics(t *testing.T) {
+	tests := []struct {
+		name   string
+		equity []float64
+		trades []Trade
+		want   Metrics
+	}{
+		{name: "no steps", want: Metrics{InitialBalance: 100, FinalEquity: 100}},
+		{name: "drawdown from the running peak", equity: []float64{100, 120, 90, 110},
+			want: Metrics{InitialBalance: 100, FinalEquity: 110, TotalReturn: 0.1, MaxDrawdown: 0.25}},
+		{name: "losses below the initial balance", equity: []float64{95, 90, 98},
+			want: Metrics{InitialBalance: 100, FinalEquity: 98, TotalReturn: -0.02, MaxDrawdown: 0.1}},
+		{name: "trades", equity: []float64{100},
+			trades: []Trade{{Fee: 1}, {ClosedPnL: 30, Fee: 1}, {ClosedPnL: -10, Fee: 1}, {ClosedPnL: 5, Fee: 1}},
+			want: Metrics{InitialBalance: 100, FinalEquity: 100, Fills: 4, ClosedTrades: 3, WinRate: 2.0 / 3,
+				ProfitFactor: 3.5, RealizedPnL: 25, Fees: 4}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := computeMetrics(100, equityPoints(time.Hour, tt.equity...), tt.trades)
+			got.Sharpe = 0 // covered by TestSharpe
+			if !metricsNear(got, tt.want) {
+				t.Fatalf("computeMetrics = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSharpe(t *testing.T) {
+	tests := []struct {
+		name   string
+		step   time.Duration
+		equity []float64
+		want   float64
+	}{
+		{name: "too few steps", step: 24 * time.Hour, equity: []float64{100, 110}},
+		{name: "flat", step: 24 * time.Hour, equity: []float64{100, 100, 100, 100}},
+		// Returns 0.1, -0.1, 0.1: mean 1/30, sample stddev sqrt(1/75), 365 daily steps a year.
+		{name: "daily", step: 24 * time.Hour, equity: []float64{100, 110, 99, 108.9},
+			want: (1.0 / 30) / math.Sqrt(1.0/75) * math.Sqrt(365)},
+		{name: "hourly", step: time.Hour, equity: []float64{100, 110, 99, 108.9},
+			want: (1.0 / 30) / math.Sqrt(1.0/75) * math.Sqrt(365*24)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := sharpe(equityPoints(tt.step, tt.equity...)); math.Abs(got-tt.want) > 1e-6 {
+				t.Fatalf("sharpe = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func equityPoints(step time.Duration, values ...float64) []EquityPoint {
+	out := make([]EquityPoint, 0, len(values))
+	for i, v := range values {
+		out = append(out, EquityPoint{Time: t0.Add(time.Duration(i) * step), Equity: v})
+	}
+	return out
+}
+
+func metricsNear(a, b Metrics) bool {
+	return near(a.InitialBalance, b.InitialBalance) && near(a.FinalEquity, b.FinalEquity) &&
+		near(a.TotalReturn, b.TotalReturn) && near(a.MaxDrawdown, b.MaxDrawdown) && near(a.Sharpe, b.Sharpe) &&
+		a.Fills == b.Fills && a.ClosedTrades == b.ClosedTrades && near(a.WinRate, b.WinRate) &&
+		near(a.ProfitFactor, b.ProfitFactor) && near(a.RealizedPnL, b.RealizedPnL) && near(a.Fees, b.Fees)
+}
+
+func near(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
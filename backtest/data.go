@@ -0,0 +1,129 @@
+package backtest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	"deepseek-trader/hyperliquid"
+)
+
+// CandleSource is the subset of the Hyperliquid client used to download history.
+type CandleSource interface {
+	CandleSnapshot(ctx context.Context, coin, interval string, startTime, endTime int64) ([]hyperliquid.Candle, error)
+}
+
+// Series holds candles per coin, oldest first.
+type Series map[string][]hyperliquid.Candle
+
+// FetchCandles downloads candles of one interval for every coin in [from, to) with CandleSnapshot.
+func FetchCandles(ctx context.Context, src CandleSource, coins []string, interval string, from, to time.Time) (Series, error) {
+	out := make(Series, len(coins))
+	for _, coin := range coins {
+		candles, err := src.CandleSnapshot(ctx, coin, interval, from.UnixMilli(), to.UnixMilli())
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch candles for %s: %w", coin, err)
+		}
+		out[coin] = candles
+	}
+	out.sort()
+	return out, nil
+}
+
+// LoadCandles reads a JSON array of candles as returned by candleSnapshot, for any mix of coins.
+// Coins are taken from each candle's "s" field.
+func LoadCandles(path string) (Series, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var candles []hyperliquid.Candle
+	if err := json.Unmarshal(raw, &candles); err != nil {
+		return nil, err
+	}
+
+	out := make(Series)
+	for _, c := range candles {
+		out[c.Symbol] = append(out[c.Symbol], c)
+	}
+	out.sort()
+	return out, nil
+}
+
+// Save writes the series in the format LoadCandles reads, so a download can be replayed offline.
+func (s Series) Save(path string) error {
+	var all []hyperliquid.Candle
+	for _, list := range s {
+		all = append(all, list...)
+	}
+	b, err := json.Marshal(all)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, b, 0o600)
+}
+
+// interval returns the candle interval of the series, taken from the first candle's "i" field.
+func (s Series) interval() string {
+	for _, list := range s {
+		if len(list) > 0 {
+			return list[0].Interval
+		}
+	}
+	return ""
+}
+
+// resample aggregates candles (oldest first) into bars of length d aligned to the epoch, which matches
+// the exchange's buckets up to 1d. The last bar may be partial, like the exchange's current candle.
+func resample(candles []hyperliquid.Candle, interval string, d time.Duration) []hyperliquid.Candle {
+	step := d.Milliseconds()
+	var out []hyperliquid.Candle
+	var high, low, volume float64
+	for _, c := range candles {
+		start := c.StartTime - c.StartTime%step
+		if len(out) == 0 || out[len(out)-1].StartTime != start {
+			out = append(out, hyperliquid.Candle{
+				StartTime: start,
+				EndTime:   start + step - 1,
+				Symbol:    c.Symbol,
+				Interval:  interval,
+				Open:      c.Open,
+			})
+			high, low, volume = parseF(c.High), parseF(c.Low), 0
+		}
+		bar := &out[len(out)-1]
+		high = max(high, parseF(c.High))
+		low = min(low, parseF(c.Low))
+		volume += parseF(c.Volume)
+		bar.High, bar.Low, bar.Volume = formatF(high), formatF(low), formatF(volume)
+		bar.Close = c.Close
+		bar.NumTrades += c.NumTrades
+	}
+	return out
+}
+
+func (s Series) sort() {
+	for coin := range s {
+		list := s[coin]
+		sort.Slice(list, func(i, j int) bool { return list[i].StartTime < list[j].StartTime })
+	}
+}
+
+// timeline returns every distinct candle start time across coins, ascending.
+func (s Series) timeline() []int64 {
+	seen := make(map[int64]struct{})
+	for _, list := range s {
+		for _, c := range list {
+			seen[c.StartTime] = struct{}{}
+		}
+	}
+	out := make([]int64, 0, len(seen))
+	for t := range seen {
+		out = append(out, t)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i] < out[j] })
+	return out
+}
@@ -0,0 +1,741 @@
+package backtest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"deepseek-trader/agent"
+	"deepseek-trader/exits"
+	"deepseek-trader/hyperliquid"
+	"deepseek-trader/models"
+	"deepseek-trader/paper"
+	"deepseek-trader/risk"
+
+	"go.uber.org/zap"
+)
+
+// Config controls the simulation. Zero values fall back to the defaults used by the live bot.
+type Config struct {
+	InitialBalance float64
+	FeeRate        float64
+	// Slippage is the fraction market and triggered orders pay against the candle price.
+	Slippage float64
+	Leverage float64
+	// DecisionEvery is the number of candles between agent calls; 1 matches a 15m bot on 15m candles.
+	DecisionEvery int
+	// Timeframes are the candle windows passed to the agent, like the bot's. The series' own interval
+	// is used as is; longer intervals are resampled from it and must be multiples of it.
+	Timeframes []agent.Timeframe
+	Meta       hyperliquid.ExchangeMeta
+	// Risk, when set, vets and clamps decisions like the bot does before execution.
+	Risk *risk.Limits
+	// WarnDistance and DangerDistance grade the snapshot's margin health like the bot's monitor.
+	WarnDistance   float64
+	DangerDistance float64
+}
+
+// Result is everything a run produces.
+type Result struct {
+	Equity    []EquityPoint     `json:"equity"`
+	Trades    []Trade           `json:"trades"`
+	Decisions []models.Decision `json:"decisions"`
+	Metrics   Metrics           `json:"metrics"`
+}
+
+type EquityPoint struct {
+	Time   time.Time `json:"time"`
+	Equity float64   `json:"equity"`
+}
+
+// Trade is a simulated fill.
+type Trade struct {
+	Time       time.Time `json:"time"`
+	DecisionID int64     `json:"decisionId"`
+	Coin       string    `json:"coin"`
+	Side       string    `json:"side"`
+	Kind       string    `json:"kind"`
+	Size       float64   `json:"size"`
+	Price      float64   `json:"price"`
+	Fee        float64   `json:"fee"`
+	ClosedPnL  float64   `json:"closedPnl"`
+	// StartPosition is the signed position before the fill.
+	StartPosition float64 `json:"startPosition"`
+}
+
+type position struct {
+	size  float64 // signed: positive long, negative short
+	entry float64
+}
+
+type order struct {
+	decision models.Decision
+	coin     string
+	isBuy    bool
+	kind     string
+	size     float64
+	limit    float64 // resting limit entries and exits
+	trigger  float64 // reduce-only take-profit and stop legs
+}
+
+// Engine replays candles through a DecisionAgent and simulates execution the way the bot and the
+// exchange would: entries, then reduce-only TP/SL legs matched against each candle's range.
+type Engine struct {
+	cfg   Config
+	agent agent.DecisionAgent
+	log   *zap.Logger
+
+	now       time.Time
+	cash      float64
+	positions map[string]*position
+	orders    []*order
+	mids      map[string]float64
+	trades    []Trade
+	decisions []models.Decision
+}
+
+func NewEngine(cfg Config, ag agent.DecisionAgent, log *zap.Logger) *Engine {
+	if cfg.InitialBalance <= 0 {
+		cfg.InitialBalance = 10000
+	}
+	if cfg.Leverage <= 0 {
+		cfg.Leverage = 1
+	}
+	if cfg.DecisionEvery <= 0 {
+		cfg.DecisionEvery = 1
+	}
+	if len(cfg.Timeframes) == 0 {
+		cfg.Timeframes = agent.DefaultTimeframes()
+	}
+	return &Engine{cfg: cfg, agent: ag, log: log}
+}
+
+// Run steps through the series candle by candle. Each step first matches resting orders against the
+// candle's range, then, on decision steps, asks the agent at the candle close and executes the answer.
+func (e *Engine) Run(ctx context.Context, series Series) (Result, error) {
+	timeline := series.timeline()
+	if len(timeline) == 0 {
+		return Result{}, errors.New("no candles to replay")
+	}
+	if err := e.checkTimeframes(series.interval()); err != nil {
+		return Result{}, err
+	}
+
+	e.cash = e.cfg.InitialBalance
+	e.positions = make(map[string]*position)
+	e.orders = nil
+	e.mids = make(map[string]float64)
+	e.trades = nil
+	e.decisions = nil
+
+	cursors := make(map[string]int, len(series))
+	warmup := time.UnixMilli(timeline[0]).Add(agent.Shortest(e.cfg.Timeframes).Lookback)
+	var equity []EquityPoint
+
+	for i, t := range timeline {
+		if err := ctx.Err(); err != nil {
+			return Result{}, err
+		}
+
+		var closeTime int64
+		for coin, list := range series {
+			j := cursors[coin]
+			if j >= len(list) || list[j].StartTime != t {
+				continue
+			}
+			bar := list[j]
+			cursors[coin] = j + 1
+			e.matchBar(coin, bar)
+			e.mids[coin] = parseF(bar.Close)
+			closeTime = max(closeTime, bar.EndTime)
+		}
+		e.now = time.UnixMilli(closeTime)
+
+		if i%e.cfg.DecisionEvery == 0 && !e.now.Before(warmup) {
+			if err := e.decide(ctx, series, cursors); err != nil {
+				return Result{}, err
+			}
+		}
+		equity = append(equity, EquityPoint{Time: e.now, Equity: e.equity()})
+	}
+
+	return Result{
+		Equity:    equity,
+		Trades:    e.trades,
+		Decisions: e.decisions,
+		Metrics:   computeMetrics(e.cfg.InitialBalance, equity, e.trades),
+	}, nil
+}
+
+func (e *Engine) decide(ctx context.Context, series Series, cursors map[string]int) error {
+	snap := e.snapshot(series, cursors)
+	decs, _, err := e.agent.Decide(ctx, snap)
+	var verr *agent.ValidationError
+	if err != nil && !errors.As(err, &verr) {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		e.log.Sugar().Warnw("failed to get decision", "time", e.now, "error", err)
+		return nil
+	}
+
+	ds := make([]models.Decision, 0, len(decs))
+	for _, dec := range decs {
+		ds = append(ds, models.Decision{
+			ID:         int64(len(e.decisions) + len(ds) + 1),
+			Action:     dec.Action,
+			Symbol:     hyperliquid.NormalizeSymbol(dec.Symbol),
+			Size:       dec.Size,
+			OrderType:  dec.Order,
+			LimitPrice: dec.LimitPrice,
+			Fraction:   dec.Fraction,
+			TP1:        dec.Targets.TP1,
+			TP2:        dec.Targets.TP2,
+			TP3:        dec.Targets.TP3,
+			SL:         dec.Targets.SL,
+			Confidence: dec.Confidence,
+			Scores:     dec.ScoresJSON(),
+			Rationale:  dec.Rationale,
+			Status:     models.DecisionPending,
+			CreatedAt:  e.now,
+		})
+	}
+	if verr != nil {
+		for i := range ds {
+			ds[i].Status, ds[i].Reason = models.DecisionRejected, strings.Join(verr.Errors, "; ")
+		}
+	} else {
+		e.checkRisk(ds)
+	}
+	for i := range ds {
+		if ds[i].Status == models.DecisionPending {
+			status, reason := e.execute(ds[i])
+			ds[i].Status = status
+			if reason != "" {
+				ds[i].Reason = reason
+			}
+		}
+	}
+	e.decisions = append(e.decisions, ds...)
+	return nil
+}
+
+// snapshot builds the agent input the way bot.Service.snapshot does, from the candles visible at e.now.
+// Historical candles carry no depth, so OrderBooks is left empty.
+func (e *Engine) snapshot(series Series, cursors map[string]int) agent.Snapshot {
+	bal := e.equity()
+	pnl := bal - e.cfg.InitialBalance
+	roe := 0.0
+	if bal != 0 {
+		roe = (pnl / bal) * 100
+	}
+
+	mids := make(map[string]string, len(e.mids))
+	for coin, px := range e.mids {
+		mids[coin] = formatF(px)
+	}
+
+	candles := make(map[string]map[string][]hyperliquid.Candle, len(series))
+	history := make(map[string]map[string][]hyperliquid.Candle, len(series))
+	for coin, list := range series {
+		visible := list[:cursors[coin]]
+		if len(visible) == 0 {
+			continue
+		}
+		history[coin], candles[coin] = e.timeframes(visible)
+	}
+
+	snap := agent.Snapshot{
+		Balance:         bal,
+		PnL:             pnl,
+		ROE:             roe,
+		CoinsMids:       agent.FilterCoinsMids(mids),
+		Meta:            e.cfg.Meta,
+		Timeframes:      e.cfg.Timeframes,
+		CandleSnapshots: candles,
+		Indicators:      agent.Indicators(history),
+	}
+	e.accountState(&snap)
+	for i := len(e.trades) - 1; i >= 0 && len(snap.Trades) < 200; i-- {
+		snap.Trades = append(snap.Trades, userFill(e.trades[i]))
+	}
+	for i := len(e.decisions) - 1; i >= 0 && len(snap.Decisions) < 10; i-- {
+		snap.Decisions = append(snap.Decisions, e.decisions[i])
+	}
+	return snap
+}
+
+// checkTimeframes rejects timeframes that cannot be built from candles of the series' interval.
+func (e *Engine) checkTimeframes(base string) error {
+	if err := agent.ValidateTimeframes(e.cfg.Timeframes); err != nil {
+		return err
+	}
+	baseD, ok := hyperliquid.IntervalDuration(base)
+	if !ok {
+		return fmt.Errorf("candles have unknown interval %q", base)
+	}
+	for _, tf := range e.cfg.Timeframes {
+		d, _ := hyperliquid.IntervalDuration(tf.Interval)
+		if d < baseD || d%baseD != 0 {
+			return fmt.Errorf("timeframe %s cannot be built from %s candles", tf.Interval, base)
+		}
+	}
+	return nil
+}
+
+// timeframes builds each timeframe's indicator history and snapshot window from the visible candles,
+// the same split bot.Service.candles makes.
+func (e *Engine) timeframes(visible []hyperliquid.Candle) (history, window map[string][]hyperliquid.Candle) {
+	history = make(map[string][]hyperliquid.Candle, len(e.cfg.Timeframes))
+	window = make(map[string][]hyperliquid.Candle, len(e.cfg.Timeframes))
+	for _, tf := range e.cfg.Timeframes {
+		d, _ := hyperliquid.IntervalDuration(tf.Interval)
+		from := tf.HistoryStart(e.now).UnixMilli()
+		from -= from % d.Milliseconds() // start on a bucket boundary so the first resampled bar is whole
+		bars := visible[sort.Search(len(visible), func(i int) bool { return visible[i].StartTime >= from }):]
+		if tf.Interval != visible[0].Interval {
+			bars = resample(bars, tf.Interval, d)
+		}
+		history[tf.Interval] = bars
+
+		from = e.now.Add(-tf.Lookback).UnixMilli()
+		if i := sort.Search(len(bars), func(i int) bool { return bars[i].StartTime >= from }); i < len(bars) {
+			window[tf.Interval] = bars[i:]
+		}
+	}
+	return history, window
+}
+
+// accountState fills the snapshot's positions and open orders the way clearinghouseState and
+// frontendOpenOrders would report them, marked to the current mids.
+func (e *Engine) accountState(snap *agent.Snapshot) {
+	coins := make([]string, 0, len(e.positions))
+	for coin, p := range e.positions {
+		if p.size != 0 {
+			coins = append(coins, coin)
+		}
+	}
+	sort.Strings(coins)
+	for _, coin := range coins {
+		p := e.positions[coin]
+		value := math.Abs(p.size) * e.mids[coin]
+		lev := e.leverage(coin, value)
+		margin := value / lev
+		upnl := p.size * (e.mids[coin] - p.entry)
+		roe := 0.0
+		if margin > 0 {
+			roe = upnl / margin
+		}
+		snap.MarginUsed += margin
+		snap.Positions = append(snap.Positions, hyperliquid.Position{
+			Coin:           coin,
+			Size:           p.size,
+			EntryPrice:     p.entry,
+			PositionValue:  value,
+			UnrealizedPnL:  upnl,
+			ReturnOnEquity: roe,
+			MarginUsed:     margin,
+			Leverage:       int(lev),
+			LeverageType:   "cross",
+		})
+	}
+	snap.Health = e.cfg.Meta.HealthOf(hyperliquid.AccountState{AccountValue: e.equity(), Positions: snap.Positions}, e.cfg.WarnDistance, e.cfg.DangerDistance, true)
+
+	for _, o := range e.orders {
+		oo := hyperliquid.OpenOrder{
+			Coin:       o.coin,
+			IsBuy:      o.isBuy,
+			LimitPrice: o.limit,
+			Size:       o.size,
+			OrigSize:   o.size,
+			OrderType:  "Limit",
+			ReduceOnly: o.kind != models.OrderKindEntry,
+			IsTrigger:  o.trigger > 0,
+			TriggerPx:  o.trigger,
+		}
+		switch o.kind {
+		case models.OrderKindSL:
+			oo.OrderType = "Stop Market"
+		case models.OrderKindEntry, models.OrderKindExit:
+		default:
+			oo.OrderType = "Take Profit Market"
+		}
+		snap.OpenOrders = append(snap.OpenOrders, oo)
+	}
+}
+
+// checkRisk applies the configured limits to a cycle's entries together, clamping their sizes or
+// marking them rejected with the veto reason.
+func (e *Engine) checkRisk(ds []models.Decision) {
+	if e.cfg.Risk == nil {
+		return
+	}
+	var entries []int
+	var orders []risk.Order
+	for i, d := range ds {
+		if d.Action != "buy" && d.Action != "sell" && d.Action != "reverse" {
+			continue
+		}
+		price := d.LimitPrice
+		if !strings.EqualFold(d.OrderType, "limit") || price <= 0 {
+			price = e.mids[d.Symbol]
+		}
+		decimals := 8
+		if in, ok := e.cfg.Meta.Instrument(d.Symbol); ok {
+			decimals = in.SzDecimals
+		}
+		isBuy := d.Action == "buy"
+		if d.Action == "reverse" {
+			isBuy = e.position(d.Symbol).size < 0
+		}
+		entries = append(entries, i)
+		orders = append(orders, risk.Order{
+			Coin: d.Symbol, IsBuy: isBuy, Size: d.Size, Price: price, StopLoss: d.SL, SzDecimals: decimals,
+			Confidence: d.Confidence, Reverse: d.Action == "reverse",
+		})
+	}
+	if len(orders) == 0 {
+		return
+	}
+
+	fills := make([]hyperliquid.UserFill, 0, len(e.trades))
+	for _, t := range e.trades {
+		fills = append(fills, userFill(t))
+	}
+	positions := make(map[string]float64, len(e.positions))
+	for coin, p := range e.positions {
+		positions[coin] = p.size
+	}
+	verdicts := e.cfg.Risk.CheckAll(
+		risk.Account{Now: e.now, Balance: e.equity(), Positions: positions, Fills: fills, Decisions: e.decisions, Prices: e.mids},
+		orders,
+	)
+	for k, i := range entries {
+		ds[i].Reason = verdicts[k].Reason
+		if verdicts[k].Approved {
+			ds[i].Size = verdicts[k].Size
+		} else {
+			ds[i].Status = models.DecisionRejected
+		}
+	}
+}
+
+// execute carries out a decision and returns its resulting status and reason. Close, reduce, reverse
+// and adjust act on the simulated position like the bot's do on the exchange one.
+func (e *Engine) execute(d models.Decision) (string, string) {
+	switch d.Action {
+	case "buy", "sell":
+		return e.enter(d, d.Action == "buy")
+	case "close", "reduce", "reverse", "adjust":
+	default:
+		return models.DecisionSkipped, "no action"
+	}
+
+	pos := e.position(d.Symbol).size
+	if pos == 0 {
+		return models.DecisionRejected, "no open " + d.Symbol + " position"
+	}
+	switch d.Action {
+	case "adjust":
+		e.adjust(d, pos)
+		return models.DecisionExecuted, ""
+	case "reverse":
+		if status, reason := e.place(d, pos < 0, exits.CloseSize(d, pos), models.OrderKindExit); status != models.DecisionExecuted {
+			return status, reason
+		}
+		if e.position(d.Symbol).size != 0 {
+			return models.DecisionPartial, "close is resting, reverse entry not placed"
+		}
+		return e.enter(d, pos < 0)
+	default:
+		return e.place(d, pos < 0, exits.CloseSize(d, pos), models.OrderKindExit)
+	}
+}
+
+// enter opens a new position on the given side, checked against the margin available.
+func (e *Engine) enter(d models.Decision, isBuy bool) (string, string) {
+	mid := e.mids[d.Symbol]
+	if mid > 0 && !e.hasMargin(d.Symbol, isBuy, d.Size, mid) {
+		return models.DecisionRejected, "insufficient margin"
+	}
+	return e.place(d, isBuy, d.Size, models.OrderKindEntry)
+}
+
+// place fills a market or marketable limit order at once and rests any other limit order.
+func (e *Engine) place(d models.Decision, isBuy bool, size float64, kind string) (string, string) {
+	mid, ok := e.mids[d.Symbol]
+	if !ok || mid <= 0 {
+		return models.DecisionRejected, "no price for " + d.Symbol
+	}
+	if in, ok := e.cfg.Meta.Instrument(d.Symbol); ok {
+		size = hyperliquid.FloorSize(size, in.SzDecimals)
+	}
+	if size <= 0 {
+		return models.DecisionRejected, "size must be positive"
+	}
+
+	market := !strings.EqualFold(d.OrderType, "limit")
+	if !market && d.LimitPrice <= 0 {
+		return models.DecisionRejected, "limit order without limitPrice"
+	}
+
+	o := &order{decision: d, coin: d.Symbol, isBuy: isBuy, kind: kind, size: size, limit: d.LimitPrice}
+	marketable := market || (isBuy && d.LimitPrice >= mid) || (!isBuy && d.LimitPrice <= mid)
+	if !marketable {
+		e.orders = append(e.orders, o)
+		return models.DecisionExecuted, ""
+	}
+
+	px := e.slipped(mid, isBuy)
+	if !market {
+		px = bound(px, d.LimitPrice, isBuy)
+	}
+	e.fill(o, size, px)
+	return models.DecisionExecuted, ""
+}
+
+// adjust replaces the position's legs of every target set in the decision, keeping the size of the
+// legs it replaces or taking the tier's share of the position when there are none.
+func (e *Engine) adjust(d models.Decision, pos float64) {
+	decimals := 8
+	if in, ok := e.cfg.Meta.Instrument(d.Symbol); ok {
+		decimals = in.SzDecimals
+	}
+	prices := exits.Prices(d)
+	for _, kind := range exits.Kinds {
+		if prices[kind] <= 0 {
+			continue
+		}
+		size := 0.0
+		for _, o := range append([]*order(nil), e.orders...) {
+			if o.coin == d.Symbol && o.kind == kind {
+				size += o.size
+				e.remove(o)
+			}
+		}
+		if size = exits.AdjustSize(kind, size, pos, decimals); size > 0 {
+			e.orders = append(e.orders, &order{decision: d, coin: d.Symbol, isBuy: pos < 0, kind: kind, size: size, trigger: prices[kind]})
+		}
+	}
+}
+
+// matchBar fills resting limit orders and fires exits whose price lies inside the candle. Stops are checked
+// before take-profits since the candle does not say which was reached first.
+func (e *Engine) matchBar(coin string, bar hyperliquid.Candle) {
+	open, high, low := parseF(bar.Open), parseF(bar.High), parseF(bar.Low)
+	e.now = time.UnixMilli(bar.StartTime)
+
+	var pending []*order
+	for _, o := range e.orders {
+		if o.coin == coin {
+			pending = append(pending, o)
+		}
+	}
+	sort.SliceStable(pending, func(i, j int) bool {
+		return pending[i].kind == models.OrderKindSL && pending[j].kind != models.OrderKindSL
+	})
+
+	for _, o := range pending {
+		if !e.isOpen(o) {
+			continue
+		}
+		switch {
+		case o.trigger == 0:
+			if (o.isBuy && low <= o.limit) || (!o.isBuy && high >= o.limit) {
+				e.remove(o)
+				e.fill(o, o.size, bound(open, o.limit, o.isBuy))
+			}
+		case triggered(o, high, low):
+			e.remove(o)
+			px := o.trigger
+			if triggered(o, open, open) {
+				px = open // opened past the trigger
+			}
+			e.fill(o, o.size, e.slipped(px, o.isBuy))
+		}
+	}
+}
+
+func triggered(o *order, high, low float64) bool {
+	stop := o.kind == models.OrderKindSL
+	if o.isBuy == stop {
+		return high >= o.trigger // buy stop (short SL) or sell take-profit (long TP)
+	}
+	return low <= o.trigger
+}
+
+// fill applies a fill to the position and cash. Reduce-only exits are clamped to the open position and
+// every remaining exit for the coin is dropped once it is flat, like reduceOnlyCanceled on the exchange.
+func (e *Engine) fill(o *order, size, px float64) {
+	pos := e.position(o.coin)
+	if o.kind != models.OrderKindEntry {
+		if (o.isBuy && pos.size >= 0) || (!o.isBuy && pos.size <= 0) {
+			return
+		}
+		size = math.Min(size, math.Abs(pos.size))
+	}
+
+	qty := size
+	if !o.isBuy {
+		qty = -size
+	}
+	start := pos.size
+	var closed float64
+	pos.size, pos.entry, closed = paper.ApplyFill(pos.size, pos.entry, qty, px)
+	fee := size * px * e.cfg.FeeRate
+	e.cash += closed - fee
+
+	side := "sell"
+	if o.isBuy {
+		side = "buy"
+	}
+	e.trades = append(e.trades, Trade{
+		Time:          e.now,
+		DecisionID:    o.decision.ID,
+		Coin:          o.coin,
+		Side:          side,
+		Kind:          o.kind,
+		Size:          size,
+		Price:         px,
+		Fee:           fee,
+		ClosedPnL:     closed,
+		StartPosition: start,
+	})
+
+	if o.kind == models.OrderKindEntry {
+		e.protect(o.decision, o.isBuy, size)
+	}
+	if pos.size == 0 {
+		e.dropExits(o.coin)
+	}
+}
+
+// protect attaches TP1/TP2/TP3 and SL legs for a filled entry, split like bot.protect.
+func (e *Engine) protect(d models.Decision, isLong bool, size float64) {
+	decimals := 8
+	if in, ok := e.cfg.Meta.Instrument(d.Symbol); ok {
+		decimals = in.SzDecimals
+	}
+	for _, l := range exits.Legs(d, size, decimals) {
+		e.orders = append(e.orders, &order{decision: d, coin: d.Symbol, isBuy: !isLong, kind: l.Kind, size: l.Size, trigger: l.Price})
+	}
+}
+
+func (e *Engine) dropExits(coin string) {
+	kept := e.orders[:0]
+	for _, o := range e.orders {
+		if o.coin != coin || o.kind == models.OrderKindEntry {
+			kept = append(kept, o)
+		}
+	}
+	e.orders = kept
+}
+
+func (e *Engine) remove(target *order) {
+	for i, o := range e.orders {
+		if o == target {
+			e.orders = append(e.orders[:i], e.orders[i+1:]...)
+			return
+		}
+	}
+}
+
+func (e *Engine) isOpen(target *order) bool {
+	for _, o := range e.orders {
+		if o == target {
+			return true
+		}
+	}
+	return false
+}
+
+// hasMargin checks that the initial margin of all open positions plus the new entry fits in equity, each
+// position margined at the configured leverage capped by its margin tier.
+func (e *Engine) hasMargin(coin string, isBuy bool, size, px float64) bool {
+	pos := e.position(coin)
+	if (isBuy && pos.size < 0) || (!isBuy && pos.size > 0) {
+		return true // reducing or flipping an existing position
+	}
+	value := (math.Abs(pos.size) + size) * px
+	margin := value / e.leverage(coin, value)
+	for c, p := range e.positions {
+		if c == coin {
+			continue
+		}
+		v := math.Abs(p.size) * e.mids[c]
+		margin += v / e.leverage(c, v)
+	}
+	return margin <= e.equity()
+}
+
+// leverage is the configured leverage, capped by the coin's margin tier for a position of notional USD
+// when the meta lists the coin.
+func (e *Engine) leverage(coin string, notional float64) float64 {
+	if maxLev, err := e.cfg.Meta.MaxLeverageAt(coin, notional); err == nil && maxLev > 0 && float64(maxLev) < e.cfg.Leverage {
+		return float64(maxLev)
+	}
+	return e.cfg.Leverage
+}
+
+func (e *Engine) equity() float64 {
+	eq := e.cash
+	for coin, p := range e.positions {
+		eq += p.size * (e.mids[coin] - p.entry)
+	}
+	return eq
+}
+
+func (e *Engine) position(coin string) *position {
+	p, ok := e.positions[coin]
+	if !ok {
+		p = &position{}
+		e.positions[coin] = p
+	}
+	return p
+}
+
+func (e *Engine) slipped(px float64, isBuy bool) float64 {
+	if isBuy {
+		return px * (1 + e.cfg.Slippage)
+	}
+	return px * (1 - e.cfg.Slippage)
+}
+
+// bound caps a fill price at the order's limit.
+func bound(px, limit float64, isBuy bool) float64 {
+	if isBuy {
+		return math.Min(px, limit)
+	}
+	return math.Max(px, limit)
+}
+
+func userFill(t Trade) hyperliquid.UserFill {
+	side := "A"
+	if t.Side == "buy" {
+		side = "B"
+	}
+	return hyperliquid.UserFill{
+		Coin:          t.Coin,
+		Px:            formatF(t.Price),
+		Sz:            formatF(t.Size),
+		Time:          t.Time.UnixMilli(),
+		Side:          side,
+		StartPosition: formatF(t.StartPosition),
+		Dir:           paper.Direction(t.StartPosition, t.Size, t.Side == "buy"),
+		ClosedPnl:     formatF(t.ClosedPnL),
+	}
+}
+
+func parseF(s string) float64 {
+	f, _ := strconv.ParseFloat(s, 64)
+	return f
+}
+
+func formatF(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
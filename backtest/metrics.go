@@ -0,0 +1,102 @@
+package backtest
+
+import (
+	"math"
+	"time"
+)
+
+// Metrics summarizes a run. Returns and drawdown are fractions (0.05 = 5%).
+type Metrics struct {
+	InitialBalance float64 `json:"initialBalance"`
+	FinalEquity    float64 `json:"finalEquity"`
+	TotalReturn    float64 `json:"totalReturn"`
+	MaxDrawdown    float64 `json:"maxDrawdown"`
+	// Sharpe is the annualized mean/stddev of per-step equity returns, risk-free rate zero.
+	Sharpe       float64 `json:"sharpe"`
+	Fills        int     `json:"fills"`
+	ClosedTrades int     `json:"closedTrades"`
+	WinRate      float64 `json:"winRate"`
+	ProfitFactor float64 `json:"profitFactor"`
+	RealizedPnL  float64 `json:"realizedPnl"`
+	Fees         float64 `json:"fees"`
+}
+
+func computeMetrics(initial float64, equity []EquityPoint, trades []Trade) Metrics {
+	m := Metrics{InitialBalance: initial, FinalEquity: initial, Fills: len(trades)}
+	if len(equity) > 0 {
+		m.FinalEquity = equity[len(equity)-1].Equity
+	}
+	if initial != 0 {
+		m.TotalReturn = (m.FinalEquity - initial) / initial
+	}
+
+	peak := initial
+	for _, p := range equity {
+		peak = math.Max(peak, p.Equity)
+		if peak > 0 {
+			m.MaxDrawdown = math.Max(m.MaxDrawdown, (peak-p.Equity)/peak)
+		}
+	}
+	m.Sharpe = sharpe(equity)
+
+	var wins int
+	var gross, loss float64
+	for _, t := range trades {
+		m.Fees += t.Fee
+		m.RealizedPnL += t.ClosedPnL
+		if t.ClosedPnL == 0 {
+			continue
+		}
+		m.ClosedTrades++
+		if t.ClosedPnL > 0 {
+			wins++
+			gross += t.ClosedPnL
+		} else {
+			loss -= t.ClosedPnL
+		}
+	}
+	if m.ClosedTrades > 0 {
+		m.WinRate = float64(wins) / float64(m.ClosedTrades)
+	}
+	if loss > 0 {
+		m.ProfitFactor = gross / loss
+	}
+	return m
+}
+
+func sharpe(equity []EquityPoint) float64 {
+	if len(equity) < 3 {
+		return 0
+	}
+	rets := make([]float64, 0, len(equity)-1)
+	for i := 1; i < len(equity); i++ {
+		if prev := equity[i-1].Equity; prev != 0 {
+			rets = append(rets, equity[i].Equity/prev-1)
+		}
+	}
+
+	if len(rets) < 2 {
+		return 0
+	}
+
+	var mean float64
+	for _, r := range rets {
+		mean += r
+	}
+	mean /= float64(len(rets))
+	var variance float64
+	for _, r := range rets {
+		variance += (r - mean) * (r - mean)
+	}
+	std := math.Sqrt(variance / float64(len(rets)-1))
+	if std == 0 {
+		return 0
+	}
+
+	step := equity[1].Time.Sub(equity[0].Time)
+	if step <= 0 {
+		return 0
+	}
+	perYear := float64(365*24*time.Hour) / float64(step)
+	return mean / std * math.Sqrt(perYear)
+}
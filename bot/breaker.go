@@ -0,0 +1,126 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"deepseek-trader/hyperliquid"
+	"deepseek-trader/risk"
+	"deepseek-trader/services"
+)
+
+// KillRequest stops a bot with the kill switch.
+type KillRequest struct {
+	// Flatten closes every position with a reduce-only market order once the open orders are cancelled.
+	Flatten bool   `json:"flatten"`
+	Reason  string `json:"reason"`
+}
+
+// HaltReport is what stopping a bot did on the exchange.
+type HaltReport struct {
+	Reason    string   `json:"reason"`
+	Canceled  int      `json:"canceled"`
+	Flattened []string `json:"flattened,omitempty"`
+	Errors    []string `json:"errors,omitempty"`
+}
+
+// checkBreaker runs the breaker's drawdown and loss-streak rules against the account and trips it
+// when one is hit. It reports whether the bot may keep trading.
+func (s *Service) checkBreaker(ctx context.Context, balance float64, positions []hyperliquid.Position) bool {
+	b := s.botCfg.Breaker
+	if b.MaxDailyDrawdown <= 0 && b.MaxLossStreak <= 0 {
+		return true
+	}
+	fills, err := s.userFills(ctx)
+	if err != nil {
+		s.log.Sugar().Errorw("breaker: failed to load fills", "error", err)
+		return true
+	}
+	unrealized := 0.0
+	for _, p := range positions {
+		unrealized += p.UnrealizedPnL
+	}
+	reason := b.Trip(risk.Account{Now: time.Now(), Balance: balance, Fills: fills}, unrealized, s.armed)
+	if reason == "" {
+		return true
+	}
+	s.trip(ctx, reason)
+	return false
+}
+
+// apiFailed counts a failed exchange call and trips the breaker after MaxAPIFailures in a row.
+func (s *Service) apiFailed(ctx context.Context, what string) {
+	s.failures++
+	if s.botCfg.Breaker.Failed(s.failures) {
+		s.trip(ctx, fmt.Sprintf("breaker: %d consecutive exchange failures, last: %s", s.failures, what))
+	}
+}
+
+// trip stops trading for good: it cancels every open order, flattens the positions when the breaker
+// says so and hands the bot to onTrip, which records the reason and stops it until it is re-armed.
+// The loop does nothing more until then.
+func (s *Service) trip(ctx context.Context, reason string) {
+	if s.tripped {
+		return
+	}
+	s.tripped = true
+	s.log.Sugar().Errorw("circuit breaker tripped", "reason", reason)
+
+	rep := halt(ctx, s.ex, s.tradesSvc, s.userID, s.botCfg.Breaker.Flatten)
+	rep.Reason = reason
+	s.log.Sugar().Warnw("bot halted", "canceled", rep.Canceled, "flattened", rep.Flattened, "errors", rep.Errors)
+	if s.onTrip != nil {
+		go s.onTrip(reason)
+	}
+}
+
+// halt cancels every open order on the exchange, TP/SL triggers included, and with flatten closes every
+// position with a reduce-only market order, recording the fills as trades. It keeps going past failures
+// and reports them.
+func halt(ctx context.Context, ex Exchange, trades *services.TradesService, userID int64, flatten bool) HaltReport {
+	var rep HaltReport
+	n, err := ex.CancelAll(ctx, "")
+	rep.Canceled = n
+	if err != nil {
+		rep.Errors = append(rep.Errors, "failed to cancel open orders: "+err.Error())
+	}
+	if !flatten {
+		return rep
+	}
+
+	st, err := ex.AccountState(ctx)
+	if err != nil {
+		rep.Errors = append(rep.Errors, "failed to get positions: "+err.Error())
+		return rep
+	}
+	for _, p := range st.Positions {
+		if p.Size == 0 {
+			continue
+		}
+		res, err := ex.PlaceOrder(ctx, hyperliquid.OrderRequest{
+			Coin:       p.Coin,
+			IsBuy:      p.Size < 0,
+			Size:       math.Abs(p.Size),
+			Market:     true,
+			ReduceOnly: true,
+		})
+		if err != nil {
+			rep.Errors = append(rep.Errors, fmt.Sprintf("failed to close %s: %v", p.Coin, err))
+			continue
+		}
+		rep.Flattened = append(rep.Flattened, p.Coin)
+		if res.Status != hyperliquid.OrderStatusFilled {
+			continue
+		}
+		side := "sell"
+		if p.Size < 0 {
+			side = "buy"
+		}
+		if _, err := trades.Record(ctx, userID, p.Coin+"USDT", side, res.FilledSize, res.AvgPrice); err != nil {
+			rep.Errors = append(rep.Errors, fmt.Sprintf("failed to record %s close: %v", p.Coin, err))
+		}
+	}
+	return rep
+}
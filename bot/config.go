@@ -0,0 +1,123 @@
+package bot
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"deepseek-trader/agent"
+	"deepseek-trader/config"
+	"deepseek-trader/hyperliquid"
+	"deepseek-trader/risk"
+	"deepseek-trader/services"
+)
+
+// Config is the per-bot configuration. DefaultConfig fills it from the environment.
+type Config struct {
+	Agent      agent.ProviderConfig   `json:"agent"`
+	Validation agent.ValidationConfig `json:"validation"`
+	Risk       risk.Limits            `json:"risk"`
+	Leverage   LeveragePolicy         `json:"leverage"`
+	Health     HealthConfig           `json:"health"`
+	Breaker    risk.Breaker           `json:"breaker"`
+	Orders     OrderPolicy            `json:"orders"`
+	// Timeframes are the candle intervals fetched every tick; the shortest one is the entry timeframe.
+	Timeframes []agent.Timeframe `json:"timeframes"`
+}
+
+// DefaultConfig builds a bot config from settings. LLM_* variables select the provider; with the
+// default deepseek provider the DEEPSEEK_* variables are used for anything LLM_* leaves unset.
+func DefaultConfig(cfg *config.Settings) (Config, error) {
+	ac := agent.ProviderConfig{
+		Provider:    cfg.LLMProvider,
+		BaseURL:     cfg.LLMBaseURL,
+		Model:       cfg.LLMModel,
+		APIKey:      cfg.LLMAPIKey,
+		Temperature: cfg.LLMTemperature,
+		MaxTokens:   cfg.LLMMaxTokens,
+		Timeout:     time.Duration(cfg.LLMTimeout) * time.Second,
+	}
+	if ac.Provider == "" || ac.Provider == agent.ProviderDeepseek {
+		ac.Provider = agent.ProviderDeepseek
+		if ac.BaseURL == "" {
+			ac.BaseURL = cfg.DeepseekBaseURL
+		}
+		if ac.Model == "" {
+			ac.Model = cfg.DeepseekModel
+		}
+		if ac.APIKey == "" {
+			ac.APIKey = cfg.DeepseekAPIKey
+		}
+	}
+	timeframes := agent.DefaultTimeframes()
+	if cfg.CandleTimeframes != "" {
+		var err error
+		if timeframes, err = agent.ParseTimeframes(cfg.CandleTimeframes); err != nil {
+			return Config{}, fmt.Errorf("CANDLE_TIMEFRAMES: %w", err)
+		}
+	}
+	perCoin, err := ParseLeverages(cfg.LeveragePerCoin)
+	if err != nil {
+		return Config{}, fmt.Errorf("LEVERAGE_PER_COIN: %w", err)
+	}
+	switch cfg.OrderLimitTif {
+	case hyperliquid.TifGtc, hyperliquid.TifIoc, hyperliquid.TifAlo:
+	default:
+		return Config{}, fmt.Errorf("ORDER_LIMIT_TIF: want Gtc, Ioc or Alo, got %q", cfg.OrderLimitTif)
+	}
+	return Config{
+		Agent:      ac,
+		Validation: agent.ValidationConfig{PriceBand: cfg.DecisionBand, Repair: cfg.DecisionRepair, MaxDecisions: cfg.DecisionMax},
+		Risk: risk.Limits{
+			MaxRiskPerTrade:     cfg.RiskPerTrade,
+			MaxPositionValue:    cfg.RiskMaxPosition,
+			DefaultStopDistance: risk.DefaultLimits().DefaultStopDistance,
+			SymbolCooldown:      time.Duration(cfg.RiskCooldown) * time.Minute,
+			MaxTradesPerHour:    cfg.RiskPerHour,
+			MaxTradesPerDay:     cfg.RiskPerDay,
+			LossStreak:          cfg.RiskLossStreak,
+			LossPause:           time.Duration(cfg.RiskLossPause) * time.Minute,
+			MinConfidence:       cfg.RiskMinConfidence,
+			MaxTotalExposure:    cfg.RiskMaxTotal,
+		},
+		Leverage: LeveragePolicy{Default: cfg.LeverageDefault, Isolated: cfg.LeverageIsolated, PerCoin: perCoin},
+		Health: HealthConfig{
+			WarnDistance:   cfg.MarginWarn,
+			DangerDistance: cfg.MarginDanger,
+			AutoReduce:     cfg.MarginAutoReduce,
+			ReduceFraction: cfg.MarginReduce,
+			CheckEvery:     time.Duration(cfg.MarginCheckEvery) * time.Second,
+		},
+		Breaker: risk.Breaker{
+			MaxDailyDrawdown: cfg.BreakerDrawdown,
+			MaxLossStreak:    cfg.BreakerLossStreak,
+			MaxAPIFailures:   cfg.BreakerAPIFails,
+			Flatten:          cfg.BreakerFlatten,
+		},
+		Orders: OrderPolicy{
+			Slippage: cfg.OrderSlippage,
+			LimitTif: cfg.OrderLimitTif,
+			TTL:      time.Duration(cfg.OrderTTL) * time.Minute,
+		},
+		Timeframes: timeframes,
+	}, nil
+}
+
+// LoadConfig loads the user's stored config on top of the defaults, so fields added later keep their defaults.
+func LoadConfig(ctx context.Context, botsSvc *services.BotsService, cfg *config.Settings, userID int64) (Config, error) {
+	botCfg, err := DefaultConfig(cfg)
+	if err != nil {
+		return Config{}, err
+	}
+	b, ok, err := botsSvc.Get(ctx, userID)
+	if err != nil {
+		return Config{}, err
+	}
+	if ok && len(b.Config) > 0 {
+		if err := json.Unmarshal(b.Config, &botCfg); err != nil {
+			return Config{}, fmt.Errorf("invalid stored bot config: %w", err)
+		}
+	}
+	return botCfg, nil
+}
@@ -0,0 +1,178 @@
+package bot
+
+import (
+	"context"
+	"errors"
+
+	"deepseek-trader/agent"
+	"deepseek-trader/hyperliquid"
+	"deepseek-trader/models"
+	"deepseek-trader/services"
+)
+
+// Exchange is the order-routing and account surface the bot executes decisions against:
+// the live hyperliquid.Client or a paper.Exchange.
+type Exchange interface {
+	PlaceOrder(ctx context.Context, req hyperliquid.OrderRequest) (hyperliquid.OrderResult, error)
+	PlaceTriggerOrder(ctx context.Context, req hyperliquid.TriggerOrderRequest) (hyperliquid.OrderResult, error)
+	CancelOrder(ctx context.Context, coin string, oid int64) error
+	CancelAll(ctx context.Context, coin string) (int, error)
+	QueryOrder(ctx context.Context, oid int64) (hyperliquid.OrderQuery, error)
+	QueryOrderByCloid(ctx context.Context, cloid string) (hyperliquid.OrderQuery, error)
+	GetLiveStats(ctx context.Context) (*hyperliquid.LiveStats, error)
+	HistoricalOrders(ctx context.Context) ([]hyperliquid.UserFill, error)
+	AccountState(ctx context.Context) (hyperliquid.AccountState, error)
+	OpenOrders(ctx context.Context) ([]hyperliquid.OpenOrder, error)
+	UpdateLeverage(ctx context.Context, coin string, leverage int, isolated bool) error
+	UpdateIsolatedMargin(ctx context.Context, coin string, amount float64) error
+}
+
+// execute turns a recorded decision into orders, persists the exchange outcome and marks the decision
+// as executed, rejected or failed; d.Reason (e.g. a risk clamp) is kept on success. Filled entries get
+// their exit legs immediately; resting limit entries are protected by syncOrders once they fill.
+// Close, reduce, reverse and adjust act on the position in snap (see position.go).
+func (s *Service) execute(ctx context.Context, d models.Decision, snap agent.Snapshot) {
+	switch d.Action {
+	case "buy", "sell":
+		s.enter(ctx, d, d.Action == "buy", snap.Position(hyperliquid.NormalizeSymbol(d.Symbol)), snap)
+	case "close", "reduce":
+		s.exit(ctx, d, snap)
+	case "reverse":
+		s.reverse(ctx, d, snap)
+	case "adjust":
+		s.adjust(ctx, d, snap)
+	default:
+		s.markDecision(ctx, d.ID, models.DecisionSkipped, "no action")
+	}
+}
+
+// enter places the entry of a decision on the given side, after applying the leverage policy to the
+// position held before it, and protects it once filled. It reports whether the exchange took the order.
+func (s *Service) enter(ctx context.Context, d models.Decision, isBuy bool, held float64, snap agent.Snapshot) bool {
+	req, reason := s.botCfg.Orders.request(d, isBuy, d.Size)
+	var margin float64
+	if reason == "" {
+		margin, reason = s.setLeverage(ctx, d, isBuy, held, snap)
+	}
+	if reason != "" {
+		s.markDecision(ctx, d.ID, models.DecisionRejected, reason)
+		return false
+	}
+
+	ord := s.submit(ctx, d, req, models.OrderKindEntry)
+	if ord.Status != models.OrderRejected && ord.Status != models.OrderFailed {
+		s.committed += margin // a pending order may have been taken
+	}
+	if !s.markPlaced(ctx, d, ord) {
+		return false
+	}
+	if ord.Status == models.OrderFilled {
+		if _, err := s.tradesSvc.Record(ctx, s.userID, d.Symbol, ord.Side, ord.FilledSize, ord.AvgPrice); err != nil {
+			s.log.Sugar().Errorw("failed to record trade", "error", err)
+		}
+		s.protect(ctx, d, isBuy, ord.FilledSize, snap.Meta)
+	}
+	return true
+}
+
+// submit stores an order ticket for the decision and sends it to the exchange under the cloid of the
+// decision's leg kind, so the order can be found after a restart and is never sent twice. The returned
+// order carries the outcome; an order that could not be stored, or was already sent, comes back failed.
+func (s *Service) submit(ctx context.Context, d models.Decision, req hyperliquid.OrderRequest, kind string) models.Order {
+	side := "sell"
+	if req.IsBuy {
+		side = "buy"
+	}
+	orderType := "limit"
+	if req.Market {
+		orderType = "market"
+	}
+
+	ord, err := s.ordersSvc.Create(ctx, models.Order{
+		UserID:     &s.userID,
+		DecisionID: &d.ID,
+		Symbol:     d.Symbol,
+		Side:       side,
+		OrderType:  orderType,
+		Kind:       kind,
+		Size:       req.Size,
+		Price:      req.Price,
+		Cloid:      hyperliquid.DecisionCloid(d.ID, kind),
+	})
+	if errors.Is(err, services.ErrOrderExists) {
+		s.log.Sugar().Warnw("order leg already submitted, not sending it again", "decision", d.ID, "kind", kind, "order", ord.ID)
+		return models.Order{Status: models.OrderFailed, Error: err.Error()}
+	}
+	if err != nil {
+		s.log.Sugar().Errorw("failed to store order", "decision", d.ID, "error", err)
+		return models.Order{Status: models.OrderFailed, Error: "failed to store order: " + err.Error()}
+	}
+
+	req.Cloid = ord.Cloid
+	res, err := s.ex.PlaceOrder(ctx, req)
+	s.applyResult(&ord, res, err)
+	if ord.Status == models.OrderPending {
+		s.resolve(ctx, &ord)
+	}
+	// An order still pending may yet turn up on the exchange; reconcile counts it once it is known lost.
+	if ord.Status == models.OrderFailed {
+		s.apiFailed(ctx, "order: "+ord.Error)
+	}
+	if _, uerr := s.ordersSvc.UpdateResult(ctx, ord); uerr != nil {
+		s.log.Sugar().Errorw("failed to update order", "order", ord.ID, "error", uerr)
+	}
+	return ord
+}
+
+// markPlaced marks the decision after its order was submitted and reports whether the exchange took it.
+// The decision of an order whose outcome is still unknown stays pending until reconcile settles it.
+func (s *Service) markPlaced(ctx context.Context, d models.Decision, ord models.Order) bool {
+	switch ord.Status {
+	case models.OrderPending:
+		s.log.Sugar().Warnw("order outcome unknown, left for reconcile", "decision", d.ID, "order", ord.ID)
+		return false
+	case models.OrderRejected:
+		s.markDecision(ctx, d.ID, models.DecisionRejected, ord.Error)
+		return false
+	case models.OrderFailed:
+		s.markDecision(ctx, d.ID, models.DecisionFailed, ord.Error)
+		return false
+	}
+	s.markDecision(ctx, d.ID, models.DecisionExecuted, d.Reason)
+	return true
+}
+
+// applyResult sets the order's status from the exchange's answer. An order that got no answer stays pending,
+// since the exchange may still have taken it; resolve and reconcile look it up by cloid.
+func (s *Service) applyResult(ord *models.Order, res hyperliquid.OrderResult, err error) {
+	if res.Oid != 0 {
+		oid := res.Oid
+		ord.ExchangeOID = &oid
+	}
+
+	switch {
+	case errors.Is(err, hyperliquid.ErrOrderRejected):
+		ord.Status = models.OrderRejected
+		ord.Error = res.Error
+	case errors.Is(err, hyperliquid.ErrOrderUnknown):
+		s.log.Sugar().Warnw("order outcome unknown", "order", ord.ID, "cloid", ord.Cloid, "error", err)
+		ord.Status = models.OrderPending
+		ord.Error = err.Error()
+	case err != nil:
+		s.log.Sugar().Errorw("failed to place order", "order", ord.ID, "error", err)
+		ord.Status = models.OrderFailed
+		ord.Error = err.Error()
+	case res.Status == hyperliquid.OrderStatusFilled:
+		ord.Status = models.OrderFilled
+		ord.FilledSize = res.FilledSize
+		ord.AvgPrice = res.AvgPrice
+	default:
+		ord.Status = models.OrderResting
+	}
+}
+
+func (s *Service) markDecision(ctx context.Context, id int64, status, reason string) {
+	if err := s.tradesSvc.MarkDecision(ctx, id, status, reason); err != nil {
+		s.log.Sugar().Errorw("failed to update decision status", "decision", id, "error", err)
+	}
+}
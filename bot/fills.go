@@ -0,0 +1,108 @@
+package bot
+
+import (
+	"context"
+	"sort"
+
+	"deepseek-trader/hyperliquid"
+	"deepseek-trader/models"
+)
+
+// fillLogSize is how many fills the log keeps, as many as a userFills request returns.
+const fillLogSize = 2000
+
+// fillBatch is one userFills message from the WebSocket.
+type fillBatch struct {
+	fills    []hyperliquid.UserFill
+	snapshot bool
+}
+
+// fillLog is the user's fills as streamed over the WebSocket, deduplicated by trade id. Only the loop
+// goroutine touches it. It stands in for polling userFills once the stream sent its first snapshot; the
+// stream sends one on every resubscribe, so fills made while it was disconnected are filled in.
+type fillLog struct {
+	live  bool
+	seen  map[int64]bool
+	fills []hyperliquid.UserFill
+}
+
+// add records the batch's fills that are not in the log yet and returns them.
+func (l *fillLog) add(b fillBatch) []hyperliquid.UserFill {
+	if l.seen == nil {
+		l.seen = make(map[int64]bool)
+	}
+	var added []hyperliquid.UserFill
+	for _, f := range b.fills {
+		if l.seen[f.Tid] {
+			continue
+		}
+		l.seen[f.Tid] = true
+		added = append(added, f)
+	}
+	l.fills = append(l.fills, added...)
+	sort.SliceStable(l.fills, func(i, j int) bool { return l.fills[i].Time < l.fills[j].Time })
+	if n := len(l.fills) - fillLogSize; n > 0 {
+		for _, f := range l.fills[:n] {
+			delete(l.seen, f.Tid)
+		}
+		l.fills = append([]hyperliquid.UserFill(nil), l.fills[n:]...)
+	}
+	if b.snapshot {
+		l.live = true
+	}
+	return added
+}
+
+// recent returns the logged fills newest first, like userFills.
+func (l *fillLog) recent() []hyperliquid.UserFill {
+	out := make([]hyperliquid.UserFill, len(l.fills))
+	for i, f := range l.fills {
+		out[len(out)-1-i] = f
+	}
+	return out
+}
+
+// pushFills hands streamed fills to the loop goroutine like pushOrderUpdates. A dropped batch is made up
+// for by the snapshot the stream sends when it resubscribes, and by the next tick's order sync.
+func (s *Service) pushFills(fills []hyperliquid.UserFill, snapshot bool) {
+	select {
+	case s.fillCh <- fillBatch{fills: fills, snapshot: snapshot}:
+	default:
+		s.log.Sugar().Warnw("fills dropped, loop busy", "count", len(fills))
+	}
+}
+
+// onFills logs streamed fills and syncs the resting orders they filled, which books their trades at once
+// instead of on the next tick. The first snapshot only seeds the log: it holds fills from before the start.
+func (s *Service) onFills(ctx context.Context, b fillBatch) {
+	live := s.fills.live
+	added := s.fills.add(b)
+	if !live {
+		return
+	}
+	synced := make(map[int64]bool)
+	for _, f := range added {
+		if synced[f.Oid] {
+			continue
+		}
+		synced[f.Oid] = true
+		o, err := s.ordersSvc.ByExchangeOID(ctx, s.userID, f.Oid)
+		if err != nil || o.Status != models.OrderResting {
+			continue
+		}
+		q, err := s.ex.QueryOrder(ctx, f.Oid)
+		if err != nil {
+			s.log.Sugar().Errorw("failed to query filled order", "order", o.ID, "error", err)
+			continue
+		}
+		s.syncOrder(ctx, o, q, s.meta)
+	}
+}
+
+// userFills returns the user's recent fills from the stream once it is live, and polls them otherwise.
+func (s *Service) userFills(ctx context.Context) ([]hyperliquid.UserFill, error) {
+	if s.fills.live {
+		return s.fills.recent(), nil
+	}
+	return s.ex.HistoricalOrders(ctx)
+}
@@ -0,0 +1,50 @@
+package bot
+
+import (
+	"testing"
+
+	"deepseek-trader/hyperliquid"
+)
+
+func TestFillLog(t *testing.T) {
+	fill := func(tid, at int64) hyperliquid.UserFill {
+		return hyperliquid.UserFill{Tid: tid, Oid: tid * 10, Time: at}
+	}
+	var l fillLog
+
+	if added := l.add(fillBatch{fills: []hyperliquid.UserFill{fill(2, 200), fill(1, 100)}, snapshot: true}); len(added) != 2 {
+		t.Fatalf("snapshot added %d fills, want 2", len(added))
+	}
+	if !l.live {
+		t.Fatal("the log must be live once the snapshot arrived")
+	}
+	// A resubscribe resends the snapshot with the fills missed in between.
+	added := l.add(fillBatch{fills: []hyperliquid.UserFill{fill(3, 300), fill(2, 200), fill(1, 100)}, snapshot: true})
+	if len(added) != 1 || added[0].Tid != 3 {
+		t.Fatalf("resent snapshot added %+v, want only tid 3", added)
+	}
+	l.add(fillBatch{fills: []hyperliquid.UserFill{fill(4, 250)}})
+
+	got := l.recent()
+	want := []int64{3, 4, 2, 1}
+	if len(got) != len(want) {
+		t.Fatalf("recent = %+v, want tids %v", got, want)
+	}
+	for i, f := range got {
+		if f.Tid != want[i] {
+			t.Errorf("recent[%d] = tid %d, want %d", i, f.Tid, want[i])
+		}
+	}
+}
+
+func TestFillLogKeepsTheNewest(t *testing.T) {
+	var l fillLog
+	fills := make([]hyperliquid.UserFill, fillLogSize+5)
+	for i := range fills {
+		fills[i] = hyperliquid.UserFill{Tid: int64(i + 1), Time: int64(i + 1)}
+	}
+	l.add(fillBatch{fills: fills, snapshot: true})
+	if len(l.fills) != fillLogSize || l.fills[0].Tid != 6 {
+		t.Fatalf("kept %d fills from tid %d, want %d from tid 6", len(l.fills), l.fills[0].Tid, fillLogSize)
+	}
+}
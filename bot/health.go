@@ -0,0 +1,101 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"deepseek-trader/agent"
+	"deepseek-trader/hyperliquid"
+	"deepseek-trader/models"
+)
+
+// HealthConfig configures the margin-health monitor. Distances are the move from the mark to the
+// liquidation price as a fraction of the mark; CheckEvery zero turns the monitor off.
+type HealthConfig struct {
+	WarnDistance   float64 `json:"warnDistance"`
+	DangerDistance float64 `json:"dangerDistance"`
+	// AutoReduce closes ReduceFraction of a position at danger level on every check until it recovers.
+	AutoReduce     bool          `json:"autoReduce"`
+	ReduceFraction float64       `json:"reduceFraction"`
+	CheckEvery     time.Duration `json:"checkEvery"`
+}
+
+// health computes the margin health of an account state with the bot's thresholds. Only the exchange's
+// liquidation prices count, so AutoReduce never acts on an estimate or on a paper position.
+func (c HealthConfig) health(meta hyperliquid.ExchangeMeta, st hyperliquid.AccountState) hyperliquid.MarginHealth {
+	return meta.HealthOf(st, c.WarnDistance, c.DangerDistance, false)
+}
+
+// checkHealth is one run of the margin-health monitor: it logs positions whose level changed since the
+// last run and, with AutoReduce, reduces positions at danger level.
+func (s *Service) checkHealth(ctx context.Context) {
+	if s.tripped {
+		return
+	}
+	st, err := s.ex.AccountState(ctx)
+	if err != nil {
+		s.log.Sugar().Errorw("failed to get account state", "error", err)
+		return
+	}
+	meta := s.meta
+	if len(meta.Universe) == 0 {
+		if meta, err = s.hl.Meta(ctx); err != nil {
+			s.log.Sugar().Errorw("failed to get meta", "error", err)
+			return
+		}
+		s.meta = meta
+	}
+
+	h := s.botCfg.Health.health(meta, st)
+	levels := make(map[string]string, len(h.Positions))
+	for _, p := range h.Positions {
+		levels[p.Coin] = p.Level
+		prev := s.levels[p.Coin]
+		switch {
+		case p.Level == prev || (prev == "" && p.Level == hyperliquid.HealthOK):
+		case p.Level == hyperliquid.HealthOK:
+			s.log.Sugar().Infow("margin health recovered", "coin", p.Coin, "distance", p.Distance)
+		default:
+			s.log.Sugar().Warnw("margin health "+p.Level, "coin", p.Coin, "distance", p.Distance,
+				"mark", p.MarkPrice, "liquidation", p.LiquidationPrice, "marginRatio", h.MarginRatio)
+		}
+		if p.Level == hyperliquid.HealthDanger && s.botCfg.Health.AutoReduce {
+			s.autoReduce(ctx, p, st, meta)
+		}
+	}
+	s.levels = levels
+	s.checkBreaker(ctx, st.AccountValue, st.Positions)
+}
+
+// autoReduce closes ReduceFraction of a position at danger level, or all of it when that rounds to
+// nothing, through a recorded reduce decision.
+func (s *Service) autoReduce(ctx context.Context, p hyperliquid.PositionHealth, st hyperliquid.AccountState, meta hyperliquid.ExchangeMeta) {
+	decimals := 0
+	if in, ok := meta.Instrument(p.Coin); ok {
+		decimals = in.SzDecimals
+	}
+	fraction := s.botCfg.Health.ReduceFraction
+	size := hyperliquid.FloorSize(math.Abs(p.Size)*fraction, decimals)
+	if size <= 0 || fraction <= 0 || fraction >= 1 {
+		size, fraction = math.Abs(p.Size), 1
+	}
+
+	d, err := s.tradesSvc.RecordDecision(ctx, models.Decision{
+		UserID:    &s.userID,
+		Action:    "reduce",
+		Symbol:    p.Coin + "USDT",
+		Size:      size,
+		OrderType: "market",
+		Fraction:  fraction,
+		Rationale: fmt.Sprintf("auto-reduce: mark %v is %.2f%% from the liquidation price %v",
+			p.MarkPrice, p.Distance*100, p.LiquidationPrice),
+	})
+	if err != nil {
+		s.log.Sugar().Errorw("failed to record auto-reduce decision", "coin", p.Coin, "error", err)
+		return
+	}
+	s.log.Sugar().Warnw("auto-reducing position", "coin", p.Coin, "size", size, "decision", d.ID)
+	s.exit(ctx, d, agent.Snapshot{Positions: st.Positions, Meta: meta})
+}
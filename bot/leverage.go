@@ -0,0 +1,112 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"deepseek-trader/agent"
+	"deepseek-trader/hyperliquid"
+	"deepseek-trader/models"
+)
+
+// LeveragePolicy is the leverage a bot sets on a coin before it opens a position there. PerCoin overrides
+// Default by coin name and zero leaves the account's setting alone. Leverage is capped at the margin tier of
+// the resulting position, and isolated-only coins always use isolated margin.
+type LeveragePolicy struct {
+	Default  int            `json:"default"`
+	Isolated bool           `json:"isolated"`
+	PerCoin  map[string]int `json:"perCoin,omitempty"`
+}
+
+// For returns the policy's leverage for coin.
+func (p LeveragePolicy) For(coin string) int {
+	if lev, ok := p.PerCoin[hyperliquid.NormalizeSymbol(coin)]; ok {
+		return lev
+	}
+	return p.Default
+}
+
+// ParseLeverages parses per-coin leverage overrides given as coin:leverage pairs, e.g. "BTC:5,ETH:3".
+func ParseLeverages(s string) (map[string]int, error) {
+	out := make(map[string]int)
+	for _, pair := range strings.Split(s, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		coin, lev, ok := strings.Cut(pair, ":")
+		n, err := strconv.Atoi(strings.TrimSpace(lev))
+		if !ok || err != nil || n < 0 || strings.TrimSpace(coin) == "" {
+			return nil, fmt.Errorf("invalid leverage %q, want coin:leverage", pair)
+		}
+		out[hyperliquid.NormalizeSymbol(coin)] = n
+	}
+	return out, nil
+}
+
+// setLeverage applies the leverage policy before an entry of d.Size and checks the position it leads to
+// against the coin's margin tier and the account's free margin, less the margin committed to entries placed
+// earlier in the cycle. held is the signed position before the entry; an open position keeps its leverage
+// and mode. It returns the initial margin the entry adds and a rejection reason, empty when the entry may
+// be placed.
+func (s *Service) setLeverage(ctx context.Context, d models.Decision, isBuy bool, held float64, snap agent.Snapshot) (float64, string) {
+	coin := hyperliquid.NormalizeSymbol(d.Symbol)
+	lev := s.botCfg.Leverage.For(coin)
+	if lev <= 0 {
+		return 0, ""
+	}
+	in, ok := snap.Meta.Instrument(coin)
+	if !ok {
+		s.log.Sugar().Warnw("no margin tiers for coin, leverage not managed", "coin", coin)
+		return 0, ""
+	}
+	isolated := s.botCfg.Leverage.Isolated || in.IsolatedOnly()
+
+	px := d.LimitPrice
+	if px <= 0 {
+		px, _ = strconv.ParseFloat(snap.CoinsMids[coin], 64)
+	}
+	signed := d.Size
+	if !isBuy {
+		signed = -signed
+	}
+	notional := math.Abs(held+signed) * px
+
+	if p, ok := openPosition(snap, coin); ok && held != 0 && p.Leverage > 0 {
+		lev, isolated = p.Leverage, p.LeverageType == "isolated"
+	} else if maxLev, err := snap.Meta.MaxLeverageAt(coin, notional); err == nil && lev > maxLev {
+		s.log.Sugar().Infow("leverage capped by margin tier", "coin", coin, "policy", lev, "max", maxLev, "notional", notional)
+		lev = maxLev
+	}
+	chk, err := snap.Meta.CheckPosition(coin, notional, lev, isolated)
+	if err != nil {
+		return 0, err.Error()
+	}
+	added := (math.Abs(held+signed) - math.Abs(held)) * px / float64(chk.Leverage)
+	if free := snap.Balance - snap.MarginUsed - s.committed; added > free {
+		return 0, fmt.Sprintf("insufficient margin: need %.2f, free %.2f at %dx", added, free, chk.Leverage)
+	}
+
+	added = math.Max(added, 0) // an entry against the position frees margin only once it fills
+	if held != 0 {
+		return added, ""
+	}
+	if err := s.ex.UpdateLeverage(ctx, coin, chk.Leverage, chk.Isolated); err != nil {
+		s.log.Sugar().Errorw("failed to set leverage", "coin", coin, "leverage", chk.Leverage, "error", err)
+		return 0, "failed to set leverage: " + err.Error()
+	}
+	return added, ""
+}
+
+// openPosition returns the snapshot's open position in coin.
+func openPosition(snap agent.Snapshot, coin string) (hyperliquid.Position, bool) {
+	for _, p := range snap.Positions {
+		if p.Coin == coin && p.Size != 0 {
+			return p, true
+		}
+	}
+	return hyperliquid.Position{}, false
+}
@@ -0,0 +1,74 @@
+package bot
+
+import (
+	"context"
+	"math"
+	"strings"
+	"testing"
+
+	"deepseek-trader/agent"
+	"deepseek-trader/hyperliquid"
+	"deepseek-trader/models"
+
+	"go.uber.org/zap"
+)
+
+// leverageExchange records the leverage set per coin.
+type leverageExchange struct {
+	Exchange
+	set map[string]int
+}
+
+func (f *leverageExchange) UpdateLeverage(_ context.Context, coin string, leverage int, _ bool) error {
+	f.set[coin] = leverage
+	return nil
+}
+
+func TestSetLeverage(t *testing.T) {
+	snap := agent.Snapshot{
+		Balance:    1000,
+		MarginUsed: 200,
+		CoinsMids:  map[string]string{"BTC": "100", "ETH": "100"},
+		Meta:       hyperliquid.ExchangeMeta{Universe: []hyperliquid.Instrument{{Name: "BTC", MaxLeverage: 10}, {Name: "ETH", MaxLeverage: 3}}},
+		Positions:  []hyperliquid.Position{{Coin: "ETH", Size: 3, Leverage: 2, LeverageType: "cross"}},
+	}
+	tests := []struct {
+		name       string
+		symbol     string
+		size       float64
+		isBuy      bool
+		held       float64
+		committed  float64 // margin of entries placed earlier in the cycle
+		wantMargin float64
+		wantSet    int
+		wantReason string
+	}{
+		{name: "fits the free margin", symbol: "BTCUSDT", size: 20, isBuy: true, wantMargin: 400, wantSet: 5},
+		{name: "uses all of it", symbol: "BTCUSDT", size: 40, isBuy: true, wantMargin: 800, wantSet: 5},
+		{name: "over the free margin", symbol: "BTCUSDT", size: 41, isBuy: true, wantReason: "insufficient margin: need 820.00, free 800.00"},
+		{name: "earlier entries use up the margin", symbol: "BTCUSDT", size: 20, isBuy: true, committed: 500,
+			wantReason: "insufficient margin: need 400.00, free 300.00"},
+		{name: "fits beside earlier entries", symbol: "BTCUSDT", size: 10, isBuy: false, committed: 500, wantMargin: 200, wantSet: 5},
+		{name: "open position keeps its leverage", symbol: "ETHUSDT", size: 3, isBuy: true, held: 3, wantMargin: 150},
+		{name: "reducing side adds no margin", symbol: "ETHUSDT", size: 2, isBuy: false, held: 3, committed: 800},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ex := &leverageExchange{set: make(map[string]int)}
+			s := &Service{ex: ex, log: zap.NewNop(), committed: tt.committed}
+			s.botCfg.Leverage = LeveragePolicy{Default: 5}
+
+			d := models.Decision{Symbol: tt.symbol, Size: tt.size}
+			margin, reason := s.setLeverage(context.Background(), d, tt.isBuy, tt.held, snap)
+			if !strings.HasPrefix(reason, tt.wantReason) || (tt.wantReason == "") != (reason == "") {
+				t.Fatalf("reason = %q, want %q", reason, tt.wantReason)
+			}
+			if math.Abs(margin-tt.wantMargin) > 1e-9 {
+				t.Errorf("margin = %v, want %v", margin, tt.wantMargin)
+			}
+			if got := ex.set[hyperliquid.NormalizeSymbol(tt.symbol)]; got != tt.wantSet {
+				t.Errorf("leverage set = %d, want %d", got, tt.wantSet)
+			}
+		})
+	}
+}
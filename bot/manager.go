@@ -0,0 +1,500 @@
+package bot
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"deepseek-trader/config"
+	"deepseek-trader/hyperliquid"
+	"deepseek-trader/models"
+	"deepseek-trader/paper"
+	cryptoutil "deepseek-trader/pkg/crypto"
+	"deepseek-trader/repository"
+	"deepseek-trader/services"
+
+	"go.uber.org/zap"
+)
+
+// ErrNoWallet is returned when a user starts a bot without a connected wallet.
+var ErrNoWallet = errors.New("connect a wallet before starting the bot")
+
+// ErrNoAPIKey is returned when a live bot is started for a wallet connected without its agent-wallet key.
+var ErrNoAPIKey = errors.New("reconnect the wallet with its API key to trade live")
+
+// ErrBotStopped is returned for account changes that need the user's running bot to sign them.
+var ErrBotStopped = errors.New("start the bot to change leverage, margin or orders")
+
+// ErrMarginCheck wraps the reason a leverage change fails the coin's margin tier.
+var ErrMarginCheck = errors.New("margin check failed")
+
+// ErrTripped is returned when a bot stopped by the circuit breaker or kill switch is started before it is re-armed.
+var ErrTripped = errors.New("the bot was stopped by the circuit breaker, re-arm it before starting")
+
+// Status describes a user's bot.
+type Status struct {
+	UserID int64  `json:"userId"`
+	On     bool   `json:"on"`
+	Wallet string `json:"wallet,omitempty"`
+	Paper  bool   `json:"paper"`
+	Config Config `json:"config"`
+	// TrippedAt is set while the bot is stopped by the circuit breaker or kill switch.
+	TrippedAt  *time.Time `json:"trippedAt,omitempty"`
+	TripReason string     `json:"tripReason,omitempty"`
+}
+
+// Manager runs at most one bot per user. Every bot gets its own wallet-bound client and exchange,
+// so bots never share account state.
+type Manager struct {
+	mx   sync.Mutex
+	bots map[int64]*Service
+
+	hl        *hyperliquid.Client
+	wallets   *services.WalletService
+	botsSvc   *services.BotsService
+	tradesSvc *services.TradesService
+	ordersSvc *services.OrdersService
+	statsSvc  *services.StatsService
+	callsSvc  *services.AgentCallsService
+	prompts   *services.PromptsService
+	paperRepo *repository.PaperRepository
+	cfg       *config.Settings
+	log       *zap.Logger
+}
+
+func NewManager(
+	hl *hyperliquid.Client, wallets *services.WalletService, botsSvc *services.BotsService,
+	tradesSvc *services.TradesService, ordersSvc *services.OrdersService, statsSvc *services.StatsService,
+	callsSvc *services.AgentCallsService, prompts *services.PromptsService,
+	paperRepo *repository.PaperRepository, cfg *config.Settings, log *zap.Logger,
+) *Manager {
+	return &Manager{
+		bots:      make(map[int64]*Service),
+		hl:        hl,
+		wallets:   wallets,
+		botsSvc:   botsSvc,
+		tradesSvc: tradesSvc,
+		ordersSvc: ordersSvc,
+		statsSvc:  statsSvc,
+		callsSvc:  callsSvc,
+		prompts:   prompts,
+		paperRepo: paperRepo,
+		cfg:       cfg,
+		log:       log,
+	}
+}
+
+// Start starts the user's bot with its stored config, or the defaults on first start.
+// Starting a running bot is a no-op and a tripped bot is refused until it is re-armed.
+func (m *Manager) Start(ctx context.Context, userID int64) (Status, error) {
+	m.mx.Lock()
+	defer m.mx.Unlock()
+
+	if svc, ok := m.bots[userID]; ok && svc.IsOn() {
+		return m.status(userID, svc), nil
+	}
+	if b, _, err := m.botsSvc.Get(ctx, userID); err != nil {
+		return Status{}, err
+	} else if b.TrippedAt != nil {
+		return Status{}, fmt.Errorf("%w: %s", ErrTripped, b.TripReason)
+	}
+
+	w, err := m.wallets.FindLatestByUser(ctx, userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return Status{}, ErrNoWallet
+	}
+	if err != nil {
+		return Status{}, err
+	}
+	botCfg, err := m.config(ctx, userID)
+	if err != nil {
+		return Status{}, err
+	}
+
+	client, err := m.client(ctx, w)
+	if err != nil {
+		return Status{}, err
+	}
+	var ex Exchange = client
+	if m.cfg.PaperTrading {
+		ex = m.paperExchange(client, userID)
+	}
+
+	svc, err := NewService(userID, client, ex, m.tradesSvc, m.ordersSvc, m.statsSvc, m.callsSvc, m.prompts, m.cfg, botCfg, m.log.With(zap.Int64("user", userID)))
+	if err != nil {
+		client.Close()
+		return Status{}, err
+	}
+	if err := m.save(ctx, userID, botCfg, true); err != nil {
+		client.Close()
+		return Status{}, err
+	}
+
+	svc.onTrip = func(reason string) { m.tripped(userID, svc, reason) }
+	svc.Start()
+	m.bots[userID] = svc
+	return m.status(userID, svc), nil
+}
+
+// Stop stops the user's bot and keeps it stopped across restarts.
+func (m *Manager) Stop(ctx context.Context, userID int64) error {
+	m.mx.Lock()
+	defer m.mx.Unlock()
+
+	botCfg, err := m.config(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if svc, ok := m.bots[userID]; ok {
+		m.stop(svc)
+		delete(m.bots, userID)
+	}
+	return m.save(ctx, userID, botCfg, false)
+}
+
+// Status reports the user's bot, running or not.
+func (m *Manager) Status(ctx context.Context, userID int64) (Status, error) {
+	m.mx.Lock()
+	defer m.mx.Unlock()
+
+	if svc, ok := m.bots[userID]; ok {
+		return m.status(userID, svc), nil
+	}
+	botCfg, err := m.config(ctx, userID)
+	if err != nil {
+		return Status{}, err
+	}
+	st := Status{UserID: userID, Paper: m.cfg.PaperTrading, Config: botCfg}
+	if b, ok, err := m.botsSvc.Get(ctx, userID); err == nil && ok {
+		st.TrippedAt, st.TripReason = b.TrippedAt, b.TripReason
+	}
+	if w, err := m.wallets.FindLatestByUser(ctx, userID); err == nil {
+		st.Wallet = w.Address
+	}
+	return st, nil
+}
+
+// Kill is the kill switch: it stops the user's bot, cancels every open order and, when asked, closes every
+// position at market. The bot stays stopped, across restarts too, until it is re-armed. A stopped bot is
+// killed with a client built from the user's wallet, so it also clears orders left behind by an earlier run.
+// The trip is recorded before the lock is released, so Start refuses the bot while it is being halted.
+func (m *Manager) Kill(ctx context.Context, userID int64, req KillRequest) (HaltReport, error) {
+	reason := "kill switch"
+	if req.Reason != "" {
+		reason += ": " + req.Reason
+	}
+
+	m.mx.Lock()
+	svc, running := m.bots[userID]
+	delete(m.bots, userID)
+	_, tripErr := m.botsSvc.Trip(ctx, userID, reason)
+	m.mx.Unlock()
+	if tripErr != nil {
+		tripErr = fmt.Errorf("failed to record the kill: %w", tripErr)
+	}
+
+	// fail returns an error that stopped the halt, or the failed trip record when there is one: a bot that
+	// is not marked tripped can be started again, which the caller must hear about before anything else.
+	fail := func(err error) (HaltReport, error) {
+		if tripErr == nil {
+			return HaltReport{}, err
+		}
+		m.log.Sugar().Errorw("failed to halt killed bot", "user", userID, "error", err)
+		return HaltReport{Reason: reason}, tripErr
+	}
+
+	var (
+		ex     Exchange
+		client *hyperliquid.Client
+	)
+	if running {
+		svc.Stop()
+		ex, client = svc.ex, svc.hl
+	} else {
+		w, err := m.wallets.FindLatestByUser(ctx, userID)
+		if errors.Is(err, sql.ErrNoRows) {
+			return fail(ErrNoWallet)
+		}
+		if err != nil {
+			return fail(err)
+		}
+		if client, err = m.client(ctx, w); err != nil {
+			return fail(err)
+		}
+		ex = client
+		if m.cfg.PaperTrading {
+			ex = m.paperExchange(client, userID)
+		}
+	}
+	defer client.Close()
+
+	rep := halt(ctx, ex, m.tradesSvc, userID, req.Flatten)
+	rep.Reason = reason
+	m.log.Sugar().Warnw("bot killed", "user", userID, "reason", reason, "canceled", rep.Canceled,
+		"flattened", rep.Flattened, "errors", rep.Errors)
+	return rep, tripErr
+}
+
+// Rearm clears the trip of the user's bot so it can be started again. It does not start it.
+func (m *Manager) Rearm(ctx context.Context, userID int64) error {
+	return m.botsSvc.Rearm(ctx, userID)
+}
+
+// tripped records the reason the breaker of a running bot tripped and stops the bot, unless it was already
+// stopped or replaced.
+func (m *Manager) tripped(userID int64, svc *Service, reason string) {
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+	if _, err := m.botsSvc.Trip(ctx, userID, reason); err != nil {
+		m.log.Sugar().Errorw("failed to record breaker trip", "user", userID, "error", err)
+	}
+
+	m.mx.Lock()
+	defer m.mx.Unlock()
+	if m.bots[userID] == svc {
+		m.stop(svc)
+		delete(m.bots, userID)
+	}
+}
+
+// Resume starts every bot that was enabled when the process last stopped.
+func (m *Manager) Resume(ctx context.Context) {
+	bots, err := m.botsSvc.Enabled(ctx)
+	if err != nil {
+		m.log.Sugar().Errorw("failed to list enabled bots", "error", err)
+		return
+	}
+	for _, b := range bots {
+		if _, err := m.Start(ctx, b.UserID); err != nil {
+			m.log.Sugar().Errorw("failed to resume bot", "user", b.UserID, "error", err)
+		}
+	}
+}
+
+// StopAll stops every running bot without changing whether it resumes on the next start.
+func (m *Manager) StopAll() {
+	m.mx.Lock()
+	defer m.mx.Unlock()
+
+	for id, svc := range m.bots {
+		m.stop(svc)
+		delete(m.bots, id)
+	}
+}
+
+// client builds the wallet-bound client a bot reads and trades with. Live bots sign with the wallet's
+// decrypted agent-wallet key, which lives only in that client until stop wipes it; paper bots never
+// place real orders, so their key stays encrypted.
+func (m *Manager) client(ctx context.Context, w models.Wallet) (*hyperliquid.Client, error) {
+	if m.cfg.PaperTrading {
+		return m.hl.ForWallet(w.Address), nil
+	}
+	if w.APIKey == "" {
+		return nil, ErrNoAPIKey
+	}
+	key, err := cryptoutil.Decrypt([]byte(m.cfg.SecretKey), w.APIKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt wallet key: %w", err)
+	}
+	return m.hl.WithSigner(ctx, w.Address, key)
+}
+
+// stop stops the bot and wipes its signing key once the calls the API is still making with it return.
+func (m *Manager) stop(svc *Service) {
+	svc.Stop()
+	svc.hl.Close()
+}
+
+// AccountState reports the user's margin summary and positions from the exchange their bot trades on.
+func (m *Manager) AccountState(ctx context.Context, userID int64) (hyperliquid.AccountState, error) {
+	ex, err := m.exchange(ctx, userID)
+	if err != nil {
+		return hyperliquid.AccountState{}, err
+	}
+	return ex.AccountState(ctx)
+}
+
+// OpenOrders reports the user's resting orders from the exchange their bot trades on.
+func (m *Manager) OpenOrders(ctx context.Context, userID int64) ([]hyperliquid.OpenOrder, error) {
+	ex, err := m.exchange(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return ex.OpenOrders(ctx)
+}
+
+// Health reports the margin health of the user's account, graded with their bot's thresholds.
+func (m *Manager) Health(ctx context.Context, userID int64) (hyperliquid.MarginHealth, error) {
+	ex, err := m.exchange(ctx, userID)
+	if err != nil {
+		return hyperliquid.MarginHealth{}, err
+	}
+	botCfg, err := m.config(ctx, userID)
+	if err != nil {
+		return hyperliquid.MarginHealth{}, err
+	}
+	meta, err := m.hl.Meta(ctx)
+	if err != nil {
+		return hyperliquid.MarginHealth{}, err
+	}
+	st, err := ex.AccountState(ctx)
+	if err != nil {
+		return hyperliquid.MarginHealth{}, err
+	}
+	return botCfg.Health.health(meta, st), nil
+}
+
+// LeverageRequest sets the leverage and margin mode of a coin.
+type LeverageRequest struct {
+	Coin     string `json:"coin"`
+	Leverage int    `json:"leverage"`
+	Isolated bool   `json:"isolated"`
+}
+
+// MarginRequest moves USD into an isolated position, or out of it when Amount is negative.
+type MarginRequest struct {
+	Coin   string  `json:"coin"`
+	Amount float64 `json:"amount"`
+}
+
+// SetLeverage checks leverage and margin mode against the margin tier of the user's position in coin and sets
+// them through the running bot.
+func (m *Manager) SetLeverage(ctx context.Context, userID int64, coin string, leverage int, isolated bool) (hyperliquid.MarginCheck, error) {
+	ex, err := m.running(userID)
+	if err != nil {
+		return hyperliquid.MarginCheck{}, err
+	}
+	meta, err := m.hl.Meta(ctx)
+	if err != nil {
+		return hyperliquid.MarginCheck{}, err
+	}
+	st, err := ex.AccountState(ctx)
+	if err != nil {
+		return hyperliquid.MarginCheck{}, err
+	}
+	notional := 0.0
+	for _, p := range st.Positions {
+		if p.Coin == hyperliquid.NormalizeSymbol(coin) {
+			notional = p.PositionValue
+		}
+	}
+	chk, err := meta.CheckPosition(coin, notional, leverage, isolated)
+	if err != nil {
+		return hyperliquid.MarginCheck{}, fmt.Errorf("%w: %v", ErrMarginCheck, err)
+	}
+	if err := ex.UpdateLeverage(ctx, chk.Coin, chk.Leverage, chk.Isolated); err != nil {
+		return hyperliquid.MarginCheck{}, stopped(err)
+	}
+	return chk, nil
+}
+
+// AdjustMargin adds amount USD to the user's isolated position in coin, or removes it when negative, through
+// the running bot.
+func (m *Manager) AdjustMargin(ctx context.Context, userID int64, coin string, amount float64) error {
+	ex, err := m.running(userID)
+	if err != nil {
+		return err
+	}
+	return stopped(ex.UpdateIsolatedMargin(ctx, coin, amount))
+}
+
+// CancelRequest cancels one resting order by oid.
+type CancelRequest struct {
+	Coin string `json:"coin"`
+	Oid  int64  `json:"oid"`
+}
+
+// CancelOrder cancels one of the user's resting orders through the running bot, which picks the cancel up
+// on its next order sync.
+func (m *Manager) CancelOrder(ctx context.Context, userID int64, coin string, oid int64) error {
+	ex, err := m.running(userID)
+	if err != nil {
+		return err
+	}
+	return stopped(ex.CancelOrder(ctx, coin, oid))
+}
+
+// CancelAll cancels every open order of the user on coin, or on every coin when coin is empty, through the
+// running bot and returns how many were canceled.
+func (m *Manager) CancelAll(ctx context.Context, userID int64, coin string) (int, error) {
+	ex, err := m.running(userID)
+	if err != nil {
+		return 0, err
+	}
+	n, err := ex.CancelAll(ctx, coin)
+	return n, stopped(err)
+}
+
+// running returns the exchange of the user's running bot, the only one holding their signing key.
+func (m *Manager) running(userID int64) (Exchange, error) {
+	m.mx.Lock()
+	defer m.mx.Unlock()
+	svc, ok := m.bots[userID]
+	if !ok || !svc.IsOn() {
+		return nil, ErrBotStopped
+	}
+	return svc.ex, nil
+}
+
+// stopped reports a signed call that lost the race with the bot stopping as ErrBotStopped. The call itself is
+// safe: stop wipes the key only once calls in flight have returned, and later ones fail with ErrNoSigner.
+func stopped(err error) error {
+	if errors.Is(err, hyperliquid.ErrNoSigner) {
+		return ErrBotStopped
+	}
+	return err
+}
+
+// exchange returns the running bot's exchange, or a read-only one for the user's wallet when the bot is stopped.
+func (m *Manager) exchange(ctx context.Context, userID int64) (Exchange, error) {
+	m.mx.Lock()
+	svc, ok := m.bots[userID]
+	m.mx.Unlock()
+	if ok {
+		return svc.ex, nil
+	}
+
+	w, err := m.wallets.FindLatestByUser(ctx, userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNoWallet
+	}
+	if err != nil {
+		return nil, err
+	}
+	client := m.hl.ForWallet(w.Address)
+	if m.cfg.PaperTrading {
+		return m.paperExchange(client, userID), nil
+	}
+	return client, nil
+}
+
+func (m *Manager) paperExchange(client *hyperliquid.Client, userID int64) *paper.Exchange {
+	return paper.NewExchange(client, m.paperRepo, fmt.Sprintf("user-%d", userID), m.cfg.PaperBalance, m.cfg.FeeRate, m.cfg.PaperLeverage)
+}
+
+func (m *Manager) status(userID int64, svc *Service) Status {
+	return Status{
+		UserID: userID,
+		On:     svc.IsOn(),
+		Wallet: svc.hl.WalletAddress(),
+		Paper:  m.cfg.PaperTrading,
+		Config: svc.botCfg,
+	}
+}
+
+func (m *Manager) config(ctx context.Context, userID int64) (Config, error) {
+	return LoadConfig(ctx, m.botsSvc, m.cfg, userID)
+}
+
+func (m *Manager) save(ctx context.Context, userID int64, botCfg Config, enabled bool) error {
+	raw, err := json.Marshal(botCfg)
+	if err != nil {
+		return err
+	}
+	_, err = m.botsSvc.Save(ctx, userID, raw, enabled)
+	return err
+}
@@ -0,0 +1,235 @@
+package bot
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"deepseek-trader/hyperliquid"
+	"deepseek-trader/models"
+)
+
+// OrderPolicy sets how decisions become orders. Market orders fill within Slippage of the mid, limit
+// orders use LimitTif, and resting limit orders are canceled once they are older than TTL (zero keeps them).
+type OrderPolicy struct {
+	Slippage float64       `json:"slippage"`
+	LimitTif string        `json:"limitTif"`
+	TTL      time.Duration `json:"ttl"`
+}
+
+// request builds the order ticket for a decision's side and size, or returns a rejection reason.
+func (p OrderPolicy) request(d models.Decision, isBuy bool, size float64) (hyperliquid.OrderRequest, string) {
+	if size <= 0 {
+		return hyperliquid.OrderRequest{}, "size must be positive"
+	}
+
+	req := hyperliquid.OrderRequest{
+		Coin:   d.Symbol,
+		IsBuy:  isBuy,
+		Size:   size,
+		Market: !strings.EqualFold(d.OrderType, "limit"),
+	}
+	if req.Market {
+		req.Slippage = p.Slippage
+		return req, ""
+	}
+	if d.LimitPrice <= 0 {
+		return hyperliquid.OrderRequest{}, "limit order without limitPrice"
+	}
+	req.Price = d.LimitPrice
+	req.Tif = p.LimitTif
+
+	return req, ""
+}
+
+// stale reports whether o is a resting limit order past the policy's TTL.
+func (p OrderPolicy) stale(o models.Order, now time.Time) bool {
+	return p.TTL > 0 && o.OrderType == "limit" && o.Status == models.OrderResting && now.Sub(o.CreatedAt) >= p.TTL
+}
+
+// cancelStale cancels a stale resting order and returns its state after the cancel, so a partial fill is
+// still accounted for. ok is false when the order could not be canceled or queried.
+func (s *Service) cancelStale(ctx context.Context, o models.Order) (hyperliquid.OrderQuery, bool) {
+	if err := s.ex.CancelOrder(ctx, o.Symbol, *o.ExchangeOID); err != nil {
+		s.log.Sugar().Errorw("failed to cancel stale order", "order", o.ID, "error", err)
+		return hyperliquid.OrderQuery{}, false
+	}
+	q, err := s.ex.QueryOrder(ctx, *o.ExchangeOID)
+	if err != nil {
+		s.log.Sugar().Errorw("failed to query order", "order", o.ID, "error", err)
+		return hyperliquid.OrderQuery{}, false
+	}
+	s.log.Sugar().Infow("canceled stale limit order", "order", o.ID, "symbol", o.Symbol, "age", time.Since(o.CreatedAt).Round(time.Second))
+	return q, true
+}
+
+// resolve looks up an order whose submission got no answer by its cloid and takes the outcome from the
+// exchange, mirroring how a direct answer is applied. When the lookup fails too the order stays pending
+// for reconcile to settle on the next tick.
+func (s *Service) resolve(ctx context.Context, o *models.Order) {
+	q, err := s.ex.QueryOrderByCloid(ctx, o.Cloid)
+	if err != nil {
+		s.log.Sugar().Errorw("failed to query order by cloid", "order", o.ID, "cloid", o.Cloid, "error", err)
+		return
+	}
+	if q.Status == "unknownOid" {
+		o.Status = models.OrderFailed
+		o.Error = "not received by the exchange: " + o.Error
+		return
+	}
+
+	oid := q.Order.Oid
+	o.ExchangeOID = &oid
+	o.Error = ""
+	switch q.Status {
+	case "open":
+		o.Status = models.OrderResting
+	case "filled", "triggered":
+		o.Status = models.OrderFilled
+		s.applyFills(ctx, o, q)
+	default:
+		s.applyFills(ctx, o, q)
+		o.Status = models.OrderFilled
+		if o.FilledSize <= 0 {
+			o.Status = models.OrderRejected
+			o.Error = q.Status
+		}
+	}
+	s.log.Sugar().Infow("resolved order by cloid", "order", o.ID, "cloid", o.Cloid, "oid", oid, "status", q.Status)
+}
+
+// applyFills sets the filled size and average price of an order the exchange reports done from the user's
+// fills of its oid: the order status only says the order traded, not at what price. When the fills cannot
+// be loaded or have not shown up yet, the order is booked at its limit or trigger price, the worst it could
+// have traded at.
+func (s *Service) applyFills(ctx context.Context, o *models.Order, q hyperliquid.OrderQuery) {
+	o.FilledSize = parseF(q.Order.OrigSz) - parseF(q.Order.Sz)
+	if q.Status == "filled" || q.Status == "triggered" {
+		o.FilledSize = o.Size
+	}
+	if o.FilledSize <= 0 {
+		return
+	}
+	if size, px, ok := s.fillsOf(ctx, q.Order.Oid); ok {
+		o.FilledSize, o.AvgPrice = size, px
+		return
+	}
+	s.log.Sugar().Warnw("no fills found for order, booking it at its limit price", "order", o.ID, "oid", q.Order.Oid)
+	o.AvgPrice = parseF(q.Order.LimitPx)
+	if o.OrderType == "trigger" {
+		o.AvgPrice = o.TriggerPx
+	}
+}
+
+// fillsOf returns the total size and size-weighted price of the user's fills of an order. The stream may
+// report an order done before its fills, so the fills are polled when the stream has none of them yet.
+func (s *Service) fillsOf(ctx context.Context, oid int64) (size, px float64, ok bool) {
+	fills, err := s.userFills(ctx)
+	if err == nil && s.fills.live && !hasOid(fills, oid) {
+		fills, err = s.ex.HistoricalOrders(ctx)
+	}
+	if err != nil {
+		s.log.Sugar().Errorw("failed to load fills", "oid", oid, "error", err)
+		return 0, 0, false
+	}
+	notional := 0.0
+	for _, f := range fills {
+		if f.Oid == oid {
+			sz := parseF(f.Sz)
+			size += sz
+			notional += sz * parseF(f.Px)
+		}
+	}
+	if size <= 0 {
+		return 0, 0, false
+	}
+	return size, notional / size, true
+}
+
+func hasOid(fills []hyperliquid.UserFill, oid int64) bool {
+	for _, f := range fills {
+		if f.Oid == oid {
+			return true
+		}
+	}
+	return false
+}
+
+// reconcile settles the orders stored without an outcome: ones a previous run stopped in the middle of
+// submitting, and ones whose submission got no answer and that resolve could not look up. It runs at start
+// and on every tick. Each is looked up by its cloid instead of being sent again: an order the exchange has
+// is synced like any other, one it never received is marked failed, and so is its decision if that was left
+// pending.
+func (s *Service) reconcile(ctx context.Context) {
+	pending, err := s.ordersSvc.Pending(ctx, s.userID)
+	if err != nil {
+		s.log.Sugar().Errorw("failed to list pending orders", "error", err)
+		return
+	}
+	if len(pending) == 0 {
+		return
+	}
+	meta, err := s.hl.Meta(ctx)
+	if err != nil {
+		s.log.Sugar().Errorw("failed to get meta", "error", err)
+		return
+	}
+	s.meta = meta
+
+	for _, o := range pending {
+		q, err := s.ex.QueryOrderByCloid(ctx, o.Cloid)
+		if err != nil {
+			s.log.Sugar().Errorw("failed to query order by cloid", "order", o.ID, "cloid", o.Cloid, "error", err)
+			continue
+		}
+		if q.Status == "unknownOid" {
+			o.Status = models.OrderFailed
+			o.Error = "never reached the exchange, not resubmitted"
+			if _, err := s.ordersSvc.UpdateResult(ctx, o); err != nil {
+				s.log.Sugar().Errorw("failed to update order", "order", o.ID, "error", err)
+			}
+			s.settleDecision(ctx, o, models.DecisionFailed, o.Error)
+			s.log.Sugar().Warnw("pending order not found on the exchange", "order", o.ID, "cloid", o.Cloid)
+			s.apiFailed(ctx, "order lost: "+o.Cloid)
+			continue
+		}
+
+		oid := q.Order.Oid
+		o.ExchangeOID = &oid
+		o.Status = models.OrderResting
+		o.Error = ""
+		s.settleDecision(ctx, o, models.DecisionExecuted, "")
+		s.log.Sugar().Infow("reconciled pending order", "order", o.ID, "cloid", o.Cloid, "oid", oid, "status", q.Status)
+		if q.Status == "open" {
+			if _, err := s.ordersSvc.UpdateResult(ctx, o); err != nil {
+				s.log.Sugar().Errorw("failed to update order", "order", o.ID, "error", err)
+			}
+			continue
+		}
+		s.syncOrder(ctx, o, q, meta)
+	}
+}
+
+// settleDecision marks the decision of a reconciled order when it is still pending; an empty reason keeps
+// the decision's own.
+func (s *Service) settleDecision(ctx context.Context, o models.Order, status, reason string) {
+	if o.DecisionID == nil {
+		return
+	}
+	d, err := s.tradesSvc.Decision(ctx, *o.DecisionID)
+	if err != nil {
+		s.log.Sugar().Errorw("failed to load decision", "decision", *o.DecisionID, "error", err)
+		return
+	}
+	if d.Status != models.DecisionPending {
+		return
+	}
+	if reason == "" {
+		reason = d.Reason
+	}
+	if status == models.DecisionExecuted && d.Action == "reverse" && o.Kind == models.OrderKindExit {
+		// The entry of a reverse follows a filled close in the same pass; a close settled later stands alone.
+		status, reason = models.DecisionPartial, "close placed, reverse entry not placed"
+	}
+	s.markDecision(ctx, d.ID, status, reason)
+}
@@ -0,0 +1,83 @@
+package bot
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"deepseek-trader/hyperliquid"
+	"deepseek-trader/models"
+
+	"go.uber.org/zap"
+)
+
+// fakeExchange serves the user's fills; the other calls are not used by the tests that embed it.
+type fakeExchange struct {
+	Exchange
+	fills []hyperliquid.UserFill
+	polls int
+}
+
+func (f *fakeExchange) HistoricalOrders(context.Context) ([]hyperliquid.UserFill, error) {
+	f.polls++
+	return f.fills, nil
+}
+
+func TestApplyFills(t *testing.T) {
+	fills := []hyperliquid.UserFill{
+		{Oid: 1, Px: "100", Sz: "0.3"},
+		{Oid: 1, Px: "101", Sz: "0.1"},
+		{Oid: 2, Px: "250", Sz: "1"},
+		{Oid: 3, Px: "99", Sz: "0.2"},
+	}
+	query := func(oid int64, status, origSz, sz string) hyperliquid.OrderQuery {
+		q := hyperliquid.OrderQuery{Status: status}
+		q.Order.Oid, q.Order.OrigSz, q.Order.Sz, q.Order.LimitPx = oid, origSz, sz, "105"
+		return q
+	}
+	tests := []struct {
+		name      string
+		order     models.Order
+		q         hyperliquid.OrderQuery
+		wantSize  float64
+		wantPrice float64
+	}{
+		{name: "market order at its fills", order: models.Order{Size: 0.4, OrderType: "market"},
+			q: query(1, "filled", "0.4", "0"), wantSize: 0.4, wantPrice: 100.25},
+		{name: "partly filled then canceled", order: models.Order{Size: 0.5, OrderType: "limit"},
+			q: query(3, "canceled", "0.5", "0.3"), wantSize: 0.2, wantPrice: 99},
+		{name: "canceled unfilled", order: models.Order{Size: 0.5, OrderType: "limit"},
+			q: query(4, "canceled", "0.5", "0.5"), wantSize: 0, wantPrice: 0},
+		{name: "no fills yet: limit price", order: models.Order{Size: 0.4, OrderType: "market"},
+			q: query(5, "filled", "0.4", "0"), wantSize: 0.4, wantPrice: 105},
+		{name: "no fills yet: trigger price", order: models.Order{Size: 0.4, OrderType: "trigger", TriggerPx: 90},
+			q: query(6, "triggered", "0.4", "0"), wantSize: 0.4, wantPrice: 90},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &Service{ex: &fakeExchange{fills: fills}, log: zap.NewNop()}
+			o := tt.order
+			s.applyFills(context.Background(), &o, tt.q)
+			if math.Abs(o.FilledSize-tt.wantSize) > 1e-9 || math.Abs(o.AvgPrice-tt.wantPrice) > 1e-9 {
+				t.Fatalf("filled %v @ %v, want %v @ %v", o.FilledSize, o.AvgPrice, tt.wantSize, tt.wantPrice)
+			}
+		})
+	}
+}
+
+func TestFillsOfPollsWhenTheStreamLags(t *testing.T) {
+	ex := &fakeExchange{fills: []hyperliquid.UserFill{{Oid: 9, Px: "50", Sz: "2"}}}
+	s := &Service{ex: ex, log: zap.NewNop()}
+	s.fills.add(fillBatch{fills: []hyperliquid.UserFill{{Oid: 8, Tid: 1, Px: "10", Sz: "1"}}, snapshot: true})
+
+	size, px, ok := s.fillsOf(context.Background(), 9)
+	if !ok || size != 2 || px != 50 {
+		t.Fatalf("fillsOf = %v, %v, %v, want 2, 50, true", size, px, ok)
+	}
+	if ex.polls != 1 {
+		t.Errorf("polled %d times, want 1", ex.polls)
+	}
+	if _, _, ok := s.fillsOf(context.Background(), 8); !ok || ex.polls != 1 {
+		t.Errorf("a streamed fill must not be polled, polls = %d", ex.polls)
+	}
+}
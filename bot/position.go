@@ -0,0 +1,147 @@
+package bot
+
+import (
+	"context"
+	"strings"
+
+	"deepseek-trader/agent"
+	"deepseek-trader/exits"
+	"deepseek-trader/hyperliquid"
+	"deepseek-trader/models"
+)
+
+// exit closes or reduces the position with a reduce-only order of the decision's size on the other
+// side. Once a close fills, the position's remaining exit legs are cancelled.
+func (s *Service) exit(ctx context.Context, d models.Decision, snap agent.Snapshot) {
+	coin := hyperliquid.NormalizeSymbol(d.Symbol)
+	pos := snap.Position(coin)
+	if pos == 0 {
+		s.markDecision(ctx, d.ID, models.DecisionRejected, "no open "+coin+" position")
+		return
+	}
+
+	req, reason := s.botCfg.Orders.request(d, pos < 0, exits.CloseSize(d, pos))
+	if reason != "" {
+		s.markDecision(ctx, d.ID, models.DecisionRejected, reason)
+		return
+	}
+	req.ReduceOnly = true
+
+	ord := s.submit(ctx, d, req, models.OrderKindExit)
+	if !s.markPlaced(ctx, d, ord) || ord.Status != models.OrderFilled {
+		return
+	}
+	if _, err := s.tradesSvc.Record(ctx, s.userID, d.Symbol, ord.Side, ord.FilledSize, ord.AvgPrice); err != nil {
+		s.log.Sugar().Errorw("failed to record trade", "error", err)
+	}
+	if d.Action == "close" {
+		s.cancelCoinExits(ctx, coin)
+	}
+}
+
+// reverse closes the position with a reduce-only order and, once the close has filled, opens the decision's
+// size on the other side, protected by the decision's targets. A close that rests leaves the decision
+// partial: the entry is not placed on top of a position that is still open.
+func (s *Service) reverse(ctx context.Context, d models.Decision, snap agent.Snapshot) {
+	coin := hyperliquid.NormalizeSymbol(d.Symbol)
+	pos := snap.Position(coin)
+	if pos == 0 {
+		s.markDecision(ctx, d.ID, models.DecisionRejected, "no open "+coin+" position")
+		return
+	}
+
+	closeReq, reason := s.botCfg.Orders.request(d, pos < 0, exits.CloseSize(d, pos))
+	if reason == "" {
+		_, reason = s.botCfg.Orders.request(d, pos < 0, d.Size)
+	}
+	if reason != "" {
+		s.markDecision(ctx, d.ID, models.DecisionRejected, reason)
+		return
+	}
+	closeReq.ReduceOnly = true
+
+	ord := s.submit(ctx, d, closeReq, models.OrderKindExit)
+	switch ord.Status {
+	case models.OrderFilled:
+	case models.OrderResting:
+		s.markDecision(ctx, d.ID, models.DecisionPartial, "close is resting, reverse entry not placed")
+		return
+	default:
+		s.markPlaced(ctx, d, ord)
+		return
+	}
+	if _, err := s.tradesSvc.Record(ctx, s.userID, d.Symbol, ord.Side, ord.FilledSize, ord.AvgPrice); err != nil {
+		s.log.Sugar().Errorw("failed to record trade", "error", err)
+	}
+	s.cancelCoinExits(ctx, coin)
+
+	if !s.enter(ctx, d, pos < 0, exits.Remaining(pos, ord.FilledSize), snap) {
+		s.log.Sugar().Warnw("position closed but the reverse entry was not placed", "decision", d.ID, "coin", coin)
+	}
+}
+
+// adjust replaces the position's exit legs of every target set in the decision. A new leg takes the
+// size of the resting legs it replaces, or its tier's share of the position when there are none; a
+// kind whose resting legs could not be cancelled keeps them.
+func (s *Service) adjust(ctx context.Context, d models.Decision, snap agent.Snapshot) {
+	coin := hyperliquid.NormalizeSymbol(d.Symbol)
+	pos := snap.Position(coin)
+	if pos == 0 {
+		s.markDecision(ctx, d.ID, models.DecisionRejected, "no open "+coin+" position")
+		return
+	}
+	active, err := s.ordersSvc.Active(ctx, s.userID)
+	if err != nil {
+		s.markDecision(ctx, d.ID, models.DecisionFailed, "failed to list active orders: "+err.Error())
+		return
+	}
+	decimals := 0
+	if in, ok := snap.Meta.Instrument(coin); ok {
+		decimals = in.SzDecimals
+	}
+
+	prices := exits.Prices(d)
+	placed := 0
+	var problems []string
+	for _, kind := range exits.Kinds {
+		if prices[kind] <= 0 {
+			continue
+		}
+		size, ok := s.cancelLegs(ctx, active, coin, kind)
+		if !ok {
+			problems = append(problems, kind+": failed to cancel the resting leg")
+			continue
+		}
+		leg := exits.Leg{Kind: kind, Price: prices[kind], Size: exits.AdjustSize(kind, size, pos, decimals)}
+		ord := s.placeExit(ctx, d, leg, pos > 0)
+		if ord.Status == models.OrderRejected || ord.Status == models.OrderFailed {
+			problems = append(problems, kind+": "+ord.Error)
+			continue
+		}
+		placed++
+	}
+
+	switch {
+	case placed == 0:
+		s.markDecision(ctx, d.ID, models.DecisionFailed, strings.Join(problems, "; "))
+	case len(problems) > 0:
+		s.markDecision(ctx, d.ID, models.DecisionExecuted, strings.Join(problems, "; "))
+	default:
+		s.markDecision(ctx, d.ID, models.DecisionExecuted, d.Reason)
+	}
+}
+
+// cancelLegs cancels the resting exit legs of one kind on a coin and returns their total size.
+func (s *Service) cancelLegs(ctx context.Context, active []models.Order, coin, kind string) (float64, bool) {
+	size := 0.0
+	for _, o := range active {
+		if o.Kind != kind || hyperliquid.NormalizeSymbol(o.Symbol) != coin {
+			continue
+		}
+		if !s.cancelOrder(ctx, o) {
+			return 0, false
+		}
+		size += o.Size
+	}
+	return size, true
+}
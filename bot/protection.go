@@ -0,0 +1,219 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"deepseek-trader/exits"
+	"deepseek-trader/hyperliquid"
+	"deepseek-trader/models"
+	"deepseek-trader/services"
+)
+
+// protect places reduce-only TP1/TP2/TP3 and SL trigger orders for a filled entry of the given side and size.
+func (s *Service) protect(ctx context.Context, d models.Decision, isLong bool, size float64, meta hyperliquid.ExchangeMeta) {
+	decimals := 0
+	if in, ok := meta.Instrument(d.Symbol); ok {
+		decimals = in.SzDecimals
+	}
+
+	for _, leg := range exits.Legs(d, size, decimals) {
+		s.placeExit(ctx, d, leg, isLong)
+	}
+}
+
+// placeExit stores and places one exit leg; the returned order carries the outcome.
+func (s *Service) placeExit(ctx context.Context, d models.Decision, leg exits.Leg, isLong bool) models.Order {
+	side := "sell"
+	if !isLong {
+		side = "buy"
+	}
+	tpsl := hyperliquid.TriggerTakeProfit
+	if leg.Kind == models.OrderKindSL {
+		tpsl = hyperliquid.TriggerStopLoss
+	}
+
+	ord, err := s.ordersSvc.Create(ctx, models.Order{
+		UserID:     &s.userID,
+		DecisionID: &d.ID,
+		Symbol:     d.Symbol,
+		Side:       side,
+		OrderType:  "trigger",
+		Kind:       leg.Kind,
+		Size:       leg.Size,
+		TriggerPx:  leg.Price,
+		Cloid:      hyperliquid.DecisionCloid(d.ID, leg.Kind),
+	})
+	if errors.Is(err, services.ErrOrderExists) {
+		s.log.Sugar().Warnw("exit leg already submitted, not sending it again", "decision", d.ID, "kind", leg.Kind, "order", ord.ID)
+		return models.Order{Kind: leg.Kind, Status: models.OrderFailed, Error: err.Error()}
+	}
+	if err != nil {
+		s.log.Sugar().Errorw("failed to store exit order", "decision", d.ID, "kind", leg.Kind, "error", err)
+		return models.Order{Kind: leg.Kind, Status: models.OrderFailed, Error: "failed to store order: " + err.Error()}
+	}
+
+	res, err := s.ex.PlaceTriggerOrder(ctx, hyperliquid.TriggerOrderRequest{
+		Coin:      d.Symbol,
+		IsBuy:     !isLong,
+		Size:      leg.Size,
+		TriggerPx: leg.Price,
+		Tpsl:      tpsl,
+		Cloid:     ord.Cloid,
+	})
+	s.applyResult(&ord, res, err)
+	if ord.Status == models.OrderPending {
+		s.resolve(ctx, &ord)
+	}
+	if _, err := s.ordersSvc.UpdateResult(ctx, ord); err != nil {
+		s.log.Sugar().Errorw("failed to update exit order", "order", ord.ID, "error", err)
+	}
+	return ord
+}
+
+// syncOrders polls resting orders, places exits for entries that filled since the last tick,
+// cancels limit orders that rested past the order TTL and cancels the remaining exit legs once the
+// stop fires or the position is closed.
+func (s *Service) syncOrders(ctx context.Context, meta hyperliquid.ExchangeMeta) {
+	active, err := s.ordersSvc.Active(ctx, s.userID)
+	if err != nil {
+		s.log.Sugar().Errorw("failed to list active orders", "error", err)
+		return
+	}
+
+	for _, o := range active {
+		if o.ExchangeOID == nil {
+			continue
+		}
+		q, err := s.ex.QueryOrder(ctx, *o.ExchangeOID)
+		if err != nil {
+			s.log.Sugar().Errorw("failed to query order", "order", o.ID, "error", err)
+			continue
+		}
+		if q.Status == "open" && s.botCfg.Orders.stale(o, time.Now()) {
+			var ok bool
+			if q, ok = s.cancelStale(ctx, o); !ok {
+				continue
+			}
+		}
+		s.syncOrder(ctx, o, q, meta)
+	}
+}
+
+func (s *Service) syncOrder(ctx context.Context, o models.Order, q hyperliquid.OrderQuery, meta hyperliquid.ExchangeMeta) {
+	switch q.Status {
+	case "open", "unknownOid":
+		return
+	case "filled", "triggered":
+		o.Status = models.OrderFilled
+		s.applyFills(ctx, &o, q)
+	default:
+		o.Status = models.OrderCanceled
+		o.Error = q.Status
+		s.applyFills(ctx, &o, q)
+	}
+
+	if _, err := s.ordersSvc.UpdateResult(ctx, o); err != nil {
+		s.log.Sugar().Errorw("failed to update order", "order", o.ID, "error", err)
+		return
+	}
+	if o.DecisionID == nil {
+		return
+	}
+
+	if o.Kind != models.OrderKindEntry && o.FilledSize > 0 {
+		if _, err := s.tradesSvc.Record(ctx, s.userID, o.Symbol, o.Side, o.FilledSize, o.AvgPrice); err != nil {
+			s.log.Sugar().Errorw("failed to record trade", "order", o.ID, "kind", o.Kind, "error", err)
+		}
+	}
+
+	switch {
+	case o.Kind == models.OrderKindEntry && o.FilledSize > 0:
+		s.onEntryFilled(ctx, o, meta)
+	case o.Kind == models.OrderKindSL && o.Status == models.OrderFilled:
+		s.cancelExits(ctx, *o.DecisionID)
+	case o.Kind != models.OrderKindEntry && (q.Status == "reduceOnlyCanceled" || q.Status == "liquidatedCanceled"):
+		// The exchange only cancels reduce-only legs like this once the position is gone.
+		s.cancelExits(ctx, *o.DecisionID)
+	case o.Kind != models.OrderKindEntry && o.Kind != models.OrderKindExit && o.Status == models.OrderFilled:
+		s.cancelStopIfFlat(ctx, *o.DecisionID)
+	}
+}
+
+func (s *Service) onEntryFilled(ctx context.Context, o models.Order, meta hyperliquid.ExchangeMeta) {
+	d, err := s.tradesSvc.Decision(ctx, *o.DecisionID)
+	if err != nil {
+		s.log.Sugar().Errorw("failed to load decision", "decision", *o.DecisionID, "error", err)
+		return
+	}
+	if _, err := s.tradesSvc.Record(ctx, s.userID, d.Symbol, o.Side, o.FilledSize, o.AvgPrice); err != nil {
+		s.log.Sugar().Errorw("failed to record trade", "error", err)
+	}
+	s.protect(ctx, d, o.Side == "buy", o.FilledSize, meta)
+}
+
+// cancelStopIfFlat cancels the stop once every take-profit leg of the decision has filled.
+func (s *Service) cancelStopIfFlat(ctx context.Context, decisionID int64) {
+	legs, err := s.ordersSvc.ByDecision(ctx, decisionID)
+	if err != nil {
+		s.log.Sugar().Errorw("failed to list decision orders", "decision", decisionID, "error", err)
+		return
+	}
+	for _, l := range legs {
+		if l.Kind != models.OrderKindEntry && l.Kind != models.OrderKindSL && l.Status == models.OrderResting {
+			return
+		}
+	}
+	s.cancelExits(ctx, decisionID)
+}
+
+// cancelExits cancels every exit leg of a decision that is still resting.
+func (s *Service) cancelExits(ctx context.Context, decisionID int64) {
+	legs, err := s.ordersSvc.ByDecision(ctx, decisionID)
+	if err != nil {
+		s.log.Sugar().Errorw("failed to list decision orders", "decision", decisionID, "error", err)
+		return
+	}
+	for _, l := range legs {
+		if l.Kind != models.OrderKindEntry {
+			s.cancelOrder(ctx, l)
+		}
+	}
+}
+
+// cancelCoinExits cancels every resting exit leg on a coin, whichever decision placed it.
+func (s *Service) cancelCoinExits(ctx context.Context, coin string) {
+	active, err := s.ordersSvc.Active(ctx, s.userID)
+	if err != nil {
+		s.log.Sugar().Errorw("failed to list active orders", "error", err)
+		return
+	}
+	for _, o := range active {
+		if o.Kind != models.OrderKindEntry && hyperliquid.NormalizeSymbol(o.Symbol) == coin {
+			s.cancelOrder(ctx, o)
+		}
+	}
+}
+
+// cancelOrder cancels a resting order and reports whether it is no longer resting.
+func (s *Service) cancelOrder(ctx context.Context, o models.Order) bool {
+	if o.Status != models.OrderResting || o.ExchangeOID == nil {
+		return true
+	}
+	if err := s.ex.CancelOrder(ctx, o.Symbol, *o.ExchangeOID); err != nil {
+		s.log.Sugar().Errorw("failed to cancel order", "order", o.ID, "error", err)
+		return false
+	}
+	o.Status = models.OrderCanceled
+	if _, err := s.ordersSvc.UpdateResult(ctx, o); err != nil {
+		s.log.Sugar().Errorw("failed to update order", "order", o.ID, "error", err)
+	}
+	return true
+}
+
+func parseF(s string) float64 {
+	f, _ := strconv.ParseFloat(s, 64)
+	return f
+}
@@ -0,0 +1,114 @@
+package bot
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+
+	"deepseek-trader/agent"
+	"deepseek-trader/hyperliquid"
+	"deepseek-trader/models"
+	"deepseek-trader/risk"
+)
+
+// checkRisk runs a cycle's recorded decisions through the bot's risk limits and returns the ones to
+// execute, in order. Buy, sell and reverse entries are checked together so that their total exposure
+// is capped; exits and target adjustments pass unchecked. Vetoed decisions are marked rejected with
+// the reason; clamped ones come back with the reduced size and the reason set.
+func (s *Service) checkRisk(ctx context.Context, ds []models.Decision, snap agent.Snapshot) []models.Decision {
+	var entries []int
+	for i, d := range ds {
+		if d.Action == "buy" || d.Action == "sell" || d.Action == "reverse" {
+			entries = append(entries, i)
+		}
+	}
+	if len(entries) == 0 {
+		return ds
+	}
+
+	now := time.Now()
+	fills, err := s.userFills(ctx)
+	if err != nil {
+		return s.failEntries(ctx, ds, entries, "risk: failed to load fills: "+err.Error())
+	}
+	decisions, err := s.tradesSvc.DecisionsSince(ctx, s.userID, now.Add(-24*time.Hour))
+	if err != nil {
+		return s.failEntries(ctx, ds, entries, "risk: failed to load decisions: "+err.Error())
+	}
+
+	prices := make(map[string]float64, len(snap.CoinsMids))
+	for coin, mid := range snap.CoinsMids {
+		prices[coin], _ = strconv.ParseFloat(mid, 64)
+	}
+	positions := make(map[string]float64, len(snap.Positions))
+	for _, p := range snap.Positions {
+		positions[p.Coin] += p.Size
+	}
+	orders := make([]risk.Order, 0, len(entries))
+	for _, i := range entries {
+		orders = append(orders, riskOrder(ds[i], snap, prices))
+	}
+	verdicts := s.botCfg.Risk.CheckAll(
+		risk.Account{Now: now, Balance: snap.Balance, Positions: positions, Fills: fills, Decisions: decisions, Prices: prices},
+		orders,
+	)
+
+	vetoed := make(map[int]bool)
+	for k, i := range entries {
+		v := verdicts[k]
+		if !v.Approved {
+			s.log.Sugar().Infow("decision vetoed by risk limits", "decision", ds[i].ID, "reason", v.Reason)
+			s.markDecision(ctx, ds[i].ID, models.DecisionRejected, v.Reason)
+			vetoed[i] = true
+			continue
+		}
+		if v.Reason != "" {
+			s.log.Sugar().Infow("decision size clamped by risk limits", "decision", ds[i].ID, "reason", v.Reason)
+		}
+		ds[i].Size = v.Size
+		ds[i].Reason = v.Reason
+	}
+	return without(ds, vetoed)
+}
+
+// riskOrder describes an entry decision for the risk check.
+func riskOrder(d models.Decision, snap agent.Snapshot, prices map[string]float64) risk.Order {
+	coin := hyperliquid.NormalizeSymbol(d.Symbol)
+	price := d.LimitPrice
+	if !strings.EqualFold(d.OrderType, "limit") || price <= 0 {
+		price = prices[coin]
+	}
+	isBuy := d.Action == "buy"
+	if d.Action == "reverse" {
+		isBuy = snap.Position(coin) < 0
+	}
+	decimals := 0
+	if in, ok := snap.Meta.Instrument(coin); ok {
+		decimals = in.SzDecimals
+	}
+	return risk.Order{
+		Coin: coin, IsBuy: isBuy, Size: d.Size, Price: price, StopLoss: d.SL, SzDecimals: decimals,
+		Confidence: d.Confidence, Reverse: d.Action == "reverse",
+	}
+}
+
+// failEntries marks the entries failed when the risk check cannot run and returns the other decisions.
+func (s *Service) failEntries(ctx context.Context, ds []models.Decision, entries []int, reason string) []models.Decision {
+	failed := make(map[int]bool, len(entries))
+	for _, i := range entries {
+		s.markDecision(ctx, ds[i].ID, models.DecisionFailed, reason)
+		failed[i] = true
+	}
+	return without(ds, failed)
+}
+
+func without(ds []models.Decision, drop map[int]bool) []models.Decision {
+	kept := make([]models.Decision, 0, len(ds))
+	for i, d := range ds {
+		if !drop[i] {
+			kept = append(kept, d)
+		}
+	}
+	return kept
+}
@@ -2,6 +2,9 @@ package bot
 
 import (
 	"context"
+	"errors"
+	"sort"
+	"strings"
 	"sync"
 	"time"
 
@@ -14,33 +17,77 @@ import (
 	"go.uber.org/zap"
 )
 
-type DeepSeekAgent interface {
-	Decide(ctx context.Context, snap agent.Snapshot) (agent.Decision, error)
-}
-
+// Service is one user's bot: a decision loop bound to that user's wallet, exchange and history.
 type Service struct {
 	mx     sync.RWMutex
 	on     bool
 	cancel context.CancelFunc
+	done   chan struct{}
 
+	userID    int64
 	hl        *hyperliquid.Client
+	ex        Exchange
 	tradesSvc *services.TradesService
+	ordersSvc *services.OrdersService
 	statsSvc  *services.StatsService
+	callsSvc  *services.AgentCallsService
+	prompts   *services.PromptsService
 	cfg       *config.Settings
-	agent     DeepSeekAgent
+	botCfg    Config
+	agent     agent.DecisionAgent
+	promptID  int64 // prompt version the agent renders; 0 for the built-in one
 	log       *zap.Logger
+
+	// market is the WebSocket-fed state; nil until the bot is started or when HL_WS_URL is empty.
+	market  *hyperliquid.MarketState
+	updates chan []hyperliquid.OrderQuery
+	fillCh  chan fillBatch
+	fills   fillLog
+	meta    hyperliquid.ExchangeMeta
+	// levels is the margin-health level per coin at the monitor's last run.
+	levels map[string]string
+	// committed is the initial margin of the entries placed this cycle, which the snapshot's MarginUsed
+	// does not hold yet.
+	committed float64
+
+	// armed is when the bot started; the breaker only counts losses since then. failures counts
+	// consecutive failed exchange calls and tripped stops the loop's work once the breaker trips.
+	armed    time.Time
+	failures int
+	tripped  bool
+	// onTrip is called, on its own goroutine, with the reason once the breaker trips.
+	onTrip func(reason string)
 }
 
-func NewService(hl *hyperliquid.Client, tradesSvc *services.TradesService, statsSvc *services.StatsService, cfg *config.Settings, log *zap.Logger) *Service {
-	ag := agent.NewDeepseekAgent(cfg)
+func NewService(
+	userID int64, hl *hyperliquid.Client, ex Exchange,
+	tradesSvc *services.TradesService, ordersSvc *services.OrdersService, statsSvc *services.StatsService,
+	callsSvc *services.AgentCallsService, prompts *services.PromptsService,
+	cfg *config.Settings, botCfg Config, log *zap.Logger,
+) (*Service, error) {
+	if err := agent.ValidateTimeframes(botCfg.Timeframes); err != nil {
+		return nil, err
+	}
+	ag, err := agent.NewLLMAgent(botCfg.Agent, botCfg.Validation, nil)
+	if err != nil {
+		return nil, err
+	}
 	return &Service{
+		userID:    userID,
 		hl:        hl,
+		ex:        ex,
 		tradesSvc: tradesSvc,
+		ordersSvc: ordersSvc,
 		statsSvc:  statsSvc,
+		callsSvc:  callsSvc,
+		prompts:   prompts,
 		cfg:       cfg,
+		botCfg:    botCfg,
 		agent:     ag,
 		log:       log,
-	}
+		updates:   make(chan []hyperliquid.OrderQuery, 64),
+		fillCh:    make(chan fillBatch, 64),
+	}, nil
 }
 
 func (s *Service) Start() {
@@ -52,18 +99,44 @@ func (s *Service) Start() {
 	ctx, cancel := context.WithCancel(context.Background())
 	s.cancel = cancel
 	s.on = true
+	s.done = make(chan struct{})
+	s.armed = time.Now()
 	s.mx.Unlock()
 
-	go s.loop(ctx)
+	if s.cfg.HLWSURL != "" {
+		// Paper orders never reach the exchange, so a paper bot streams market data only.
+		user := s.hl.WalletAddress()
+		if s.cfg.PaperTrading {
+			user = ""
+		}
+		intervals := make([]string, 0, len(s.botCfg.Timeframes))
+		for _, tf := range s.botCfg.Timeframes {
+			intervals = append(intervals, tf.Interval)
+		}
+		ws := hyperliquid.NewWSClient(s.cfg.HLWSURL, user, agent.Coins, intervals, s.log)
+		ws.OnFills = s.pushFills
+		ws.OnOrderUpdates = s.pushOrderUpdates
+		s.market = ws.State()
+		go ws.Run(ctx)
+	}
+
+	go s.loop(ctx, s.done)
 }
 
+// Stop cancels the loop and waits for the tick in flight to finish, so the caller may release
+// what the bot was using (e.g. its signing key) once Stop returns.
 func (s *Service) Stop() {
 	s.mx.Lock()
 	if s.cancel != nil {
 		s.cancel()
 	}
 	s.on = false
+	done := s.done
 	s.mx.Unlock()
+
+	if done != nil {
+		<-done
+	}
 }
 
 func (s *Service) IsOn() bool {
@@ -72,119 +145,344 @@ func (s *Service) IsOn() bool {
 	return s.on
 }
 
-func (s *Service) loop(ctx context.Context) {
+// paperMatchEvery is how often a paper bot's open orders and triggers are matched against fresh books.
+const paperMatchEvery = 10 * time.Second
+
+// matcher is a simulated exchange that fills open orders only when asked to, like paper.Exchange.
+type matcher interface {
+	Match(ctx context.Context) error
+}
+
+func (s *Service) loop(ctx context.Context, done chan struct{}) {
+	defer close(done)
+	s.reconcile(ctx)
 	ticker := time.NewTicker(15 * time.Minute)
 	defer ticker.Stop()
+	var health <-chan time.Time
+	if every := s.botCfg.Health.CheckEvery; every > 0 {
+		t := time.NewTicker(every)
+		defer t.Stop()
+		health = t.C
+	}
+	var match <-chan time.Time
+	if _, ok := s.ex.(matcher); ok {
+		t := time.NewTicker(paperMatchEvery)
+		defer t.Stop()
+		match = t.C
+	}
 
 	for {
 		select {
 		case <-ctx.Done():
 			return
 		case <-ticker.C:
-			now := time.Now()
-			endTime := unixMilli(now)
-			startTime := unixMilli(now.Add(-3 * time.Hour))
-			stats, err := s.hl.GetLiveStats(ctx)
-			if err != nil {
-				s.log.Sugar().Errorw("failed to get stats", "error", err)
-				continue
-			}
+			s.tick(ctx)
+		case <-health:
+			s.checkHealth(ctx)
+		case <-match:
+			s.matchPaper(ctx)
+		case updates := <-s.updates:
+			s.onOrderUpdates(ctx, updates)
+		case b := <-s.fillCh:
+			s.onFills(ctx, b)
+		}
+	}
+}
 
-			coinsMids, err := s.hl.CoinsMids(ctx)
-			if err != nil {
-				s.log.Sugar().Errorw("failed to get coin mids", "error", err)
-				continue
-			}
+// pushOrderUpdates hands WebSocket order updates to the loop goroutine so order state is only
+// ever mutated from one place. Updates are dropped when the loop is busy; the next tick resyncs them.
+func (s *Service) pushOrderUpdates(updates []hyperliquid.OrderQuery) {
+	select {
+	case s.updates <- updates:
+	default:
+		s.log.Sugar().Warnw("order update dropped, loop busy", "count", len(updates))
+	}
+}
 
-			meta, err := s.hl.Meta(ctx)
-			if err != nil {
-				s.log.Sugar().Errorw("failed to get meta", "error", err)
-				continue
-			}
+func (s *Service) onOrderUpdates(ctx context.Context, updates []hyperliquid.OrderQuery) {
+	for _, u := range updates {
+		o, err := s.ordersSvc.ByExchangeOID(ctx, s.userID, u.Order.Oid)
+		if err != nil || o.Status != models.OrderResting {
+			continue
+		}
+		s.syncOrder(ctx, o, u, s.meta)
+	}
+}
 
-			var orderBooks []hyperliquid.OrderBookSnapshot
-			candleSnapshots := make(map[string][]hyperliquid.Candle, 0)
-			for _, coin := range agent.Coins {
-				l2Book, err := s.hl.L2Book(ctx, coin)
-				if err != nil {
-					s.log.Sugar().Errorw("failed to get l2book", "error", err)
-					continue
-				}
-				orderBooks = append(orderBooks, l2Book)
-
-				candleSnapshot, err := s.hl.CandleSnapshot(ctx, coin, startTime, endTime)
-				if err != nil {
-					s.log.Sugar().Errorw("failed to get candle snapshot", "error", err)
-					continue
-				}
-
-				candleSnapshots[coin] = candleSnapshot
-			}
+// matchPaper fills the paper orders the market reached and syncs them, so their trades are booked and
+// exits placed without waiting for the next tick. Syncing waits for the first tick's meta to size exits.
+func (s *Service) matchPaper(ctx context.Context) {
+	if err := s.ex.(matcher).Match(ctx); err != nil {
+		s.log.Sugar().Errorw("failed to match paper orders", "error", err)
+		return
+	}
+	if s.tripped || len(s.meta.Universe) == 0 {
+		return
+	}
+	s.syncOrders(ctx, s.meta)
+}
 
-			filtered := agent.FilterCoinsMids(coinsMids)
-			snap := agent.Snapshot{
-				Balance:         stats.Balance,
-				PnL:             stats.PnL,
-				ROE:             stats.ROE,
-				CoinsMids:       filtered,
-				Meta:            meta,
-				OrderBooks:      orderBooks,
-				CandleSnapshots: candleSnapshots,
-			}
+// tick runs one decision cycle: snapshot, settle and sync orders, decide, record, execute. The agent may
+// answer with several decisions; they are risk-checked together and executed in the agent's priority order.
+func (s *Service) tick(ctx context.Context) {
+	if s.tripped {
+		return
+	}
+	snap, ok := s.snapshot(ctx)
+	if !ok {
+		s.apiFailed(ctx, "failed to build the snapshot")
+		return
+	}
+	s.failures = 0
+	s.meta = snap.Meta
+	s.committed = 0
+	if !s.checkBreaker(ctx, snap.Balance, snap.Positions) {
+		return
+	}
+	s.reconcile(ctx)
+	s.syncOrders(ctx, snap.Meta)
+	s.useActivePrompt(ctx)
 
-			hist, err := s.hl.HistoricalOrders(ctx)
-			if err != nil {
-				s.log.Sugar().Errorw("failed to get orders", "error", err)
-				continue
-			}
+	s.log.Sugar().Infow("start agent", "positions", len(snap.Positions), "openOrders", len(snap.OpenOrders))
+	decs, call, err := s.agent.Decide(ctx, snap)
+	var verr *agent.ValidationError
+	if err != nil && !errors.As(err, &verr) {
+		s.log.Sugar().Errorw("failed to get decision", "error", err)
+		s.archive(ctx, nil, snap, call, err)
+		return
+	}
 
-			decisions, err := s.tradesSvc.LatestDecisions(ctx, 10)
-			if err != nil {
-				s.log.Sugar().Errorw("failed to get lates decisions", "error", err)
-				continue
-			}
+	decideErr := err
+	ds := make([]models.Decision, 0, len(decs))
+	ids := make([]int64, 0, len(decs))
+	for _, dec := range decs {
+		d, err := s.tradesSvc.RecordDecision(ctx, decisionModel(s.userID, dec, call))
+		if err != nil {
+			s.log.Sugar().Errorw("failed to record decision", "symbol", dec.Symbol, "error", err)
+			continue
+		}
+		ds = append(ds, d)
+		ids = append(ids, d.ID)
+	}
+	s.archive(ctx, ids, snap, call, decideErr)
+	if verr != nil {
+		for _, d := range ds {
+			s.log.Sugar().Warnw("agent decision rejected", "decision", d.ID, "error", verr)
+			s.markDecision(ctx, d.ID, models.DecisionRejected, strings.Join(verr.Errors, "; "))
+		}
+		return
+	}
 
-			for _, d := range decisions {
-				snap.Decisions = append(snap.Decisions, d)
-			}
-			for _, t := range hist {
-				snap.Trades = append(snap.Trades, t)
-			}
+	for _, d := range s.checkRisk(ctx, ds, snap) {
+		s.execute(ctx, d, snap)
+	}
+}
 
-			snap.Balance += 10000
-			snap.PnL += 10000
-			snap.ROE += 10000
-			s.log.Sugar().Infow("start agent", "snapshot", snap)
-			dec, err := s.agent.Decide(ctx, snap)
-			if err != nil {
-				s.log.Sugar().Errorw("failed to get decision", "error", err)
-				continue
-			}
+// decisionModel converts an agent decision into the record stored for it.
+func decisionModel(userID int64, dec agent.Decision, call agent.Call) models.Decision {
+	d := models.Decision{
+		UserID:     &userID,
+		Action:     dec.Action,
+		Symbol:     dec.Symbol,
+		Size:       dec.Size,
+		OrderType:  dec.Order,
+		LimitPrice: dec.LimitPrice,
+		Fraction:   dec.Fraction,
+		TP1:        dec.Targets.TP1,
+		TP2:        dec.Targets.TP2,
+		TP3:        dec.Targets.TP3,
+		SL:         dec.Targets.SL,
+		Confidence: dec.Confidence,
+		Scores:     dec.ScoresJSON(),
+		Rationale:  dec.Rationale,
+	}
+	if call.PromptID != 0 {
+		d.PromptVersionID = &call.PromptID
+	}
+	return d
+}
 
-			d := models.Decision{
-				Action:     dec.Action,
-				Symbol:     dec.Symbol,
-				Size:       dec.Size,
-				OrderType:  dec.Order,
-				LimitPrice: dec.LimitPrice,
-				TP1:        dec.Targets.TP1,
-				TP2:        dec.Targets.TP2,
-				TP3:        dec.Targets.TP3,
-				SL:         dec.Targets.SL,
-			}
+// useActivePrompt switches the agent to the bot's active prompt version when it changed since the
+// last cycle. When the version cannot be loaded or compiled the agent keeps its current prompt.
+func (s *Service) useActivePrompt(ctx context.Context) {
+	p, err := s.prompts.Active(ctx, s.userID)
+	if err != nil {
+		s.log.Sugar().Errorw("failed to load active prompt", "error", err)
+		return
+	}
+	if p.ID == s.promptID {
+		return
+	}
+	prompt, err := s.prompts.Compile(p)
+	if err != nil {
+		s.log.Sugar().Errorw("failed to compile prompt, keeping the current one", "prompt", p.ID, "current", s.promptID, "error", err)
+		return
+	}
+	ag, err := agent.NewLLMAgent(s.botCfg.Agent, s.botCfg.Validation, prompt)
+	if err != nil {
+		s.log.Sugar().Errorw("failed to create agent", "error", err)
+		return
+	}
+	s.log.Sugar().Infow("prompt version switched", "prompt", p.ID, "name", p.Name, "version", p.Version)
+	s.agent, s.promptID = ag, p.ID
+}
 
-			_, err = s.tradesSvc.RecordDecision(ctx, d)
-			if err != nil || dec.Action == "none" {
-				continue
-			}
+// archive stores what the agent saw and answered in this cycle. Cycles in which no model was
+// called are not archived; a failure to archive is logged and does not stop the cycle.
+func (s *Service) archive(ctx context.Context, decisionIDs []int64, snap agent.Snapshot, call agent.Call, callErr error) {
+	if call.System == "" {
+		return
+	}
+	c, err := s.callsSvc.Archive(ctx, s.userID, decisionIDs, snap, call, callErr)
+	if err != nil {
+		s.log.Sugar().Errorw("failed to archive agent call", "decisions", decisionIDs, "error", err)
+		return
+	}
+	s.log.Sugar().Infow("agent call archived", "call", c.ID, "model", c.Model, "latencyMs", c.LatencyMS,
+		"promptTokens", c.PromptTokens, "completionTokens", c.CompletionTokens)
+}
+
+func (s *Service) snapshot(ctx context.Context) (agent.Snapshot, bool) {
+	now := time.Now()
+	stats, err := s.ex.GetLiveStats(ctx)
+	if err != nil {
+		s.log.Sugar().Errorw("failed to get stats", "error", err)
+		return agent.Snapshot{}, false
+	}
+
+	coinsMids, err := s.mids(ctx)
+	if err != nil {
+		s.log.Sugar().Errorw("failed to get coin mids", "error", err)
+		return agent.Snapshot{}, false
+	}
+
+	meta, err := s.hl.Meta(ctx)
+	if err != nil {
+		s.log.Sugar().Errorw("failed to get meta", "error", err)
+		return agent.Snapshot{}, false
+	}
 
-			_, err = s.tradesSvc.Record(ctx, dec.Symbol, dec.Action, dec.Size, dec.LimitPrice)
+	var orderBooks []hyperliquid.OrderBookSnapshot
+	candleSnapshots := make(map[string]map[string][]hyperliquid.Candle, len(agent.Coins))
+	history := make(map[string]map[string][]hyperliquid.Candle, len(agent.Coins))
+	for _, coin := range agent.Coins {
+		l2Book, err := s.book(ctx, coin)
+		if err != nil {
+			s.log.Sugar().Errorw("failed to get l2book", "error", err)
+			continue
+		}
+		orderBooks = append(orderBooks, l2Book)
+
+		history[coin], candleSnapshots[coin] = s.candles(ctx, coin, now)
+	}
+
+	account, err := s.ex.AccountState(ctx)
+	if err != nil {
+		s.log.Sugar().Errorw("failed to get account state", "error", err)
+		return agent.Snapshot{}, false
+	}
+	openOrders, err := s.ex.OpenOrders(ctx)
+	if err != nil {
+		s.log.Sugar().Errorw("failed to get open orders", "error", err)
+		return agent.Snapshot{}, false
+	}
+
+	filtered := agent.FilterCoinsMids(coinsMids)
+	snap := agent.Snapshot{
+		Balance:         stats.Balance,
+		PnL:             stats.PnL,
+		ROE:             stats.ROE,
+		MarginUsed:      account.MarginUsed,
+		Positions:       account.Positions,
+		OpenOrders:      openOrders,
+		CoinsMids:       filtered,
+		Meta:            meta,
+		OrderBooks:      orderBooks,
+		Timeframes:      s.botCfg.Timeframes,
+		CandleSnapshots: candleSnapshots,
+		Indicators:      agent.Indicators(history),
+		Books:           agent.Books(orderBooks),
+		Health:          s.botCfg.Health.health(meta, account),
+	}
+
+	hist, err := s.userFills(ctx)
+	if err != nil {
+		s.log.Sugar().Errorw("failed to get orders", "error", err)
+		return agent.Snapshot{}, false
+	}
+
+	decisions, err := s.tradesSvc.LatestDecisions(ctx, s.userID, 10)
+	if err != nil {
+		s.log.Sugar().Errorw("failed to get lates decisions", "error", err)
+		return agent.Snapshot{}, false
+	}
+
+	for _, d := range decisions {
+		snap.Decisions = append(snap.Decisions, d)
+	}
+	for _, t := range hist {
+		snap.Trades = append(snap.Trades, t)
+	}
+
+	return snap, true
+}
+
+// mids prefers the streamed mids and falls back to polling /info when the stream is stale.
+func (s *Service) mids(ctx context.Context) (map[string]string, error) {
+	if s.market != nil && s.market.Fresh(time.Minute) {
+		return s.market.Mids(), nil
+	}
+	return s.hl.CoinsMids(ctx)
+}
+
+// book prefers the coin's streamed book and polls it when the stream has not delivered one recently.
+func (s *Service) book(ctx context.Context, coin string) (hyperliquid.OrderBookSnapshot, error) {
+	if s.market != nil {
+		if b, ok := s.market.Book(coin, time.Minute); ok {
+			return b, nil
+		}
+	}
+	return s.hl.L2Book(ctx, coin)
+}
+
+// candles fetches every configured timeframe of a coin. history reaches back far enough for the
+// indicators; window is the part within each timeframe's lookback that goes into the snapshot.
+// A timeframe the stream keeps current is read from it; the others are polled and seeded for the
+// stream to extend. A timeframe that fails to load is logged and left out.
+func (s *Service) candles(ctx context.Context, coin string, now time.Time) (history, window map[string][]hyperliquid.Candle) {
+	history = make(map[string][]hyperliquid.Candle, len(s.botCfg.Timeframes))
+	window = make(map[string][]hyperliquid.Candle, len(s.botCfg.Timeframes))
+	for _, tf := range s.botCfg.Timeframes {
+		candles, ok := s.streamedCandles(coin, tf.Interval)
+		if !ok {
+			var err error
+			candles, err = s.hl.CandleSnapshot(ctx, coin, tf.Interval, unixMilli(tf.HistoryStart(now)), unixMilli(now))
 			if err != nil {
-				s.log.Sugar().Errorw("failed to record trade", "error", err)
+				s.log.Sugar().Errorw("failed to get candle snapshot", "coin", coin, "interval", tf.Interval, "error", err)
 				continue
 			}
+			if s.market != nil {
+				s.market.SeedCandles(coin, tf.Interval, candles)
+			}
 		}
+		history[tf.Interval] = candles
+		window[tf.Interval] = since(candles, unixMilli(now.Add(-tf.Lookback)))
 	}
+	return history, window
+}
+
+// streamedCandles reads a coin's candle history from the stream when it updated it within the last minute.
+func (s *Service) streamedCandles(coin, interval string) ([]hyperliquid.Candle, bool) {
+	if s.market == nil {
+		return nil, false
+	}
+	return s.market.Candles(coin, interval, time.Minute)
+}
+
+// since returns the candles starting at or after from; candles are oldest first.
+func since(candles []hyperliquid.Candle, from int64) []hyperliquid.Candle {
+	i := sort.Search(len(candles), func(i int) bool { return candles[i].StartTime >= from })
+	return candles[i:]
 }
 
 func unixMilli(t time.Time) int64 {
@@ -0,0 +1,162 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"deepseek-trader/agent"
+	"deepseek-trader/backtest"
+	"deepseek-trader/bot"
+	"deepseek-trader/config"
+	"deepseek-trader/db"
+	"deepseek-trader/hyperliquid"
+	"deepseek-trader/logger"
+	"deepseek-trader/repository"
+	"deepseek-trader/services"
+)
+
+func main() {
+	var (
+		candlesPath = flag.String("candles", "", "JSON file of candleSnapshot candles; downloaded from Hyperliquid when empty")
+		store       = flag.Bool("store", false, "read candles from the market-data store in Postgres instead of Hyperliquid")
+		savePath    = flag.String("save", "", "write downloaded candles to this file for offline reruns")
+		outPath     = flag.String("out", "", "write the result JSON here instead of stdout")
+		coins       = flag.String("coins", strings.Join(agent.Coins, ","), "comma-separated coins to replay")
+		from        = flag.String("from", "", "start time, RFC3339 (default: 7 days ago)")
+		to          = flag.String("to", "", "end time, RFC3339 (default: now)")
+		balance     = flag.Float64("balance", 10000, "initial balance")
+		slippage    = flag.Float64("slippage", 0.0005, "fraction paid on market and triggered fills")
+		leverage    = flag.Float64("leverage", 1, "maximum gross exposure as a multiple of equity")
+		every       = flag.Int("every", 1, "candles between agent decisions")
+		timeframes  = flag.String("timeframes", "", "interval:lookback pairs passed to the agent, e.g. 15m:3h,1h:24h (default: CANDLE_TIMEFRAMES)")
+		interval    = flag.String("interval", "", "interval of downloaded candles (default: the shortest timeframe)")
+		provider    = flag.String("provider", "", "LLM provider override: deepseek, openai, anthropic, ollama or llamacpp")
+		model       = flag.String("model", "", "LLM model override")
+		baseURL     = flag.String("base-url", "", "LLM base URL override")
+	)
+	flag.Parse()
+
+	log := logger.New()
+	defer func() { _ = log.Sync() }()
+
+	cfg, err := config.Load()
+	if err != nil {
+		log.Sugar().Fatalw("failed to load config", "error", err)
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	hlClient := hyperliquid.NewClient(cfg)
+
+	botCfg, err := bot.DefaultConfig(cfg)
+	if err != nil {
+		log.Sugar().Fatalw("invalid bot config", "error", err)
+	}
+	if *timeframes != "" {
+		if botCfg.Timeframes, err = agent.ParseTimeframes(*timeframes); err != nil {
+			log.Sugar().Fatalw("invalid timeframes", "error", err)
+		}
+	}
+	if *interval == "" {
+		*interval = agent.Shortest(botCfg.Timeframes).Interval
+	}
+
+	var series backtest.Series
+	if *candlesPath != "" {
+		series, err = backtest.LoadCandles(*candlesPath)
+	} else {
+		var start, end time.Time
+		var src backtest.CandleSource = hlClient
+		if *store {
+			dbConn, err := db.NewPostgres(ctx, cfg.DBURL)
+			if err != nil {
+				log.Sugar().Fatalw("failed to connect to db", "error", err)
+			}
+			defer dbConn.Close()
+			src = services.NewMarketService(repository.NewRepositories(dbConn).Market)
+		}
+		if start, end, err = parseRange(*from, *to); err == nil {
+			series, err = backtest.FetchCandles(ctx, src, strings.Split(*coins, ","), *interval, start, end)
+		}
+	}
+	if err != nil {
+		log.Sugar().Fatalw("failed to load candles", "error", err)
+	}
+	if *savePath != "" {
+		if err := series.Save(*savePath); err != nil {
+			log.Sugar().Fatalw("failed to save candles", "error", err)
+		}
+	}
+
+	meta, err := hlClient.Meta(ctx)
+	if err != nil {
+		log.Sugar().Warnw("failed to get meta, sizes will not be rounded", "error", err)
+	}
+
+	ac := botCfg.Agent.Override(*provider, *model, *baseURL, cfg.LLMAPIKey)
+	ag, err := agent.NewLLMAgent(ac, botCfg.Validation, nil)
+	if err != nil {
+		log.Sugar().Fatalw("failed to create agent", "error", err)
+	}
+
+	engine := backtest.NewEngine(backtest.Config{
+		InitialBalance: *balance,
+		FeeRate:        cfg.FeeRate,
+		Slippage:       *slippage,
+		Leverage:       *leverage,
+		DecisionEvery:  *every,
+		Timeframes:     botCfg.Timeframes,
+		Meta:           meta,
+		Risk:           &botCfg.Risk,
+		WarnDistance:   botCfg.Health.WarnDistance,
+		DangerDistance: botCfg.Health.DangerDistance,
+	}, ag, log)
+
+	res, err := engine.Run(ctx, series)
+	if err != nil {
+		log.Sugar().Fatalw("backtest failed", "error", err)
+	}
+	log.Sugar().Infow("backtest finished", "metrics", res.Metrics)
+
+	out := os.Stdout
+	if *outPath != "" {
+		f, err := os.Create(*outPath)
+		if err != nil {
+			log.Sugar().Fatalw("failed to create output file", "error", err)
+		}
+		defer f.Close()
+		out = f
+	}
+	enc := json.NewEncoder(out)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(res); err != nil {
+		log.Sugar().Fatalw("failed to write result", "error", err)
+	}
+}
+
+func parseRange(from, to string) (time.Time, time.Time, error) {
+	end := time.Now()
+	if to != "" {
+		t, err := time.Parse(time.RFC3339, to)
+		if err != nil {
+			return time.Time{}, time.Time{}, err
+		}
+		end = t
+	}
+	start := end.Add(-7 * 24 * time.Hour)
+	if from != "" {
+		t, err := time.Parse(time.RFC3339, from)
+		if err != nil {
+			return time.Time{}, time.Time{}, err
+		}
+		start = t
+	}
+	return start, end, nil
+}
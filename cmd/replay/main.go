@@ -0,0 +1,104 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"deepseek-trader/config"
+	"deepseek-trader/db"
+	"deepseek-trader/logger"
+	"deepseek-trader/replay"
+	"deepseek-trader/repository"
+	"deepseek-trader/services"
+)
+
+func main() {
+	var (
+		userID      = flag.Int64("user", 0, "user whose archived cycles are replayed")
+		callID      = flag.Int64("call", 0, "replay this archived agent call only")
+		from        = flag.String("from", "", "replay calls archived since this time, RFC3339")
+		to          = flag.String("to", "", "end of the range, RFC3339 (default: now)")
+		limit       = flag.Int("limit", replay.MaxCycles, "maximum cycles to replay")
+		provider    = flag.String("provider", "", "LLM provider override: deepseek, openai, anthropic, ollama or llamacpp")
+		model       = flag.String("model", "", "LLM model override")
+		baseURL     = flag.String("base-url", "", "LLM base URL override")
+		promptID    = flag.Int64("prompt", 0, "prompt version to replay with (default: the active one; -1: the built-in prompt)")
+		temperature = flag.Float64("temperature", -1, "LLM temperature override (default: the bot's)")
+		outPath     = flag.String("out", "", "write the result JSON here instead of stdout")
+	)
+	flag.Parse()
+
+	log := logger.New()
+	defer func() { _ = log.Sync() }()
+
+	if *userID == 0 || (*callID == 0 && *from == "") {
+		log.Sugar().Fatal("-user and either -call or -from are required")
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		log.Sugar().Fatalw("failed to load config", "error", err)
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	dbConn, err := db.NewPostgres(ctx, cfg.DBURL)
+	if err != nil {
+		log.Sugar().Fatalw("failed to connect to db", "error", err)
+	}
+	defer dbConn.Close()
+	repos := repository.NewRepositories(dbConn)
+
+	req := replay.Request{
+		CallID:   *callID,
+		Limit:    *limit,
+		Provider: *provider,
+		Model:    *model,
+		BaseURL:  *baseURL,
+		PromptID: *promptID,
+	}
+	if *temperature >= 0 {
+		req.Temperature = temperature
+	}
+	if *from != "" {
+		if req.From, err = time.Parse(time.RFC3339, *from); err != nil {
+			log.Sugar().Fatalw("invalid -from", "error", err)
+		}
+	}
+	if *to != "" {
+		if req.To, err = time.Parse(time.RFC3339, *to); err != nil {
+			log.Sugar().Fatalw("invalid -to", "error", err)
+		}
+	}
+
+	replayer := replay.NewReplayer(
+		services.NewAgentCallsService(repos.Calls), services.NewTradesService(repos.Trades),
+		services.NewBotsService(repos.Bots), services.NewPromptsService(repos.Prompts), cfg, log,
+	)
+	res, err := replayer.Run(ctx, *userID, req)
+	if err != nil {
+		log.Sugar().Fatalw("replay failed", "error", err)
+	}
+	log.Sugar().Infow("replay finished", "summary", res.Summary)
+
+	out := os.Stdout
+	if *outPath != "" {
+		f, err := os.Create(*outPath)
+		if err != nil {
+			log.Sugar().Fatalw("failed to create output file", "error", err)
+		}
+		defer f.Close()
+		out = f
+	}
+	enc := json.NewEncoder(out)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(res); err != nil {
+		log.Sugar().Fatalw("failed to write result", "error", err)
+	}
+}
@@ -8,19 +8,62 @@ import (
 )
 
 type Settings struct {
-	Port            int
-	DBURL           string
-	APIKey          string
-	APISecret       string
-	SecretKey       string
-	HLBaseURL       string
-	HLWSURL         string
-	JWTSecret       string
-	DeepseekAPIKey  string
-	DeepseekBaseURL string
-	DeepseekModel   string
-	FeeRate         float64
-	APIWallet       string
+	Port              int
+	DBURL             string
+	APIKey            string
+	APISecret         string
+	SecretKey         string
+	HLBaseURL         string
+	HLWSURL           string
+	JWTSecret         string
+	DeepseekAPIKey    string
+	DeepseekBaseURL   string
+	DeepseekModel     string
+	LLMProvider       string
+	LLMBaseURL        string
+	LLMModel          string
+	LLMAPIKey         string
+	LLMTemperature    float64
+	LLMMaxTokens      int
+	LLMTimeout        int
+	DecisionBand      float64
+	DecisionRepair    bool
+	DecisionMax       int
+	RiskPerTrade      float64
+	RiskMaxPosition   float64
+	RiskCooldown      int
+	RiskPerHour       int
+	RiskPerDay        int
+	RiskLossStreak    int
+	RiskLossPause     int
+	RiskMinConfidence float64
+	RiskMaxTotal      float64
+	LeverageDefault   int
+	LeverageIsolated  bool
+	LeveragePerCoin   string
+	MarginWarn        float64
+	MarginDanger      float64
+	MarginAutoReduce  bool
+	MarginReduce      float64
+	MarginCheckEvery  int
+	BreakerDrawdown   float64
+	BreakerLossStreak int
+	BreakerAPIFails   int
+	BreakerFlatten    bool
+	OrderSlippage     float64
+	OrderLimitTif     string
+	OrderTTL          int
+	CandleTimeframes  string
+	MarketRecord      bool
+	MarketIntervals   string
+	MarketHistory     int
+	MarketBackfill    int
+	MarketBookEvery   int
+	FeeRate           float64
+	APIWallet         string
+	PaperTrading      bool
+	PaperBalance      float64
+	PaperLeverage     float64
 }
 
 func Load() (*Settings, error) {
@@ -29,19 +72,62 @@ func Load() (*Settings, error) {
 
 	port := getInt("PORT", 8080)
 	cfg := &Settings{
-		Port:            port,
-		DBURL:           getStr("DB_URL", "postgres://postgres:postgres@db:5432/deepseek_trader?sslmode=disable"),
-		APIKey:          getStr("API_KEY", ""),
-		APISecret:       getStr("API_SECRET", ""),
-		SecretKey:       getStr("SECRET_KEY", "0123456789abcdef0123456789abcdef"),
-		HLBaseURL:       getStr("HL_BASE_URL", "https://api.hyperliquid.xyz"),
-		HLWSURL:         getStr("HL_WS_URL", "wss://api.hyperliquid.xyz/ws"),
-		JWTSecret:       getStr("JWT_SECRET", "change-me-super-secret"),
-		DeepseekAPIKey:  getStr("DEEPSEEK_API_KEY", ""),
-		DeepseekBaseURL: getStr("DEEPSEEK_BASE_URL", "https://api.deepseek.com"),
-		DeepseekModel:   getStr("DEEPSEEK_MODEL", "deepseek-chat"),
-		FeeRate:         getFloat("FEE_RATE", 0.0005),
-		APIWallet:       getStr("API_WALLET", ""),
+		Port:              port,
+		DBURL:             getStr("DB_URL", "postgres://postgres:postgres@db:5432/deepseek_trader?sslmode=disable"),
+		APIKey:            getStr("API_KEY", ""),
+		APISecret:         getStr("API_SECRET", ""),
+		SecretKey:         getStr("SECRET_KEY", "0123456789abcdef0123456789abcdef"),
+		HLBaseURL:         getStr("HL_BASE_URL", "https://api.hyperliquid.xyz"),
+		HLWSURL:           getStr("HL_WS_URL", "wss://api.hyperliquid.xyz/ws"),
+		JWTSecret:         getStr("JWT_SECRET", "change-me-super-secret"),
+		DeepseekAPIKey:    getStr("DEEPSEEK_API_KEY", ""),
+		DeepseekBaseURL:   getStr("DEEPSEEK_BASE_URL", "https://api.deepseek.com"),
+		DeepseekModel:     getStr("DEEPSEEK_MODEL", "deepseek-chat"),
+		LLMProvider:       getStr("LLM_PROVIDER", "deepseek"),
+		LLMBaseURL:        getStr("LLM_BASE_URL", ""),
+		LLMModel:          getStr("LLM_MODEL", ""),
+		LLMAPIKey:         getStr("LLM_API_KEY", ""),
+		LLMTemperature:    getFloat("LLM_TEMPERATURE", 0.2),
+		LLMMaxTokens:      getInt("LLM_MAX_TOKENS", 0),
+		LLMTimeout:        getInt("LLM_TIMEOUT_SECONDS", 1200),
+		DecisionBand:      getFloat("DECISION_PRICE_BAND", 0.005),
+		DecisionRepair:    getBool("DECISION_REPAIR", true),
+		DecisionMax:       getInt("DECISION_MAX_PER_CYCLE", 1),
+		RiskPerTrade:      getFloat("RISK_PER_TRADE", 0.015),
+		RiskMaxPosition:   getFloat("RISK_MAX_POSITION", 0.20),
+		RiskCooldown:      getInt("RISK_COOLDOWN_MINUTES", 15),
+		RiskPerHour:       getInt("RISK_TRADES_PER_HOUR", 4),
+		RiskPerDay:        getInt("RISK_TRADES_PER_DAY", 12),
+		RiskLossStreak:    getInt("RISK_LOSS_STREAK", 3),
+		RiskLossPause:     getInt("RISK_LOSS_PAUSE_MINUTES", 60),
+		RiskMinConfidence: getFloat("RISK_MIN_CONFIDENCE", 0),
+		RiskMaxTotal:      getFloat("RISK_MAX_TOTAL_EXPOSURE", 1),
+		LeverageDefault:   getInt("LEVERAGE_DEFAULT", 1),
+		LeverageIsolated:  getBool("LEVERAGE_ISOLATED", false),
+		LeveragePerCoin:   getStr("LEVERAGE_PER_COIN", ""),
+		MarginWarn:        getFloat("MARGIN_WARN_DISTANCE", 0.15),
+		MarginDanger:      getFloat("MARGIN_DANGER_DISTANCE", 0.05),
+		MarginAutoReduce:  getBool("MARGIN_AUTO_REDUCE", false),
+		MarginReduce:      getFloat("MARGIN_REDUCE_FRACTION", 0.5),
+		MarginCheckEvery:  getInt("MARGIN_CHECK_SECONDS", 60),
+		BreakerDrawdown:   getFloat("BREAKER_DAILY_DRAWDOWN", 0.10),
+		BreakerLossStreak: getInt("BREAKER_LOSS_STREAK", 6),
+		BreakerAPIFails:   getInt("BREAKER_API_FAILURES", 5),
+		BreakerFlatten:    getBool("BREAKER_FLATTEN", false),
+		OrderSlippage:     getFloat("ORDER_SLIPPAGE", 0.05),
+		OrderLimitTif:     getStr("ORDER_LIMIT_TIF", "Gtc"),
+		OrderTTL:          getInt("ORDER_TTL_MINUTES", 60),
+		CandleTimeframes:  getStr("CANDLE_TIMEFRAMES", "15m:3h"),
+		MarketRecord:      getBool("MARKET_RECORD", true),
+		MarketIntervals:   getStr("MARKET_INTERVALS", "15m,1h,4h,1d"),
+		MarketHistory:     getInt("MARKET_HISTORY_DAYS", 30),
+		MarketBackfill:    getInt("MARKET_BACKFILL_MINUTES", 15),
+		MarketBookEvery:   getInt("MARKET_BOOK_SECONDS", 60),
+		FeeRate:           getFloat("FEE_RATE", 0.0005),
+		APIWallet:         getStr("API_WALLET", ""),
+		PaperTrading:      getBool("PAPER_TRADING", false),
+		PaperBalance:      getFloat("PAPER_BALANCE", 10000),
+		PaperLeverage:     getFloat("PAPER_LEVERAGE", 1),
 	}
 	return cfg, nil
 }
@@ -70,3 +156,12 @@ func getFloat(key string, def float64) float64 {
 	}
 	return def
 }
+
+func getBool(key string, def bool) bool {
+	if v := os.Getenv(key); v != "" {
+		if b, err := strconv.ParseBool(v); err == nil {
+			return b
+		}
+	}
+	return def
+}
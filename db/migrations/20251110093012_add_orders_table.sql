@@ -0,0 +1,34 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE decisions
+    ADD COLUMN IF NOT EXISTS status TEXT NOT NULL DEFAULT 'pending',
+    ADD COLUMN IF NOT EXISTS reason TEXT NOT NULL DEFAULT '';
+
+CREATE TABLE IF NOT EXISTS orders (
+    id SERIAL PRIMARY KEY,
+    decision_id INTEGER REFERENCES decisions(id),
+    symbol TEXT NOT NULL,
+    side TEXT NOT NULL,
+    order_type TEXT NOT NULL,
+    size NUMERIC NOT NULL,
+    price NUMERIC NOT NULL DEFAULT 0,
+    exchange_oid BIGINT,
+    status TEXT NOT NULL DEFAULT 'pending',
+    filled_size NUMERIC NOT NULL DEFAULT 0,
+    avg_price NUMERIC NOT NULL DEFAULT 0,
+    error TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS orders_decision_id_idx ON orders (decision_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS orders;
+
+ALTER TABLE decisions
+    DROP COLUMN IF EXISTS status,
+    DROP COLUMN IF EXISTS reason;
+-- +goose StatementEnd
@@ -0,0 +1,17 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE orders
+    ADD COLUMN IF NOT EXISTS kind TEXT NOT NULL DEFAULT 'entry',
+    ADD COLUMN IF NOT EXISTS trigger_price NUMERIC NOT NULL DEFAULT 0;
+
+CREATE INDEX IF NOT EXISTS orders_status_idx ON orders (status);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS orders_status_idx;
+
+ALTER TABLE orders
+    DROP COLUMN IF EXISTS kind,
+    DROP COLUMN IF EXISTS trigger_price;
+-- +goose StatementEnd
@@ -0,0 +1,63 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS paper_accounts (
+    id SERIAL PRIMARY KEY,
+    name TEXT NOT NULL UNIQUE,
+    initial_balance NUMERIC NOT NULL,
+    cash NUMERIC NOT NULL,
+    realized_pnl NUMERIC NOT NULL DEFAULT 0,
+    fees_paid NUMERIC NOT NULL DEFAULT 0,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS paper_positions (
+    account_id INTEGER NOT NULL REFERENCES paper_accounts(id),
+    coin TEXT NOT NULL,
+    size NUMERIC NOT NULL DEFAULT 0,
+    entry_price NUMERIC NOT NULL DEFAULT 0,
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (account_id, coin)
+);
+
+CREATE TABLE IF NOT EXISTS paper_orders (
+    id SERIAL PRIMARY KEY,
+    account_id INTEGER NOT NULL REFERENCES paper_accounts(id),
+    coin TEXT NOT NULL,
+    is_buy BOOLEAN NOT NULL,
+    size NUMERIC NOT NULL,
+    limit_price NUMERIC NOT NULL DEFAULT 0,
+    trigger_price NUMERIC NOT NULL DEFAULT 0,
+    tpsl TEXT NOT NULL DEFAULT '',
+    reduce_only BOOLEAN NOT NULL DEFAULT FALSE,
+    status TEXT NOT NULL,
+    filled_size NUMERIC NOT NULL DEFAULT 0,
+    avg_price NUMERIC NOT NULL DEFAULT 0,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS paper_fills (
+    id SERIAL PRIMARY KEY,
+    account_id INTEGER NOT NULL REFERENCES paper_accounts(id),
+    order_id INTEGER NOT NULL REFERENCES paper_orders(id),
+    coin TEXT NOT NULL,
+    is_buy BOOLEAN NOT NULL,
+    size NUMERIC NOT NULL,
+    price NUMERIC NOT NULL,
+    fee NUMERIC NOT NULL,
+    start_position NUMERIC NOT NULL,
+    closed_pnl NUMERIC NOT NULL DEFAULT 0,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS paper_fills_account_idx ON paper_fills (account_id, id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS paper_fills;
+DROP TABLE IF EXISTS paper_orders;
+DROP TABLE IF EXISTS paper_positions;
+DROP TABLE IF EXISTS paper_accounts;
+-- +goose StatementEnd
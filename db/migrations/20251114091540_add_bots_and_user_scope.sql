@@ -0,0 +1,31 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS bots (
+    user_id INTEGER PRIMARY KEY REFERENCES users(id),
+    config JSONB NOT NULL DEFAULT '{}',
+    enabled BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+ALTER TABLE decisions ADD COLUMN IF NOT EXISTS user_id INTEGER REFERENCES users(id);
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS user_id INTEGER REFERENCES users(id);
+ALTER TABLE trades ADD COLUMN IF NOT EXISTS user_id INTEGER REFERENCES users(id);
+
+CREATE INDEX IF NOT EXISTS decisions_user_id_idx ON decisions (user_id, id);
+CREATE INDEX IF NOT EXISTS orders_user_status_idx ON orders (user_id, status);
+CREATE INDEX IF NOT EXISTS trades_user_id_idx ON trades (user_id, id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS trades_user_id_idx;
+DROP INDEX IF EXISTS orders_user_status_idx;
+DROP INDEX IF EXISTS decisions_user_id_idx;
+
+ALTER TABLE trades DROP COLUMN IF EXISTS user_id;
+ALTER TABLE orders DROP COLUMN IF EXISTS user_id;
+ALTER TABLE decisions DROP COLUMN IF EXISTS user_id;
+
+DROP TABLE IF EXISTS bots;
+-- +goose StatementEnd
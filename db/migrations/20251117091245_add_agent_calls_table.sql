@@ -0,0 +1,29 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS agent_calls (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER REFERENCES users(id),
+    decision_id INTEGER REFERENCES decisions(id),
+    provider TEXT NOT NULL,
+    model TEXT NOT NULL,
+    snapshot JSONB NOT NULL,
+    system_prompt TEXT NOT NULL,
+    user_prompt TEXT NOT NULL,
+    response TEXT NOT NULL DEFAULT '',
+    repair_prompt TEXT NOT NULL DEFAULT '',
+    repair_response TEXT NOT NULL DEFAULT '',
+    latency_ms INTEGER NOT NULL DEFAULT 0,
+    prompt_tokens INTEGER NOT NULL DEFAULT 0,
+    completion_tokens INTEGER NOT NULL DEFAULT 0,
+    error TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS agent_calls_user_id_idx ON agent_calls (user_id, id);
+CREATE INDEX IF NOT EXISTS agent_calls_decision_id_idx ON agent_calls (decision_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS agent_calls;
+-- +goose StatementEnd
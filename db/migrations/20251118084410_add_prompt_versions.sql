@@ -0,0 +1,27 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS prompt_versions (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id),
+    name TEXT NOT NULL,
+    version INTEGER NOT NULL,
+    system_template TEXT NOT NULL,
+    user_template TEXT NOT NULL,
+    note TEXT NOT NULL DEFAULT '',
+    active BOOLEAN NOT NULL DEFAULT FALSE,
+    activated_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    UNIQUE (user_id, version)
+);
+
+-- at most one active version per bot
+CREATE UNIQUE INDEX IF NOT EXISTS prompt_versions_active_idx ON prompt_versions (user_id) WHERE active;
+
+ALTER TABLE decisions ADD COLUMN IF NOT EXISTS prompt_version_id INTEGER REFERENCES prompt_versions(id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE decisions DROP COLUMN IF EXISTS prompt_version_id;
+DROP TABLE IF EXISTS prompt_versions;
+-- +goose StatementEnd
@@ -0,0 +1,13 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE decisions ADD COLUMN IF NOT EXISTS confidence NUMERIC NOT NULL DEFAULT 0;
+ALTER TABLE decisions ADD COLUMN IF NOT EXISTS scores JSONB NOT NULL DEFAULT '{}';
+ALTER TABLE decisions ADD COLUMN IF NOT EXISTS rationale TEXT NOT NULL DEFAULT '';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE decisions DROP COLUMN IF EXISTS rationale;
+ALTER TABLE decisions DROP COLUMN IF EXISTS scores;
+ALTER TABLE decisions DROP COLUMN IF EXISTS confidence;
+-- +goose StatementEnd
@@ -0,0 +1,9 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE decisions ADD COLUMN IF NOT EXISTS fraction NUMERIC NOT NULL DEFAULT 0;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE decisions DROP COLUMN IF EXISTS fraction;
+-- +goose StatementEnd
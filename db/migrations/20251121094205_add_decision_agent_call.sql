@@ -0,0 +1,16 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE decisions ADD COLUMN IF NOT EXISTS agent_call_id INTEGER REFERENCES agent_calls(id);
+
+UPDATE decisions d SET agent_call_id = c.id
+FROM agent_calls c
+WHERE c.decision_id = d.id AND d.agent_call_id IS NULL;
+
+CREATE INDEX IF NOT EXISTS decisions_agent_call_id_idx ON decisions (agent_call_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS decisions_agent_call_id_idx;
+ALTER TABLE decisions DROP COLUMN IF EXISTS agent_call_id;
+-- +goose StatementEnd
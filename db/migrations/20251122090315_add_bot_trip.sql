@@ -0,0 +1,11 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE bots ADD COLUMN IF NOT EXISTS tripped_at TIMESTAMP;
+ALTER TABLE bots ADD COLUMN IF NOT EXISTS trip_reason TEXT NOT NULL DEFAULT '';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE bots DROP COLUMN IF EXISTS trip_reason;
+ALTER TABLE bots DROP COLUMN IF EXISTS tripped_at;
+-- +goose StatementEnd
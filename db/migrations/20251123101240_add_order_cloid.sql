@@ -0,0 +1,17 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS cloid TEXT NOT NULL DEFAULT '';
+CREATE UNIQUE INDEX IF NOT EXISTS orders_cloid_idx ON orders (cloid) WHERE cloid <> '';
+
+ALTER TABLE paper_orders ADD COLUMN IF NOT EXISTS cloid TEXT NOT NULL DEFAULT '';
+CREATE INDEX IF NOT EXISTS paper_orders_cloid_idx ON paper_orders (account_id, cloid) WHERE cloid <> '';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS paper_orders_cloid_idx;
+ALTER TABLE paper_orders DROP COLUMN IF EXISTS cloid;
+
+DROP INDEX IF EXISTS orders_cloid_idx;
+ALTER TABLE orders DROP COLUMN IF EXISTS cloid;
+-- +goose StatementEnd
@@ -0,0 +1,16 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS paper_leverage (
+    account_id INTEGER NOT NULL REFERENCES paper_accounts(id),
+    coin TEXT NOT NULL,
+    leverage INTEGER NOT NULL,
+    isolated BOOLEAN NOT NULL DEFAULT FALSE,
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (account_id, coin)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS paper_leverage;
+-- +goose StatementEnd
@@ -0,0 +1,112 @@
+// Package exits holds the exit rules the live bot and the backtest share: how a filled entry is split into
+// take-profit and stop legs, and how large the reduce-only order of a close, reduce, reverse or adjust is.
+package exits
+
+import (
+	"math"
+
+	"deepseek-trader/hyperliquid"
+	"deepseek-trader/models"
+)
+
+// tiers is the prompt's 40/30/30 take-profit ladder; the last placed tier takes the remainder.
+var tiers = []struct {
+	kind  string
+	share float64
+}{
+	{models.OrderKindTP1, 0.4},
+	{models.OrderKindTP2, 0.3},
+	{models.OrderKindTP3, 0.3},
+}
+
+// Kinds are the exit leg kinds in placement order.
+var Kinds = []string{models.OrderKindTP1, models.OrderKindTP2, models.OrderKindTP3, models.OrderKindSL}
+
+// Leg is one reduce-only trigger order protecting a position.
+type Leg struct {
+	Kind  string
+	Price float64
+	Size  float64
+}
+
+// Prices returns the decision's trigger price per leg kind; kinds without a target are zero.
+func Prices(d models.Decision) map[string]float64 {
+	return map[string]float64{
+		models.OrderKindTP1: d.TP1,
+		models.OrderKindTP2: d.TP2,
+		models.OrderKindTP3: d.TP3,
+		models.OrderKindSL:  d.SL,
+	}
+}
+
+// Legs splits a filled entry of size into the decision's take-profit legs and its stop, floored to the
+// coin's size decimals. The last take-profit takes what the others leave; the stop covers the whole size.
+// Legs that floor to zero are left out.
+func Legs(d models.Decision, size float64, decimals int) []Leg {
+	prices := Prices(d)
+
+	var legs []Leg
+	for _, t := range tiers {
+		if prices[t.kind] > 0 {
+			legs = append(legs, Leg{Kind: t.kind, Price: prices[t.kind], Size: hyperliquid.FloorSize(size*t.share, decimals)})
+		}
+	}
+	if len(legs) > 0 {
+		allocated := 0.0
+		for _, l := range legs[:len(legs)-1] {
+			allocated += l.Size
+		}
+		legs[len(legs)-1].Size = hyperliquid.FloorSize(size-allocated, decimals)
+	}
+	if d.SL > 0 {
+		legs = append(legs, Leg{Kind: models.OrderKindSL, Price: d.SL, Size: hyperliquid.FloorSize(size, decimals)})
+	}
+
+	kept := legs[:0]
+	for _, l := range legs {
+		if l.Size > 0 {
+			kept = append(kept, l)
+		}
+	}
+	return kept
+}
+
+// Share is the share of the position a take-profit tier closes; the stop covers all of it.
+func Share(kind string) float64 {
+	for _, t := range tiers {
+		if t.kind == kind {
+			return t.share
+		}
+	}
+	return 1
+}
+
+// AdjustSize is the size of the leg of kind an adjust places on the signed position pos: the size of the
+// resting legs it replaces, or the kind's share of the position when there are none.
+func AdjustSize(kind string, replaced, pos float64, decimals int) float64 {
+	if replaced > 0 {
+		return replaced
+	}
+	return hyperliquid.FloorSize(math.Abs(pos)*Share(kind), decimals)
+}
+
+// CloseSize is the size of the reduce-only order that exits the signed position pos: a reverse closes all
+// of it, a close or reduce the decision's size up to the position.
+func CloseSize(d models.Decision, pos float64) float64 {
+	if d.Action == "reverse" {
+		return math.Abs(pos)
+	}
+	return math.Min(d.Size, math.Abs(pos))
+}
+
+// Remaining is the signed position left once an exit of filled has traded against pos.
+func Remaining(pos, filled float64) float64 {
+	held := pos + filled
+	if pos > 0 {
+		held = pos - filled
+	}
+	if math.Abs(held) < 1e-9 {
+		return 0
+	}
+	return held
+}
@@ -0,0 +1,123 @@
+package exits
+
+import (
+	"math"
+	"reflect"
+	"testing"
+
+	"deepseek-trader/models"
+)
+
+func TestLegs(t *testing.T) {
+	full := models.Decision{TP1: 110, TP2: 120, TP3: 130, SL: 90}
+	tests := []struct {
+		name     string
+		d        models.Decision
+		size     float64
+		decimals int
+		want     []Leg
+	}{
+		{name: "full ladder", d: full, size: 1, decimals: 2, want: []Leg{
+			{Kind: models.OrderKindTP1, Price: 110, Size: 0.4},
+			{Kind: models.OrderKindTP2, Price: 120, Size: 0.3},
+			{Kind: models.OrderKindTP3, Price: 130, Size: 0.3},
+			{Kind: models.OrderKindSL, Price: 90, Size: 1},
+		}},
+		{name: "last tier takes the floored remainder", d: full, size: 0.07, decimals: 2, want: []Leg{
+			{Kind: models.OrderKindTP1, Price: 110, Size: 0.02},
+			{Kind: models.OrderKindTP2, Price: 120, Size: 0.02},
+			{Kind: models.OrderKindTP3, Price: 130, Size: 0.03},
+			{Kind: models.OrderKindSL, Price: 90, Size: 0.07},
+		}},
+		{name: "missing tier moves the remainder up", d: models.Decision{TP1: 110, TP2: 120}, size: 1, decimals: 1, want: []Leg{
+			{Kind: models.OrderKindTP1, Price: 110, Size: 0.4},
+			{Kind: models.OrderKindTP2, Price: 120, Size: 0.6},
+		}},
+		{name: "tiers that floor to zero are dropped", d: full, size: 2, decimals: 0, want: []Leg{
+			{Kind: models.OrderKindTP3, Price: 130, Size: 2},
+			{Kind: models.OrderKindSL, Price: 90, Size: 2},
+		}},
+		{name: "stop only", d: models.Decision{SL: 90}, size: 0.5, decimals: 3, want: []Leg{
+			{Kind: models.OrderKindSL, Price: 90, Size: 0.5},
+		}},
+		{name: "no targets", d: models.Decision{}, size: 1, decimals: 2, want: []Leg{}},
+		{name: "zero size", d: full, size: 0, decimals: 2, want: []Leg{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Legs(tt.d, tt.size, tt.decimals)
+			if len(got) == 0 && len(tt.want) == 0 {
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("Legs = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAdjustSize(t *testing.T) {
+	tests := []struct {
+		name     string
+		kind     string
+		replaced float64
+		pos      float64
+		want     float64
+	}{
+		{name: "keeps the replaced size", kind: models.OrderKindTP1, replaced: 0.25, pos: 1, want: 0.25},
+		{name: "tier share of a long", kind: models.OrderKindTP1, pos: 1, want: 0.4},
+		{name: "tier share of a short", kind: models.OrderKindTP2, pos: -1, want: 0.3},
+		{name: "stop covers the position", kind: models.OrderKindSL, pos: -0.75, want: 0.75},
+		{name: "floored to the decimals", kind: models.OrderKindTP3, pos: 0.05, want: 0.01},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := AdjustSize(tt.kind, tt.replaced, tt.pos, 2); math.Abs(got-tt.want) > 1e-12 {
+				t.Fatalf("AdjustSize = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCloseSize(t *testing.T) {
+	tests := []struct {
+		name   string
+		action string
+		size   float64
+		pos    float64
+		want   float64
+	}{
+		{name: "reduce below the position", action: "reduce", size: 0.5, pos: 2, want: 0.5},
+		{name: "close capped at the position", action: "close", size: 3, pos: -2, want: 2},
+		{name: "reverse closes all of it", action: "reverse", size: 0.5, pos: -2, want: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := CloseSize(models.Decision{Action: tt.action, Size: tt.size}, tt.pos); got != tt.want {
+				t.Fatalf("CloseSize = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRemaining(t *testing.T) {
+	tests := []struct {
+		name   string
+		pos    float64
+		filled float64
+		want   float64
+	}{
+		{name: "long partly closed", pos: 2, filled: 0.5, want: 1.5},
+		{name: "short partly closed", pos: -2, filled: 0.5, want: -1.5},
+		{name: "long fully closed", pos: 0.3, filled: 0.1 + 0.2, want: 0},
+		{name: "short fully closed", pos: -0.3, filled: 0.1 + 0.2, want: 0},
+		{name: "nothing filled", pos: 1, filled: 0, want: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Remaining(tt.pos, tt.filled); math.Abs(got-tt.want) > 1e-12 {
+				t.Fatalf("Remaining = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,97 @@
+package hyperliquid
+
+import (
+	"context"
+	"errors"
+	"strconv"
+)
+
+// AccountState fetches the wallet's clearinghouseState: margin summary and open perp positions.
+func (c *Client) AccountState(ctx context.Context) (AccountState, error) {
+	if c.walletAddress == "" {
+		return AccountState{}, errors.New("wallet address is required")
+	}
+	var out clearinghouseState
+	payload := map[string]any{"type": "clearinghouseState", "user": c.walletAddress}
+	if err := c.postInfo(ctx, payload, &out); err != nil {
+		return AccountState{}, err
+	}
+
+	st := AccountState{
+		AccountValue:      toF(out.MarginSummary.AccountValue),
+		TotalNotional:     toF(out.MarginSummary.TotalNtlPos),
+		MarginUsed:        toF(out.MarginSummary.TotalMarginUsed),
+		MaintenanceMargin: toF(out.CrossMaintenanceMarginUsed),
+		Withdrawable:      toF(out.Withdrawable),
+		Positions:         make([]Position, 0, len(out.AssetPositions)),
+		Time:              out.Time,
+	}
+	for _, ap := range out.AssetPositions {
+		p := ap.Position
+		st.Positions = append(st.Positions, Position{
+			Coin:             p.Coin,
+			Size:             toF(p.Szi),
+			EntryPrice:       toFPtr(p.EntryPx),
+			PositionValue:    toF(p.PositionValue),
+			UnrealizedPnL:    toF(p.UnrealizedPnl),
+			ReturnOnEquity:   toF(p.ReturnOnEquity),
+			LiquidationPrice: toFPtr(p.LiquidationPx),
+			MarginUsed:       toF(p.MarginUsed),
+			Leverage:         p.Leverage.Value,
+			LeverageType:     p.Leverage.Type,
+			MaxLeverage:      p.MaxLeverage,
+			FundingSinceOpen: toF(p.CumFunding.SinceOpen),
+		})
+	}
+	return st, nil
+}
+
+// OpenOrders fetches the wallet's resting orders via frontendOpenOrders, which unlike openOrders
+// also reports order type, reduce-only and trigger details.
+func (c *Client) OpenOrders(ctx context.Context) ([]OpenOrder, error) {
+	if c.walletAddress == "" {
+		return nil, errors.New("wallet address is required")
+	}
+	var out []frontendOpenOrder
+	payload := map[string]any{"type": "frontendOpenOrders", "user": c.walletAddress}
+	if err := c.postInfo(ctx, payload, &out); err != nil {
+		return nil, err
+	}
+
+	orders := make([]OpenOrder, 0, len(out))
+	for _, o := range out {
+		oo := OpenOrder{
+			Coin:       o.Coin,
+			Oid:        o.Oid,
+			IsBuy:      o.Side == "B",
+			LimitPrice: toF(o.LimitPx),
+			Size:       toF(o.Sz),
+			OrigSize:   toF(o.OrigSz),
+			OrderType:  o.OrderType,
+			ReduceOnly: o.ReduceOnly,
+			IsTrigger:  o.IsTrigger,
+			TriggerPx:  toF(o.TriggerPx),
+			Timestamp:  o.Timestamp,
+		}
+		if o.Cloid != nil {
+			oo.Cloid = *o.Cloid
+		}
+		if o.Tif != nil {
+			oo.Tif = *o.Tif
+		}
+		orders = append(orders, oo)
+	}
+	return orders, nil
+}
+
+func toF(s string) float64 {
+	f, _ := strconv.ParseFloat(s, 64)
+	return f
+}
+
+func toFPtr(s *string) float64 {
+	if s == nil {
+		return 0
+	}
+	return toF(*s)
+}
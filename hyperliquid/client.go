@@ -3,6 +3,8 @@ package hyperliquid
 import (
 	"bytes"
 	"context"
+	"crypto/ecdsa"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -10,9 +12,11 @@ import (
 	"net/http"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"deepseek-trader/config"
+	cryptoutil "deepseek-trader/pkg/crypto"
 
 	"github.com/ethereum/go-ethereum/crypto"
 	hl "github.com/sonirico/go-hyperliquid"
@@ -22,7 +26,31 @@ type Client struct {
 	cfg           *config.Settings
 	walletAddress string
 	httpClient    *http.Client
-	ex            *hl.Exchange
+	sig           *signer
+}
+
+// signer is the exchange handle a client signs actions with. Signed calls hold the read lock while they
+// run, so Close, which takes the write lock, waits for calls in flight before it wipes the key. Copies made
+// by ForWallet share it.
+type signer struct {
+	mx sync.RWMutex
+	ex *hl.Exchange
+	// key is set only on signers built by WithSigner, which own it and wipe it in Close.
+	key *ecdsa.PrivateKey
+}
+
+// ErrNoSigner is returned by signed calls on a client without a signing key, or one that was closed.
+var ErrNoSigner = errors.New("exchange client not initialized; set API_SECRET")
+
+// exchange returns the client's exchange handle under the signer's read lock; call release once the
+// signed call is done.
+func (c *Client) exchange() (ex *hl.Exchange, release func(), err error) {
+	c.sig.mx.RLock()
+	if c.sig.ex == nil {
+		c.sig.mx.RUnlock()
+		return nil, nil, ErrNoSigner
+	}
+	return c.sig.ex, c.sig.mx.RUnlock, nil
 }
 
 func NewClient(cfg *config.Settings) *Client {
@@ -30,11 +58,12 @@ func NewClient(cfg *config.Settings) *Client {
 		cfg:           cfg,
 		walletAddress: cfg.APIWallet,
 		httpClient:    &http.Client{Timeout: 10 * time.Second},
+		sig:           &signer{},
 	}
 
 	if cfg.APISecret != "" {
 		if pk, err := crypto.HexToECDSA(cfg.APISecret); err == nil {
-			lc.ex = hl.NewExchange(context.Background(), pk, cfg.HLBaseURL, nil, "", "", nil)
+			lc.sig.ex, _ = newExchange(context.Background(), pk, cfg.HLBaseURL, "")
 		}
 	}
 
@@ -124,29 +153,75 @@ func (c *Client) UserFees(ctx context.Context) (*UserFees, error) {
 	return fees, nil
 }
 
-// PlaceOrder: оформляет лимитный ордер GTC
-func (c *Client) PlaceOrder(ctx context.Context, symbol, side string, qty, price float64) (string, error) {
-	if c.ex == nil {
-		return "", errors.New("exchange client not initialized; set API_SECRET")
-	}
-	coin := normalizeSymbol(symbol)
-	isBuy := strings.EqualFold(side, "BUY")
-	req := hl.CreateOrderRequest{
-		Coin:      coin,
-		IsBuy:     isBuy,
-		Size:      qty,
-		Price:     price,
-		OrderType: hl.OrderType{Limit: &hl.LimitOrderType{Tif: "Gtc"}},
-	}
-	resp, err := c.ex.Order(ctx, req, nil)
+// ForWallet returns a copy of the client bound to address. The copy shares the HTTP client and signer,
+// so it is cheap to create per request or per bot and never changes the address other callers see.
+func (c *Client) ForWallet(address string) *Client {
+	cp := *c
+	cp.walletAddress = address
+	return &cp
+}
+
+// WithSigner returns a copy of the client bound to address that signs with the agent-wallet key in hexKey
+// (hex, optional 0x prefix) on behalf of that account. hexKey is zeroed before returning; call Close on the
+// returned client to wipe the parsed key once it is no longer needed.
+func (c *Client) WithSigner(ctx context.Context, address string, hexKey []byte) (*Client, error) {
+	defer cryptoutil.Zero(hexKey)
+
+	raw := bytes.TrimPrefix(bytes.TrimSpace(hexKey), []byte("0x"))
+	seed := make([]byte, hex.DecodedLen(len(raw)))
+	defer cryptoutil.Zero(seed)
+	if _, err := hex.Decode(seed, raw); err != nil {
+		return nil, errors.New("invalid agent wallet key")
+	}
+	pk, err := crypto.ToECDSA(seed)
 	if err != nil {
-		return "", err
+		return nil, errors.New("invalid agent wallet key")
+	}
+
+	ex, err := newExchange(ctx, pk, c.cfg.HLBaseURL, address)
+	if err != nil {
+		return nil, err
+	}
+
+	cp := *c
+	cp.walletAddress = address
+	cp.sig = &signer{key: pk, ex: ex}
+	return &cp, nil
+}
+
+// newExchange builds the SDK's signing handle for account. NewExchange panics when it cannot fetch the
+// asset meta itself, so the meta is fetched here first and a failure is returned instead.
+func newExchange(ctx context.Context, pk *ecdsa.PrivateKey, baseURL, account string) (*hl.Exchange, error) {
+	info := hl.NewInfo(ctx, baseURL, true, &hl.Meta{}, &hl.SpotMeta{})
+	meta, err := info.Meta(ctx)
+	if err != nil {
+		return nil, err
+	}
+	spotMeta, err := info.SpotMeta(ctx)
+	if err != nil {
+		return nil, err
 	}
-	return strconv.Itoa(resp.Filled.Oid), nil
+	return hl.NewExchange(ctx, pk, baseURL, meta, "", account, spotMeta), nil
 }
 
-func (c *Client) SetWalletAddress(addr string) {
-	c.walletAddress = strings.TrimSpace(addr)
+// Close waits for signed calls in flight, then wipes the signing key of a client built by WithSigner and
+// disables order placement on it. It is a no-op for other clients.
+func (c *Client) Close() {
+	c.sig.mx.Lock()
+	defer c.sig.mx.Unlock()
+	if c.sig.key == nil {
+		return
+	}
+	words := c.sig.key.D.Bits()
+	for i := range words {
+		words[i] = 0
+	}
+	c.sig.key = nil
+	c.sig.ex = nil
+}
+
+func (c *Client) WalletAddress() string {
+	return c.walletAddress
 }
 
 func (c *Client) CoinsMids(ctx context.Context) (map[string]string, error) {
@@ -249,13 +324,14 @@ func (c *Client) L2Book(ctx context.Context, coin string) (OrderBookSnapshot, er
 	return out, nil
 }
 
-func (c *Client) CandleSnapshot(ctx context.Context, coin string, stratTime, endTime int64) ([]Candle, error) {
+// CandleSnapshot fetches a coin's candles of the given interval (e.g. "15m", "4h") in [stratTime, endTime].
+func (c *Client) CandleSnapshot(ctx context.Context, coin, interval string, stratTime, endTime int64) ([]Candle, error) {
 	url := fmt.Sprintf("%s/info", c.cfg.HLBaseURL)
 	payload := CandleSnapshotRequest{
 		Type: "candleSnapshot",
 		Req: RequestBody{
 			Coin:      coin,
-			Interval:  "15m",
+			Interval:  interval,
 			StartTime: stratTime,
 			EndTime:   endTime,
 		},
@@ -289,8 +365,34 @@ func (c *Client) CandleSnapshot(ctx context.Context, coin string, stratTime, end
 	return out, nil
 }
 
-// Вспомогательная нормализация тикера
-func normalizeSymbol(sym string) string {
+// postInfo posts an arbitrary payload to /info and decodes the JSON response into out.
+func (c *Client) postInfo(ctx context.Context, payload, out any) error {
+	url := strings.TrimRight(c.cfg.HLBaseURL, "/") + "/info"
+	b, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(b))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("info request failed with status %d", resp.StatusCode)
+	}
+
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// NormalizeSymbol maps agent symbols like "BTCUSDT" to Hyperliquid coin names ("BTC").
+func NormalizeSymbol(sym string) string {
 	s := strings.ToUpper(strings.TrimSpace(sym))
 	s = strings.TrimSuffix(s, "USDT")
 	return s
@@ -0,0 +1,113 @@
+package hyperliquid
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"deepseek-trader/config"
+)
+
+// testKey is a throwaway agent-wallet key used only against the fake exchange below.
+const testKey = "4c0883a69102937d6231471b5dbb6204fe5129617082792ae468d01a3f362318"
+
+// fakeExchange serves the meta requests NewExchange makes and acknowledges cancels after a short delay,
+// so signed calls are still in flight when a test closes the client.
+func fakeExchange(t *testing.T) *httptest.Server {
+	return fakeExchangeWith(t, func(w http.ResponseWriter, _ *http.Request) {
+		time.Sleep(5 * time.Millisecond)
+		_, _ = w.Write([]byte(`{"status":"ok","response":{"type":"cancel","data":{"statuses":["success"]}}}`))
+	})
+}
+
+// fakeExchangeWith serves the meta requests NewExchange makes and answers signed actions with exchange.
+func fakeExchangeWith(t *testing.T, exchange http.HandlerFunc) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]any
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.URL.Path == "/info" && body["type"] == "meta":
+			_, _ = w.Write([]byte(`{"universe":[{"name":"BTC","szDecimals":5,"maxLeverage":40}],"marginTables":[]}`))
+		case r.URL.Path == "/info" && body["type"] == "spotMeta":
+			_, _ = w.Write([]byte(`{"universe":[],"tokens":[]}`))
+		case r.URL.Path == "/exchange":
+			exchange(w, r)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestCloseWaitsForSignedCalls(t *testing.T) {
+	srv := fakeExchange(t)
+	base := NewClient(&config.Settings{HLBaseURL: srv.URL})
+	c, err := base.WithSigner(context.Background(), "0x0000000000000000000000000000000000000001", []byte(testKey))
+	if err != nil {
+		t.Fatalf("WithSigner: %v", err)
+	}
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 16)
+	for i := 0; i < cap(errs); i++ {
+		wg.Add(1)
+		go func(oid int64) {
+			defer wg.Done()
+			errs <- c.CancelOrder(context.Background(), "BTC", oid)
+		}(int64(i))
+	}
+	time.Sleep(2 * time.Millisecond)
+	c.Close()
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil && !errors.Is(err, ErrNoSigner) {
+			t.Errorf("cancel during close: %v", err)
+		}
+	}
+	if err := c.CancelOrder(context.Background(), "BTC", 1); !errors.Is(err, ErrNoSigner) {
+		t.Errorf("cancel after close = %v, want ErrNoSigner", err)
+	}
+	if c.sig.key != nil || c.sig.ex != nil {
+		t.Error("Close left the signer set")
+	}
+}
+
+func TestSignedCallsWithoutKey(t *testing.T) {
+	c := NewClient(&config.Settings{}).ForWallet("0x0000000000000000000000000000000000000002")
+	c.Close()
+	if err := c.CancelOrder(context.Background(), "BTC", 1); !errors.Is(err, ErrNoSigner) {
+		t.Errorf("CancelOrder = %v, want ErrNoSigner", err)
+	}
+	if _, err := c.CancelAll(context.Background(), ""); !errors.Is(err, ErrNoSigner) {
+		t.Errorf("CancelAll = %v, want ErrNoSigner", err)
+	}
+	if err := c.UpdateLeverage(context.Background(), "BTC", 3, false); !errors.Is(err, ErrNoSigner) {
+		t.Errorf("UpdateLeverage = %v, want ErrNoSigner", err)
+	}
+}
+
+func TestWithSignerMetaUnavailable(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	base := NewClient(&config.Settings{HLBaseURL: srv.URL})
+	c, err := base.WithSigner(context.Background(), "0x0000000000000000000000000000000000000001", []byte(testKey))
+	if err == nil {
+		t.Fatal("WithSigner succeeded without meta")
+	}
+	if c != nil {
+		t.Error("WithSigner returned a client along with the error")
+	}
+}
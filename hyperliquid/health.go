@@ -0,0 +1,125 @@
+package hyperliquid
+
+import "math"
+
+const (
+	HealthOK      = "ok"
+	HealthWarning = "warning"
+	HealthDanger  = "danger"
+)
+
+// PositionHealth is how close an open position is to liquidation.
+type PositionHealth struct {
+	Coin             string  `json:"coin"`
+	Size             float64 `json:"size"`
+	MarkPrice        float64 `json:"markPrice"`
+	LiquidationPrice float64 `json:"liquidationPrice"`
+	// Distance is the move from the mark to the liquidation price as a fraction of the mark; 1 when the
+	// position cannot be liquidated.
+	Distance          float64 `json:"distance"`
+	MaintenanceMargin float64 `json:"maintenanceMargin"`
+	Isolated          bool    `json:"isolated"`
+	Level             string  `json:"level"`
+}
+
+// MarginHealth is the account's distance to liquidation. Level is the worst level of its positions.
+type MarginHealth struct {
+	AccountValue      float64 `json:"accountValue"`
+	MaintenanceMargin float64 `json:"maintenanceMargin"`
+	// MarginRatio is the cross maintenance margin over account value; cross positions are liquidated at 1.
+	MarginRatio float64          `json:"marginRatio"`
+	Level       string           `json:"level"`
+	Positions   []PositionHealth `json:"positions"`
+	Time        int64            `json:"time"`
+}
+
+// HealthOf computes the margin health of an account. Maintenance margin comes from the coins' margin tiers
+// and the distance from the exchange's liquidation price. A position it reports none for is out of reach
+// (distance 1) unless estimate is set, when the price is estimated from the margin available to the
+// position; only a simulation without an exchange should estimate, since paper accounts are never
+// liquidated. Positions within warn (danger) of their liquidation price, as a fraction of the mark, are at
+// warning (danger) level.
+func (m ExchangeMeta) HealthOf(st AccountState, warn, danger float64, estimate bool) MarginHealth {
+	h := MarginHealth{AccountValue: st.AccountValue, Level: HealthOK, Time: st.Time}
+	open := make([]Position, 0, len(st.Positions))
+	for _, p := range st.Positions {
+		if p.Size == 0 {
+			continue
+		}
+		ph := PositionHealth{Coin: p.Coin, Size: p.Size, Isolated: p.LeverageType == "isolated", Distance: 1}
+		ph.MarkPrice = p.PositionValue / math.Abs(p.Size)
+		ph.MaintenanceMargin, _ = m.MaintenanceMargin(p.Coin, p.PositionValue)
+		if !ph.Isolated {
+			h.MaintenanceMargin += ph.MaintenanceMargin
+		}
+		h.Positions = append(h.Positions, ph)
+		open = append(open, p)
+	}
+	if h.AccountValue > 0 {
+		h.MarginRatio = h.MaintenanceMargin / h.AccountValue
+	}
+
+	for i := range h.Positions {
+		ph := &h.Positions[i]
+		ph.LiquidationPrice = open[i].LiquidationPrice
+		if ph.LiquidationPrice <= 0 && estimate {
+			available := h.AccountValue - h.MaintenanceMargin
+			if ph.Isolated {
+				available = open[i].MarginUsed - ph.MaintenanceMargin
+			}
+			ph.LiquidationPrice = liquidationPrice(ph.Size, ph.MarkPrice, ph.MaintenanceMargin, available)
+		}
+		if ph.LiquidationPrice > 0 && ph.MarkPrice > 0 {
+			ph.Distance = math.Abs(ph.MarkPrice-ph.LiquidationPrice) / ph.MarkPrice
+		}
+		ph.Level = healthLevel(ph.Distance, warn, danger)
+		if severity(ph.Level) > severity(h.Level) {
+			h.Level = ph.Level
+		}
+	}
+	return h
+}
+
+// liquidationPrice is the price at which the margin available to a position is used up:
+// mark - side * available / |size| / (1 - side * mmr), zero when no price gets there and the mark when
+// the margin is already used up.
+func liquidationPrice(size, mark, maintenance, available float64) float64 {
+	if mark <= 0 {
+		return 0
+	}
+	if available <= 0 {
+		return mark
+	}
+	side := 1.0
+	if size < 0 {
+		side = -1
+	}
+	mmr := maintenance / (math.Abs(size) * mark)
+	px := mark - side*available/math.Abs(size)/(1-side*mmr)
+	if px <= 0 {
+		return 0
+	}
+	return px
+}
+
+func healthLevel(distance, warn, danger float64) string {
+	switch {
+	case distance <= danger:
+		return HealthDanger
+	case distance <= warn:
+		return HealthWarning
+	default:
+		return HealthOK
+	}
+}
+
+func severity(level string) int {
+	switch level {
+	case HealthDanger:
+		return 2
+	case HealthWarning:
+		return 1
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,86 @@
+package hyperliquid
+
+import (
+	"math"
+	"testing"
+)
+
+func TestLiquidationPrice(t *testing.T) {
+	tests := []struct {
+		name                               string
+		size, mark, maintenance, available float64
+		want                               float64
+	}{
+		{name: "long", size: 1, mark: 100, maintenance: 1, available: 9, want: 100 - 9/0.99},
+		{name: "short", size: -1, mark: 100, maintenance: 1, available: 9, want: 100 + 9/1.01},
+		{name: "larger long", size: 2, mark: 100, maintenance: 2, available: 18, want: 100 - 9/0.99},
+		{name: "margin used up", size: 1, mark: 100, maintenance: 1, available: 0, want: 100},
+		{name: "long out of reach", size: 1, mark: 100, maintenance: 1, available: 200},
+		{name: "no mark", size: 1, maintenance: 1, available: 9},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := liquidationPrice(tt.size, tt.mark, tt.maintenance, tt.available); math.Abs(got-tt.want) > 1e-9 {
+				t.Fatalf("liquidationPrice = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHealthOf(t *testing.T) {
+	// A BTC position worth 5000 is in the first tier: maintenance margin 1%, 50.
+	btc := Position{Coin: "BTC", Size: 1, PositionValue: 5000, MarginUsed: 500, LeverageType: "cross"}
+	withLiq := func(p Position, px float64) Position { p.LiquidationPrice = px; return p }
+	isolated := func(p Position) Position { p.LeverageType = "isolated"; return p }
+	short := func(p Position) Position { p.Size = -p.Size; return p }
+
+	tests := []struct {
+		name      string
+		value     float64
+		positions []Position
+		estimate  bool
+		wantLiq   []float64
+		wantLevel []string
+		wantRatio float64
+		wantAcct  string
+	}{
+		{name: "exchange liquidation price", value: 1000, positions: []Position{withLiq(btc, 4500)},
+			wantLiq: []float64{4500}, wantLevel: []string{HealthWarning}, wantRatio: 0.05, wantAcct: HealthWarning},
+		{name: "exchange price wins over the estimate", value: 1000, positions: []Position{withLiq(btc, 4900)}, estimate: true,
+			wantLiq: []float64{4900}, wantLevel: []string{HealthDanger}, wantRatio: 0.05, wantAcct: HealthDanger},
+		{name: "none reported is out of reach", value: 1000, positions: []Position{btc},
+			wantLiq: []float64{0}, wantLevel: []string{HealthOK}, wantRatio: 0.05, wantAcct: HealthOK},
+		{name: "paper short without a price", value: 100, positions: []Position{short(btc)},
+			wantLiq: []float64{0}, wantLevel: []string{HealthOK}, wantRatio: 0.5, wantAcct: HealthOK},
+		{name: "cross estimate from the account value", value: 1000, positions: []Position{btc}, estimate: true,
+			wantLiq: []float64{5000 - 950/0.99}, wantLevel: []string{HealthOK}, wantRatio: 0.05, wantAcct: HealthOK},
+		{name: "isolated estimate from the position margin", value: 1000, positions: []Position{isolated(btc)}, estimate: true,
+			wantLiq: []float64{5000 - 450/0.99}, wantLevel: []string{HealthWarning}, wantAcct: HealthWarning},
+		{name: "short estimate", value: 1000, positions: []Position{short(btc)}, estimate: true,
+			wantLiq: []float64{5000 + 950/1.01}, wantLevel: []string{HealthOK}, wantRatio: 0.05, wantAcct: HealthOK},
+		{name: "account under water", value: 40, positions: []Position{btc}, estimate: true,
+			wantLiq: []float64{5000}, wantLevel: []string{HealthDanger}, wantRatio: 1.25, wantAcct: HealthDanger},
+		{name: "worst position sets the level", value: 1000,
+			positions: []Position{withLiq(btc, 1000), {Coin: "ETH", Size: 0}, withLiq(short(btc), 5100)},
+			wantLiq:   []float64{1000, 5100}, wantLevel: []string{HealthOK, HealthDanger}, wantRatio: 0.1, wantAcct: HealthDanger},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := testMeta().HealthOf(AccountState{AccountValue: tt.value, Positions: tt.positions}, 0.15, 0.05, tt.estimate)
+			if len(h.Positions) != len(tt.wantLiq) {
+				t.Fatalf("positions = %+v, want %d", h.Positions, len(tt.wantLiq))
+			}
+			for i, p := range h.Positions {
+				if math.Abs(p.LiquidationPrice-tt.wantLiq[i]) > 1e-9 || p.Level != tt.wantLevel[i] {
+					t.Errorf("position %d = %+v, want liquidation %v at %s", i, p, tt.wantLiq[i], tt.wantLevel[i])
+				}
+				if p.LiquidationPrice == 0 && p.Distance != 1 {
+					t.Errorf("position %d distance = %v, want 1 without a liquidation price", i, p.Distance)
+				}
+			}
+			if math.Abs(h.MarginRatio-tt.wantRatio) > 1e-9 || h.Level != tt.wantAcct {
+				t.Errorf("account = ratio %v at %s, want %v at %s", h.MarginRatio, h.Level, tt.wantRatio, tt.wantAcct)
+			}
+		})
+	}
+}
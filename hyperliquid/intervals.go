@@ -0,0 +1,27 @@
+package hyperliquid
+
+import "time"
+
+// intervals are the candle intervals accepted by candleSnapshot and the candle subscription.
+var intervals = map[string]time.Duration{
+	"1m":  time.Minute,
+	"3m":  3 * time.Minute,
+	"5m":  5 * time.Minute,
+	"15m": 15 * time.Minute,
+	"30m": 30 * time.Minute,
+	"1h":  time.Hour,
+	"2h":  2 * time.Hour,
+	"4h":  4 * time.Hour,
+	"8h":  8 * time.Hour,
+	"12h": 12 * time.Hour,
+	"1d":  24 * time.Hour,
+	"3d":  3 * 24 * time.Hour,
+	"1w":  7 * 24 * time.Hour,
+	"1M":  30 * 24 * time.Hour,
+}
+
+// IntervalDuration returns the bar length of a Hyperliquid candle interval; "1M" counts as 30 days.
+func IntervalDuration(interval string) (time.Duration, bool) {
+	d, ok := intervals[interval]
+	return d, ok
+}
@@ -0,0 +1,110 @@
+package hyperliquid
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+)
+
+// ErrLeverageRejected is returned when the exchange did not apply a leverage or isolated margin update.
+// The SDK does not surface refusals of these actions, so the result is read back after sending them.
+var ErrLeverageRejected = errors.New("leverage update rejected by exchange")
+
+// ActiveAsset fetches the wallet's leverage setting and tradable sizes on coin via activeAssetData.
+func (c *Client) ActiveAsset(ctx context.Context, coin string) (AssetLeverage, error) {
+	if c.walletAddress == "" {
+		return AssetLeverage{}, errors.New("wallet address is required")
+	}
+	var out activeAssetData
+	payload := map[string]any{"type": "activeAssetData", "user": c.walletAddress, "coin": NormalizeSymbol(coin)}
+	if err := c.postInfo(ctx, payload, &out); err != nil {
+		return AssetLeverage{}, err
+	}
+
+	al := AssetLeverage{
+		Coin:         out.Coin,
+		Leverage:     out.Leverage.Value,
+		LeverageType: out.Leverage.Type,
+		MarkPrice:    toF(out.MarkPx),
+	}
+	for i := 0; i < 2 && i < len(out.MaxTradeSzs); i++ {
+		al.MaxTradeSizes[i] = toF(out.MaxTradeSzs[i])
+	}
+	for i := 0; i < 2 && i < len(out.AvailableToTrade); i++ {
+		al.AvailableToTrade[i] = toF(out.AvailableToTrade[i])
+	}
+	return al, nil
+}
+
+// UpdateLeverage sets the leverage and margin mode the account uses on coin and checks that the exchange
+// applied it.
+func (c *Client) UpdateLeverage(ctx context.Context, coin string, leverage int, isolated bool) error {
+	ex, release, err := c.exchange()
+	if err != nil {
+		return err
+	}
+	defer release()
+	coin = NormalizeSymbol(coin)
+	if _, err := ex.UpdateLeverage(ctx, leverage, coin, !isolated); err != nil {
+		return err
+	}
+
+	got, err := c.ActiveAsset(ctx, coin)
+	if err != nil {
+		return fmt.Errorf("failed to confirm leverage: %w", err)
+	}
+	want := "cross"
+	if isolated {
+		want = "isolated"
+	}
+	if got.Leverage != leverage || got.LeverageType != want {
+		return fmt.Errorf("%w: %s is at %dx %s", ErrLeverageRejected, coin, got.Leverage, got.LeverageType)
+	}
+	return nil
+}
+
+// UpdateIsolatedMargin adds amount USD of margin to the isolated position in coin, or removes it when
+// amount is negative, and checks that the position's margin changed.
+func (c *Client) UpdateIsolatedMargin(ctx context.Context, coin string, amount float64) error {
+	ex, release, err := c.exchange()
+	if err != nil {
+		return err
+	}
+	defer release()
+	coin = NormalizeSymbol(coin)
+	before, err := c.isolatedPosition(ctx, coin)
+	if err != nil {
+		return err
+	}
+	if _, err := ex.UpdateIsolatedMargin(ctx, amount, coin); err != nil {
+		return err
+	}
+
+	after, err := c.isolatedPosition(ctx, coin)
+	if err != nil {
+		return fmt.Errorf("failed to confirm margin: %w", err)
+	}
+	if math.Abs(after.MarginUsed-before.MarginUsed) < math.Abs(amount)/2 {
+		return fmt.Errorf("%w: %s margin is %.2f USD", ErrLeverageRejected, coin, after.MarginUsed)
+	}
+	return nil
+}
+
+// isolatedPosition returns the open isolated position in coin.
+func (c *Client) isolatedPosition(ctx context.Context, coin string) (Position, error) {
+	st, err := c.AccountState(ctx)
+	if err != nil {
+		return Position{}, err
+	}
+	for _, p := range st.Positions {
+		if p.Coin != coin {
+			continue
+		}
+		if p.LeverageType != "isolated" {
+			return Position{}, fmt.Errorf("%s position uses %s margin", coin, p.LeverageType)
+		}
+		return p, nil
+	}
+	return Position{}, fmt.Errorf("no open %s position", coin)
+}
@@ -0,0 +1,154 @@
+package hyperliquid
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+)
+
+// MarginCheck is the margin requirement of a position at a leverage, as validated by CheckPosition.
+type MarginCheck struct {
+	Coin              string  `json:"coin"`
+	Notional          float64 `json:"notional"`
+	Leverage          int     `json:"leverage"`
+	MaxLeverage       int     `json:"maxLeverage"`
+	Isolated          bool    `json:"isolated"`
+	InitialMargin     float64 `json:"initialMargin"`
+	MaintenanceMargin float64 `json:"maintenanceMargin"`
+}
+
+// IsolatedOnly reports whether the instrument can only be traded with isolated margin.
+func (in Instrument) IsolatedOnly() bool {
+	return in.OnlyIsolated || in.MarginMode == "strictIsolated" || in.MarginMode == "noCross"
+}
+
+// MarginTable looks up a margin table by id.
+func (m ExchangeMeta) MarginTable(id int) (MarginTable, bool) {
+	for _, e := range m.MarginTables {
+		if e.ID == id {
+			return e.Table, true
+		}
+	}
+	return MarginTable{}, false
+}
+
+// Tiers returns the coin's margin tiers by ascending lower bound. Tables that are not listed in the meta
+// (ids below 50 stand for a single tier at that leverage) yield one tier at the instrument's max leverage.
+func (m ExchangeMeta) Tiers(coin string) ([]MarginTier, error) {
+	in, ok := m.Instrument(coin)
+	if !ok {
+		return nil, fmt.Errorf("unknown coin %s", NormalizeSymbol(coin))
+	}
+	t, ok := m.MarginTable(in.MarginTableID)
+	if !ok || len(t.MarginTiers) == 0 {
+		return []MarginTier{{LowerBound: "0.0", MaxLeverage: in.MaxLeverage}}, nil
+	}
+	tiers := append([]MarginTier(nil), t.MarginTiers...)
+	sort.SliceStable(tiers, func(i, j int) bool { return lowerBound(tiers[i]) < lowerBound(tiers[j]) })
+	return tiers, nil
+}
+
+// MaxLeverageAt returns the highest leverage a position of notional USD in coin may use: the max leverage of
+// the tier the notional falls in, capped by the instrument's.
+func (m ExchangeMeta) MaxLeverageAt(coin string, notional float64) (int, error) {
+	if err := checkNotional(notional); err != nil {
+		return 0, err
+	}
+	tiers, err := m.Tiers(coin)
+	if err != nil {
+		return 0, err
+	}
+	in, _ := m.Instrument(coin)
+	lev := tierAt(tiers, math.Abs(notional)).MaxLeverage
+	if in.MaxLeverage > 0 && in.MaxLeverage < lev {
+		lev = in.MaxLeverage
+	}
+	return lev, nil
+}
+
+// MaintenanceMargin returns the maintenance margin of a position of notional USD in coin. A tier's rate is
+// half its initial margin at max leverage; each tier deducts what keeps the requirement continuous at its bound.
+func (m ExchangeMeta) MaintenanceMargin(coin string, notional float64) (float64, error) {
+	if err := checkNotional(notional); err != nil {
+		return 0, err
+	}
+	tiers, err := m.Tiers(coin)
+	if err != nil {
+		return 0, err
+	}
+	notional = math.Abs(notional)
+	rate, deduction := 0.0, 0.0
+	for _, t := range tiers {
+		if t.MaxLeverage <= 0 || lowerBound(t) > notional {
+			break
+		}
+		next := 1 / (2 * float64(t.MaxLeverage))
+		deduction += lowerBound(t) * (next - rate)
+		rate = next
+	}
+	return math.Max(notional*rate-deduction, 0), nil
+}
+
+// CheckPosition validates a position of notional USD in coin at the given leverage and margin mode against
+// the coin's margin tier and returns its margin requirements. It fails for unknown or delisted coins, cross
+// margin on isolated-only coins and leverage outside 1 and the tier's maximum.
+func (m ExchangeMeta) CheckPosition(coin string, notional float64, leverage int, isolated bool) (MarginCheck, error) {
+	coin = NormalizeSymbol(coin)
+	in, ok := m.Instrument(coin)
+	switch {
+	case !ok:
+		return MarginCheck{}, fmt.Errorf("unknown coin %s", coin)
+	case in.IsDelisted:
+		return MarginCheck{}, fmt.Errorf("%s is delisted", coin)
+	case !isolated && in.IsolatedOnly():
+		return MarginCheck{}, fmt.Errorf("%s can only be traded with isolated margin", coin)
+	}
+
+	notional = math.Abs(notional)
+	maxLev, err := m.MaxLeverageAt(coin, notional)
+	if err != nil {
+		return MarginCheck{}, err
+	}
+	if leverage < 1 || leverage > maxLev {
+		return MarginCheck{}, fmt.Errorf("%dx leverage is outside 1-%dx allowed for a %.2f USD %s position", leverage, maxLev, notional, coin)
+	}
+	mm, err := m.MaintenanceMargin(coin, notional)
+	if err != nil {
+		return MarginCheck{}, err
+	}
+	return MarginCheck{
+		Coin:              coin,
+		Notional:          notional,
+		Leverage:          leverage,
+		MaxLeverage:       maxLev,
+		Isolated:          isolated,
+		InitialMargin:     notional / float64(leverage),
+		MaintenanceMargin: mm,
+	}, nil
+}
+
+// checkNotional rejects NaN and infinite notionals, which would otherwise fall into the last tier.
+func checkNotional(notional float64) error {
+	if math.IsNaN(notional) || math.IsInf(notional, 0) {
+		return fmt.Errorf("notional must be a finite number, got %v", notional)
+	}
+	return nil
+}
+
+// tierAt returns the last tier whose lower bound is at most notional; tiers must be sorted.
+func tierAt(tiers []MarginTier, notional float64) MarginTier {
+	t := tiers[0]
+	for _, tier := range tiers[1:] {
+		if lowerBound(tier) > notional {
+			break
+		}
+		t = tier
+	}
+	return t
+}
+
+func lowerBound(t MarginTier) float64 {
+	f, _ := strconv.ParseFloat(t.LowerBound, 64)
+	return f
+}
@@ -0,0 +1,160 @@
+package hyperliquid
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+// testMeta has BTC on a three-tier table capped at 40x by the instrument, ETH on an unlisted table and a
+// few coins whose margin mode or listing limits them.
+func testMeta() ExchangeMeta {
+	return ExchangeMeta{
+		Universe: []Instrument{
+			{Name: "BTC", MaxLeverage: 40, MarginTableID: 56},
+			{Name: "ETH", MaxLeverage: 25, MarginTableID: 25},
+			{Name: "ISO", MaxLeverage: 10, OnlyIsolated: true},
+			{Name: "STRICT", MaxLeverage: 10, MarginMode: "strictIsolated"},
+			{Name: "NOX", MaxLeverage: 10, MarginMode: "noCross"},
+			{Name: "OLD", MaxLeverage: 10, IsDelisted: true},
+		},
+		MarginTables: []MarginTableEntry{{ID: 56, Table: MarginTable{MarginTiers: []MarginTier{
+			{LowerBound: "100000.0", MaxLeverage: 10}, // out of order on purpose; Tiers sorts them
+			{LowerBound: "0.0", MaxLeverage: 50},
+			{LowerBound: "10000.0", MaxLeverage: 20},
+		}}}},
+	}
+}
+
+func TestMaxLeverageAt(t *testing.T) {
+	tests := []struct {
+		name     string
+		coin     string
+		notional float64
+		want     int
+		wantErr  string
+	}{
+		{name: "first tier capped by the instrument", coin: "BTC", notional: 0, want: 40},
+		{name: "just below the second tier", coin: "BTC", notional: 9999.99, want: 40},
+		{name: "at the second tier bound", coin: "BTC", notional: 10000, want: 20},
+		{name: "at the third tier bound", coin: "BTC", notional: 100000, want: 10},
+		{name: "beyond the last bound", coin: "BTC", notional: 1e9, want: 10},
+		{name: "short notional", coin: "BTC", notional: -50000, want: 20},
+		{name: "pair symbol", coin: "BTCUSDT", notional: 50000, want: 20},
+		{name: "unlisted table", coin: "ETH", notional: 1e6, want: 25},
+		{name: "unknown coin", coin: "FOO", notional: 1, wantErr: "unknown coin FOO"},
+		{name: "NaN notional", coin: "BTC", notional: math.NaN(), wantErr: "finite"},
+		{name: "infinite notional", coin: "BTC", notional: math.Inf(1), wantErr: "finite"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := testMeta().MaxLeverageAt(tt.coin, tt.notional)
+			checkErr(t, err, tt.wantErr)
+			if tt.wantErr == "" && got != tt.want {
+				t.Fatalf("MaxLeverageAt = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMaintenanceMargin(t *testing.T) {
+	// BTC rates are 1% below 10k, 2.5% below 100k and 5% above, with deductions of 150 and 2650.
+	tests := []struct {
+		name     string
+		coin     string
+		notional float64
+		want     float64
+		wantErr  string
+	}{
+		{name: "flat", coin: "BTC", notional: 0, want: 0},
+		{name: "first tier", coin: "BTC", notional: 5000, want: 50},
+		{name: "continuous at the second bound", coin: "BTC", notional: 10000, want: 100},
+		{name: "second tier", coin: "BTC", notional: 50000, want: 1100},
+		{name: "continuous at the third bound", coin: "BTC", notional: 100000, want: 2350},
+		{name: "third tier", coin: "BTC", notional: 200000, want: 7350},
+		{name: "short notional", coin: "BTC", notional: -5000, want: 50},
+		{name: "unlisted table", coin: "ETH", notional: 10000, want: 200},
+		{name: "unknown coin", coin: "FOO", notional: 1, wantErr: "unknown coin"},
+		{name: "NaN notional", coin: "BTC", notional: math.NaN(), wantErr: "finite"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := testMeta().MaintenanceMargin(tt.coin, tt.notional)
+			checkErr(t, err, tt.wantErr)
+			if tt.wantErr == "" && math.Abs(got-tt.want) > 1e-6 {
+				t.Fatalf("MaintenanceMargin = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckPosition(t *testing.T) {
+	tests := []struct {
+		name     string
+		coin     string
+		notional float64
+		leverage int
+		isolated bool
+		want     MarginCheck
+		wantErr  string
+	}{
+		{
+			name: "cross at the tier maximum", coin: "BTC", notional: 10000, leverage: 20,
+			want: MarginCheck{Coin: "BTC", Notional: 10000, Leverage: 20, MaxLeverage: 20, InitialMargin: 500, MaintenanceMargin: 100},
+		},
+		{name: "above the tier maximum at its bound", coin: "BTC", notional: 10000, leverage: 21, wantErr: "outside 1-20x"},
+		{
+			name: "just below the bound", coin: "BTC", notional: 9999, leverage: 40, isolated: true,
+			want: MarginCheck{Coin: "BTC", Notional: 9999, Leverage: 40, MaxLeverage: 40, Isolated: true, InitialMargin: 249.975, MaintenanceMargin: 99.99},
+		},
+		{
+			name: "short notional", coin: "BTCUSDT", notional: -5000, leverage: 1,
+			want: MarginCheck{Coin: "BTC", Notional: 5000, Leverage: 1, MaxLeverage: 40, InitialMargin: 5000, MaintenanceMargin: 50},
+		},
+		{
+			name: "zero notional", coin: "BTC", notional: 0, leverage: 5,
+			want: MarginCheck{Coin: "BTC", Leverage: 5, MaxLeverage: 40},
+		},
+		{name: "zero leverage", coin: "BTC", notional: 1000, leverage: 0, wantErr: "outside 1-40x"},
+		{name: "negative leverage", coin: "BTC", notional: 1000, leverage: -3, wantErr: "outside 1-40x"},
+		{name: "unknown coin", coin: "FOO", notional: 1000, leverage: 1, wantErr: "unknown coin FOO"},
+		{name: "delisted", coin: "OLD", notional: 1000, leverage: 1, isolated: true, wantErr: "OLD is delisted"},
+		{name: "cross on an isolated-only coin", coin: "ISO", notional: 1000, leverage: 1, wantErr: "only be traded with isolated margin"},
+		{name: "cross on a strict isolated coin", coin: "STRICT", notional: 1000, leverage: 1, wantErr: "only be traded with isolated margin"},
+		{name: "cross on a no-cross coin", coin: "NOX", notional: 1000, leverage: 1, wantErr: "only be traded with isolated margin"},
+		{
+			name: "isolated on an isolated-only coin", coin: "ISO", notional: 1000, leverage: 10, isolated: true,
+			want: MarginCheck{Coin: "ISO", Notional: 1000, Leverage: 10, MaxLeverage: 10, Isolated: true, InitialMargin: 100, MaintenanceMargin: 50},
+		},
+		{name: "NaN notional", coin: "BTC", notional: math.NaN(), leverage: 1, wantErr: "finite"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := testMeta().CheckPosition(tt.coin, tt.notional, tt.leverage, tt.isolated)
+			checkErr(t, err, tt.wantErr)
+			if tt.wantErr != "" {
+				return
+			}
+			if math.Abs(got.InitialMargin-tt.want.InitialMargin) > 1e-6 || math.Abs(got.MaintenanceMargin-tt.want.MaintenanceMargin) > 1e-6 {
+				t.Fatalf("CheckPosition = %+v, want %+v", got, tt.want)
+			}
+			got.InitialMargin, got.MaintenanceMargin = tt.want.InitialMargin, tt.want.MaintenanceMargin
+			if got != tt.want {
+				t.Fatalf("CheckPosition = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func checkErr(t *testing.T, err error, want string) {
+	t.Helper()
+	if want == "" {
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return
+	}
+	if err == nil || !strings.Contains(err.Error(), want) {
+		t.Fatalf("error = %v, want it to mention %q", err, want)
+	}
+}
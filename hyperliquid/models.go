@@ -48,6 +48,9 @@ type UserFill struct {
 	StartPosition string `json:"startPosition"`
 	Dir           string `json:"dir"`
 	ClosedPnl     string `json:"closedPnl"`
+	Oid           int64  `json:"oid"`
+	Tid           int64  `json:"tid"`
+	Fee           string `json:"fee"`
 }
 
 // ExchangeMeta represents the full JSON structure with instruments and margin tables.
@@ -142,3 +145,226 @@ type RequestBody struct {
 	StartTime int64  `json:"startTime"`
 	EndTime   int64  `json:"endTime"`
 }
+
+const (
+	OrderStatusFilled  = "filled"
+	OrderStatusResting = "resting"
+	OrderStatusError   = "error"
+)
+
+// Time in force of limit orders: GTC rests until filled or canceled, IOC fills what it can immediately and
+// drops the rest, ALO (post-only) is rejected if it would take liquidity.
+const (
+	TifGtc = "Gtc"
+	TifIoc = "Ioc"
+	TifAlo = "Alo"
+)
+
+// OrderRequest is an exchange-agnostic order ticket. Coin accepts either "BTC" or "BTCUSDT".
+// For market orders Price is an optional reference price used for the slippage bound (mid when zero).
+type OrderRequest struct {
+	Coin       string
+	IsBuy      bool
+	Size       float64
+	Price      float64
+	Market     bool
+	ReduceOnly bool
+	// Tif is the limit order's time in force, GTC when empty; market orders are always IOC.
+	Tif string
+	// Slippage bounds a market order's price away from the reference price, DefaultSlippage when zero.
+	Slippage float64
+	// Cloid is an optional client order id: 16 bytes as 32 hex characters, with or without 0x.
+	Cloid string
+}
+
+// OrderResult is the outcome of a single order placement.
+type OrderResult struct {
+	Oid        int64
+	Cloid      string
+	Status     string // filled|resting|error
+	FilledSize float64
+	AvgPrice   float64
+	Error      string
+}
+
+// ModifyRequest replaces a resting order, identified by Oid or Cloid, with Order.
+type ModifyRequest struct {
+	Oid   int64
+	Cloid string
+	Order OrderRequest
+}
+
+const (
+	TriggerTakeProfit = "tp"
+	TriggerStopLoss   = "sl"
+)
+
+// TriggerOrderRequest is a reduce-only market trigger order (take-profit or stop-loss).
+type TriggerOrderRequest struct {
+	Coin      string
+	IsBuy     bool
+	Size      float64
+	TriggerPx float64
+	Tpsl      string // tp|sl
+	Cloid     string
+}
+
+// OrderQuery is the current state of a single order by oid.
+// Status is the raw Hyperliquid order status (open, filled, canceled, triggered, ...);
+// it is "unknownOid" when the exchange does not know the order.
+type OrderQuery struct {
+	Status          string       `json:"status"`
+	StatusTimestamp int64        `json:"statusTimestamp"`
+	Order           QueriedOrder `json:"order"`
+}
+
+type QueriedOrder struct {
+	Coin      string `json:"coin"`
+	Side      string `json:"side"`
+	LimitPx   string `json:"limitPx"`
+	Sz        string `json:"sz"`
+	OrigSz    string `json:"origSz"`
+	Oid       int64  `json:"oid"`
+	Cloid     string `json:"cloid,omitempty"`
+	IsTrigger bool   `json:"isTrigger"`
+	TriggerPx string `json:"triggerPx"`
+	Timestamp int64  `json:"timestamp"`
+}
+
+type orderStatusResponse struct {
+	Status string     `json:"status"`
+	Order  OrderQuery `json:"order"`
+}
+
+// Instrument looks up a perp by coin name ("BTC" or "BTCUSDT").
+func (m ExchangeMeta) Instrument(coin string) (Instrument, bool) {
+	coin = NormalizeSymbol(coin)
+	for _, in := range m.Universe {
+		if in.Name == coin {
+			return in, true
+		}
+	}
+	return Instrument{}, false
+}
+
+// AccountState is a perp account's margin summary and open positions, parsed from clearinghouseState.
+type AccountState struct {
+	AccountValue      float64    `json:"accountValue"`
+	TotalNotional     float64    `json:"totalNotional"`
+	MarginUsed        float64    `json:"marginUsed"`
+	MaintenanceMargin float64    `json:"maintenanceMargin"`
+	Withdrawable      float64    `json:"withdrawable"`
+	Positions         []Position `json:"positions"`
+	Time              int64      `json:"time"`
+}
+
+// Position is an open perp position. Size is signed: positive long, negative short.
+// LiquidationPrice is zero when the exchange reports none (e.g. a well-collateralized cross position).
+type Position struct {
+	Coin             string  `json:"coin"`
+	Size             float64 `json:"size"`
+	EntryPrice       float64 `json:"entryPrice"`
+	PositionValue    float64 `json:"positionValue"`
+	UnrealizedPnL    float64 `json:"unrealizedPnl"`
+	ReturnOnEquity   float64 `json:"returnOnEquity"`
+	LiquidationPrice float64 `json:"liquidationPrice"`
+	MarginUsed       float64 `json:"marginUsed"`
+	Leverage         int     `json:"leverage"`
+	LeverageType     string  `json:"leverageType"` // cross|isolated
+	MaxLeverage      int     `json:"maxLeverage"`
+	FundingSinceOpen float64 `json:"fundingSinceOpen"`
+}
+
+// OpenOrder is a resting order as reported by frontendOpenOrders, including TP/SL triggers.
+type OpenOrder struct {
+	Coin       string  `json:"coin"`
+	Oid        int64   `json:"oid"`
+	Cloid      string  `json:"cloid,omitempty"`
+	IsBuy      bool    `json:"isBuy"`
+	LimitPrice float64 `json:"limitPrice"`
+	Size       float64 `json:"size"`
+	OrigSize   float64 `json:"origSize"`
+	OrderType  string  `json:"orderType"`
+	Tif        string  `json:"tif,omitempty"`
+	ReduceOnly bool    `json:"reduceOnly"`
+	IsTrigger  bool    `json:"isTrigger"`
+	TriggerPx  float64 `json:"triggerPx,omitempty"`
+	Timestamp  int64   `json:"timestamp"`
+}
+
+type clearinghouseState struct {
+	MarginSummary              marginSummary   `json:"marginSummary"`
+	CrossMaintenanceMarginUsed string          `json:"crossMaintenanceMarginUsed"`
+	Withdrawable               string          `json:"withdrawable"`
+	AssetPositions             []assetPosition `json:"assetPositions"`
+	Time                       int64           `json:"time"`
+}
+
+type marginSummary struct {
+	AccountValue    string `json:"accountValue"`
+	TotalNtlPos     string `json:"totalNtlPos"`
+	TotalRawUsd     string `json:"totalRawUsd"`
+	TotalMarginUsed string `json:"totalMarginUsed"`
+}
+
+type assetPosition struct {
+	Type     string      `json:"type"`
+	Position rawPosition `json:"position"`
+}
+
+type rawPosition struct {
+	Coin           string  `json:"coin"`
+	Szi            string  `json:"szi"`
+	EntryPx        *string `json:"entryPx"`
+	PositionValue  string  `json:"positionValue"`
+	UnrealizedPnl  string  `json:"unrealizedPnl"`
+	ReturnOnEquity string  `json:"returnOnEquity"`
+	LiquidationPx  *string `json:"liquidationPx"`
+	MarginUsed     string  `json:"marginUsed"`
+	MaxLeverage    int     `json:"maxLeverage"`
+	Leverage       struct {
+		Type  string `json:"type"`
+		Value int    `json:"value"`
+	} `json:"leverage"`
+	CumFunding struct {
+		SinceOpen string `json:"sinceOpen"`
+	} `json:"cumFunding"`
+}
+
+type frontendOpenOrder struct {
+	Coin       string  `json:"coin"`
+	Side       string  `json:"side"`
+	LimitPx    string  `json:"limitPx"`
+	Sz         string  `json:"sz"`
+	OrigSz     string  `json:"origSz"`
+	Oid        int64   `json:"oid"`
+	Cloid      *string `json:"cloid"`
+	OrderType  string  `json:"orderType"`
+	Tif        *string `json:"tif"`
+	ReduceOnly bool    `json:"reduceOnly"`
+	IsTrigger  bool    `json:"isTrigger"`
+	TriggerPx  string  `json:"triggerPx"`
+	Timestamp  int64   `json:"timestamp"`
+}
+
+// AssetLeverage is the account's leverage setting on a perp and what it can trade there, parsed from
+// activeAssetData. MaxTradeSizes and AvailableToTrade are [buy, sell].
+type AssetLeverage struct {
+	Coin             string     `json:"coin"`
+	Leverage         int        `json:"leverage"`
+	LeverageType     string     `json:"leverageType"` // cross|isolated
+	MaxTradeSizes    [2]float64 `json:"maxTradeSizes"`
+	AvailableToTrade [2]float64 `json:"availableToTrade"`
+	MarkPrice        float64    `json:"markPrice"`
+}
+
+type activeAssetData struct {
+	Coin     string `json:"coin"`
+	Leverage struct {
+		Type  string `json:"type"`
+		Value int    `json:"value"`
+	} `json:"leverage"`
+	MaxTradeSzs      []string `json:"maxTradeSzs"`
+	AvailableToTrade []string `json:"availableToTrade"`
+	MarkPx           string   `json:"markPx"`
+}
@@ -0,0 +1,444 @@
+package hyperliquid
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	hl "github.com/sonirico/go-hyperliquid"
+)
+
+// ErrOrderRejected is returned when the exchange accepted the request but refused the order itself
+// (insufficient margin, bad tick size, etc.). Transport and signing failures are returned as-is.
+var ErrOrderRejected = errors.New("order rejected by exchange")
+
+// ErrOrderUnknown is returned when an order may have reached the exchange but no status came back, e.g.
+// because the connection dropped or timed out. Look the order up by its cloid to learn what happened.
+var ErrOrderUnknown = errors.New("order outcome unknown")
+
+// ErrInvalidCloid is returned for a client order id that is not 0x followed by 32 hex characters.
+var ErrInvalidCloid = errors.New("cloid must be 0x followed by 32 hex characters")
+
+// DefaultSlippage is how far from the reference price a market order may fill unless its request sets Slippage.
+const DefaultSlippage = hl.DefaultSlippage
+
+// PlaceOrder submits a single order. Market orders are sent as aggressive IOC limits priced off the mid
+// within their slippage bound; limit orders use their time in force, GTC by default.
+func (c *Client) PlaceOrder(ctx context.Context, req OrderRequest) (OrderResult, error) {
+	ex, release, err := c.exchange()
+	if err != nil {
+		return OrderResult{}, err
+	}
+	defer release()
+	order, err := c.order(ctx, ex, req)
+	if err != nil {
+		return OrderResult{}, err
+	}
+	return submit(ctx, ex, order)
+}
+
+// PlaceOrders submits several orders in one signed batch. Results are in request order; an order the
+// exchange refused comes back with status error and its reason while the others go through. The error
+// is only set when the batch as a whole failed.
+func (c *Client) PlaceOrders(ctx context.Context, reqs []OrderRequest) ([]OrderResult, error) {
+	if len(reqs) == 0 {
+		return nil, nil
+	}
+	ex, release, err := c.exchange()
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	orders := make([]hl.CreateOrderRequest, len(reqs))
+	for i, req := range reqs {
+		order, err := c.order(ctx, ex, req)
+		if err != nil {
+			return nil, fmt.Errorf("order %d: %w", i, err)
+		}
+		orders[i] = order
+	}
+
+	// BulkOrders also returns an error when any single order was refused; the statuses tell them apart.
+	resp, err := ex.BulkOrders(ctx, orders, nil)
+	statuses, err := orderStatuses(resp, err, len(orders))
+	if err != nil {
+		return nil, err
+	}
+	out := make([]OrderResult, len(statuses))
+	for i, st := range statuses {
+		out[i] = toOrderResult(st)
+		if out[i].Cloid == "" {
+			out[i].Cloid = NormalizeCloid(reqs[i].Cloid)
+		}
+	}
+	return out, nil
+}
+
+// order builds the SDK order for a request: market orders become IOC limits at the slippage-bounded
+// price, limit orders keep their price and time in force.
+func (c *Client) order(ctx context.Context, ex *hl.Exchange, req OrderRequest) (hl.CreateOrderRequest, error) {
+	coin := NormalizeSymbol(req.Coin)
+	price := req.Price
+	tif := hl.TifGtc
+	switch req.Tif {
+	case "", TifGtc:
+	case TifIoc:
+		tif = hl.TifIoc
+	case TifAlo:
+		tif = hl.TifAlo
+	default:
+		return hl.CreateOrderRequest{}, fmt.Errorf("unknown time in force %q", req.Tif)
+	}
+
+	if req.Market {
+		slippage := req.Slippage
+		if slippage == 0 {
+			slippage = DefaultSlippage
+		}
+		if slippage < 0 || slippage >= 1 {
+			return hl.CreateOrderRequest{}, fmt.Errorf("slippage must be between 0 and 1, got %v", slippage)
+		}
+		var ref *float64
+		if req.Price > 0 {
+			ref = &req.Price
+		}
+		px, err := ex.SlippagePrice(ctx, coin, req.IsBuy, slippage, ref)
+		if err != nil {
+			return hl.CreateOrderRequest{}, fmt.Errorf("failed to compute market price: %w", err)
+		}
+		price = px
+		tif = hl.TifIoc
+	}
+
+	order := hl.CreateOrderRequest{
+		Coin:       coin,
+		IsBuy:      req.IsBuy,
+		Size:       req.Size,
+		Price:      price,
+		ReduceOnly: req.ReduceOnly,
+		OrderType:  hl.OrderType{Limit: &hl.LimitOrderType{Tif: tif}},
+	}
+	if req.Cloid != "" {
+		cloid, err := ParseCloid(req.Cloid)
+		if err != nil {
+			return hl.CreateOrderRequest{}, err
+		}
+		order.ClientOrderID = &cloid
+	}
+	return order, nil
+}
+
+// NormalizeCloid returns a client order id the way the exchange reports it: lowercase hex with a 0x prefix.
+func NormalizeCloid(cloid string) string {
+	cloid = strings.ToLower(strings.TrimSpace(cloid))
+	if cloid == "" || strings.HasPrefix(cloid, "0x") {
+		return cloid
+	}
+	return "0x" + cloid
+}
+
+// ParseCloid normalizes cloid and checks it is a well-formed client order id, so a malformed one is refused
+// before it is signed and sent.
+func ParseCloid(cloid string) (string, error) {
+	cloid = NormalizeCloid(cloid)
+	if len(cloid) != 34 {
+		return "", ErrInvalidCloid
+	}
+	if _, err := hex.DecodeString(cloid[2:]); err != nil {
+		return "", ErrInvalidCloid
+	}
+	return cloid, nil
+}
+
+// submit sends a single order and maps its status; exchange-side refusals are wrapped in ErrOrderRejected.
+func submit(ctx context.Context, ex *hl.Exchange, order hl.CreateOrderRequest) (OrderResult, error) {
+	resp, err := ex.BulkOrders(ctx, []hl.CreateOrderRequest{order}, nil)
+	statuses, err := orderStatuses(resp, err, 1)
+	if err != nil {
+		return OrderResult{}, err
+	}
+
+	res := toOrderResult(statuses[0])
+	if res.Cloid == "" && order.ClientOrderID != nil {
+		res.Cloid = *order.ClientOrderID
+	}
+	if res.Status == OrderStatusError {
+		return res, fmt.Errorf("%w: %s", ErrOrderRejected, res.Error)
+	}
+
+	return res, nil
+}
+
+// orderStatuses checks an order or batchModify response for n orders and returns their statuses. An action
+// the exchange refused as a whole is an ErrOrderRejected; without statuses the outcome is an ErrOrderUnknown
+// wrapping the SDK's error.
+func orderStatuses(resp *hl.APIResponse[hl.OrderResponse], err error, n int) ([]hl.OrderStatus, error) {
+	if resp != nil && !resp.Ok && resp.Err != "" {
+		return nil, fmt.Errorf("%w: %s", ErrOrderRejected, resp.Err)
+	}
+	if resp == nil || len(resp.Data.Statuses) != n {
+		if err == nil {
+			err = fmt.Errorf("expected %d order statuses, got %d", n, statusCount(resp))
+		}
+		return nil, fmt.Errorf("%w: %w", ErrOrderUnknown, err)
+	}
+	return resp.Data.Statuses, nil
+}
+
+// modifyStatuses maps BulkModifyOrders' errors like orderStatuses does, from the SDK's messages since it
+// does not return the response: a batch the exchange refused is an ErrOrderRejected, one that could not be
+// built was never sent and is returned as is, and any other failure is an ErrOrderUnknown.
+func modifyStatuses(statuses []hl.OrderStatus, err error) ([]hl.OrderStatus, error) {
+	if err == nil {
+		return statuses, nil
+	}
+	msg := err.Error()
+	switch {
+	case strings.HasPrefix(msg, "failed to create bulk modify action"):
+		return nil, err
+	case errors.Unwrap(err) == nil && strings.HasPrefix(msg, "failed to modify orders: "):
+		return nil, fmt.Errorf("%w: %s", ErrOrderRejected, strings.TrimPrefix(msg, "failed to modify orders: "))
+	default:
+		return nil, fmt.Errorf("%w: %w", ErrOrderUnknown, err)
+	}
+}
+
+func statusCount(resp *hl.APIResponse[hl.OrderResponse]) int {
+	if resp == nil {
+		return 0
+	}
+	return len(resp.Data.Statuses)
+}
+
+// toOrderResult flattens the SDK's resting/filled/error union into an OrderResult.
+func toOrderResult(st hl.OrderStatus) OrderResult {
+	switch {
+	case st.Error != nil:
+		return OrderResult{Status: OrderStatusError, Error: *st.Error}
+	case st.Filled != nil:
+		sz, _ := strconv.ParseFloat(st.Filled.TotalSz, 64)
+		px, _ := strconv.ParseFloat(st.Filled.AvgPx, 64)
+		return OrderResult{Oid: int64(st.Filled.Oid), Status: OrderStatusFilled, FilledSize: sz, AvgPrice: px}
+	case st.Resting != nil:
+		res := OrderResult{Oid: st.Resting.Oid, Status: OrderStatusResting}
+		if st.Resting.ClientID != nil {
+			res.Cloid = *st.Resting.ClientID
+		}
+		return res
+	default:
+		return OrderResult{Status: OrderStatusError, Error: "unknown order status"}
+	}
+}
+
+// PlaceTriggerOrder places a reduce-only market trigger order. The limit price is the trigger price
+// widened by DefaultSlippage so the triggered order can cross the book.
+func (c *Client) PlaceTriggerOrder(ctx context.Context, req TriggerOrderRequest) (OrderResult, error) {
+	ex, release, err := c.exchange()
+	if err != nil {
+		return OrderResult{}, err
+	}
+	defer release()
+
+	coin := NormalizeSymbol(req.Coin)
+	triggerPx, err := ex.SlippagePrice(ctx, coin, req.IsBuy, 0, &req.TriggerPx)
+	if err != nil {
+		return OrderResult{}, fmt.Errorf("failed to round trigger price: %w", err)
+	}
+	limitPx, err := ex.SlippagePrice(ctx, coin, req.IsBuy, hl.DefaultSlippage, &req.TriggerPx)
+	if err != nil {
+		return OrderResult{}, fmt.Errorf("failed to compute trigger limit price: %w", err)
+	}
+
+	order := hl.CreateOrderRequest{
+		Coin:       coin,
+		IsBuy:      req.IsBuy,
+		Size:       req.Size,
+		Price:      limitPx,
+		ReduceOnly: true,
+		OrderType: hl.OrderType{Trigger: &hl.TriggerOrderType{
+			TriggerPx: triggerPx,
+			IsMarket:  true,
+			Tpsl:      hl.Tpsl(req.Tpsl),
+		}},
+	}
+	if req.Cloid != "" {
+		cloid, err := ParseCloid(req.Cloid)
+		if err != nil {
+			return OrderResult{}, err
+		}
+		order.ClientOrderID = &cloid
+	}
+
+	return submit(ctx, ex, order)
+}
+
+// CancelOrder cancels a resting order by exchange oid.
+func (c *Client) CancelOrder(ctx context.Context, coin string, oid int64) error {
+	ex, release, err := c.exchange()
+	if err != nil {
+		return err
+	}
+	defer release()
+	_, err = ex.Cancel(ctx, NormalizeSymbol(coin), oid)
+	return err
+}
+
+// CancelByCloid cancels a resting order by the client order id it was placed with.
+func (c *Client) CancelByCloid(ctx context.Context, coin, cloid string) error {
+	cloid, err := ParseCloid(cloid)
+	if err != nil {
+		return err
+	}
+	ex, release, err := c.exchange()
+	if err != nil {
+		return err
+	}
+	defer release()
+	_, err = ex.CancelByCloid(ctx, NormalizeSymbol(coin), cloid)
+	return err
+}
+
+// CancelAll cancels every open order on coin, TP/SL triggers included, or on every coin when coin is
+// empty, in one batch. It returns how many were canceled; orders that filled or were canceled in the
+// meantime are reported in the error.
+func (c *Client) CancelAll(ctx context.Context, coin string) (int, error) {
+	ex, release, err := c.exchange()
+	if err != nil {
+		return 0, err
+	}
+	defer release()
+	open, err := c.OpenOrders(ctx)
+	if err != nil {
+		return 0, err
+	}
+	var cancels []hl.CancelOrderRequest
+	for _, o := range open {
+		if coin == "" || o.Coin == NormalizeSymbol(coin) {
+			cancels = append(cancels, hl.CancelOrderRequest{Coin: o.Coin, OrderID: o.Oid})
+		}
+	}
+	if len(cancels) == 0 {
+		return 0, nil
+	}
+
+	resp, err := ex.BulkCancel(ctx, cancels)
+	if resp == nil {
+		return 0, err
+	}
+	canceled := 0
+	for _, st := range resp.Data.Statuses {
+		if s, ok := st.String(); ok && s == "success" {
+			canceled++
+		}
+	}
+	return canceled, err
+}
+
+// ModifyOrder replaces a resting order's price, size, side or time in force. The result is the status of
+// the replacement, which may fill at once.
+func (c *Client) ModifyOrder(ctx context.Context, req ModifyRequest) (OrderResult, error) {
+	res, err := c.ModifyOrders(ctx, []ModifyRequest{req})
+	if err != nil {
+		return OrderResult{}, err
+	}
+	if res[0].Status == OrderStatusError {
+		return res[0], fmt.Errorf("%w: %s", ErrOrderRejected, res[0].Error)
+	}
+	return res[0], nil
+}
+
+// ModifyOrders replaces several resting orders in one signed batch. Like PlaceOrders, results are in request
+// order and a refused modify comes back with status error.
+func (c *Client) ModifyOrders(ctx context.Context, reqs []ModifyRequest) ([]OrderResult, error) {
+	if len(reqs) == 0 {
+		return nil, nil
+	}
+	ex, release, err := c.exchange()
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	modifies := make([]hl.ModifyOrderRequest, len(reqs))
+	for i, req := range reqs {
+		if (req.Oid == 0) == (req.Cloid == "") {
+			return nil, fmt.Errorf("modify %d: set exactly one of oid and cloid", i)
+		}
+		order, err := c.order(ctx, ex, req.Order)
+		if err != nil {
+			return nil, fmt.Errorf("modify %d: %w", i, err)
+		}
+		modifies[i].Order = order
+		if req.Oid != 0 {
+			oid := req.Oid
+			modifies[i].Oid = &oid
+		} else {
+			cloid, err := ParseCloid(req.Cloid)
+			if err != nil {
+				return nil, fmt.Errorf("modify %d: %w", i, err)
+			}
+			modifies[i].Cloid = &hl.Cloid{Value: cloid}
+		}
+	}
+
+	// A single modify is sent as a batch too: only batchModify answers with the new order's status.
+	statuses, err := modifyStatuses(ex.BulkModifyOrders(ctx, modifies))
+	if err != nil {
+		return nil, err
+	}
+	if len(statuses) != len(reqs) {
+		return nil, fmt.Errorf("%w: expected %d order statuses, got %d", ErrOrderUnknown, len(reqs), len(statuses))
+	}
+	out := make([]OrderResult, len(statuses))
+	for i, st := range statuses {
+		out[i] = toOrderResult(st)
+		if out[i].Cloid == "" {
+			out[i].Cloid = NormalizeCloid(reqs[i].Order.Cloid)
+		}
+	}
+	return out, nil
+}
+
+// QueryOrder fetches the current status of an order by oid.
+func (c *Client) QueryOrder(ctx context.Context, oid int64) (OrderQuery, error) {
+	return c.queryOrder(ctx, oid)
+}
+
+// QueryOrderByCloid fetches the current status of an order by the client order id it was placed with.
+// Status is "unknownOid" when the exchange never received it.
+func (c *Client) QueryOrderByCloid(ctx context.Context, cloid string) (OrderQuery, error) {
+	return c.queryOrder(ctx, NormalizeCloid(cloid))
+}
+
+// queryOrder asks orderStatus about an order; id is its oid or its cloid.
+func (c *Client) queryOrder(ctx context.Context, id any) (OrderQuery, error) {
+	if c.walletAddress == "" {
+		return OrderQuery{}, errors.New("wallet address is required")
+	}
+	var out orderStatusResponse
+	payload := map[string]any{"type": "orderStatus", "user": c.walletAddress, "oid": id}
+	if err := c.postInfo(ctx, payload, &out); err != nil {
+		return OrderQuery{}, err
+	}
+	if out.Status != "order" {
+		return OrderQuery{Status: out.Status}, nil
+	}
+	return out.Order, nil
+}
+
+// DecisionCloid is the client order id of one leg (entry, exit, tp1, ..., sl) of a decision. It is derived
+// from the decision id and leg alone, so a retried or restarted submission carries the same cloid.
+func DecisionCloid(decisionID int64, leg string) string {
+	sum := sha256.Sum256([]byte(fmt.Sprintf("decision:%d:%s", decisionID, leg)))
+	return "0x" + hex.EncodeToString(sum[:16])
+}
+
+// FloorSize truncates a size to the instrument's szDecimals.
+func FloorSize(sz float64, szDecimals int) float64 {
+	pow := math.Pow(10, float64(szDecimals))
+	return math.Floor(sz*pow+1e-9) / pow
+}
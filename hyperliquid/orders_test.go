@@ -0,0 +1,187 @@
+package hyperliquid
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"deepseek-trader/config"
+
+	hl "github.com/sonirico/go-hyperliquid"
+)
+
+func TestParseCloid(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+		err  bool
+	}{
+		{"prefixed", "0x0123456789abcdef0123456789abcdef", "0x0123456789abcdef0123456789abcdef", false},
+		{"unprefixed upper", "0123456789ABCDEF0123456789ABCDEF", "0x0123456789abcdef0123456789abcdef", false},
+		{"padded", "  0x0123456789abcdef0123456789abcdef ", "0x0123456789abcdef0123456789abcdef", false},
+		{"empty", "", "", true},
+		{"short", "0x0123456789abcdef", "", true},
+		{"long", "0x0123456789abcdef0123456789abcdef00", "", true},
+		{"not hex", "0x0123456789abcdef0123456789abcdeg", "", true},
+		{"double prefix", "0x0x23456789abcdef0123456789abcdef", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseCloid(tt.in)
+			if tt.err {
+				if !errors.Is(err, ErrInvalidCloid) {
+					t.Fatalf("ParseCloid(%q) error = %v, want ErrInvalidCloid", tt.in, err)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Fatalf("ParseCloid(%q) = %q, %v, want %q", tt.in, got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestModifyOrdersRejectsMalformedCloid(t *testing.T) {
+	srv := fakeExchange(t)
+	c, err := NewClient(&config.Settings{HLBaseURL: srv.URL}).
+		WithSigner(context.Background(), "0x0000000000000000000000000000000000000001", []byte(testKey))
+	if err != nil {
+		t.Fatalf("WithSigner: %v", err)
+	}
+	defer c.Close()
+
+	_, err = c.ModifyOrders(context.Background(), []ModifyRequest{{
+		Cloid: "0xnot-a-cloid",
+		Order: OrderRequest{Coin: "BTC", IsBuy: true, Size: 0.01, Price: 50000},
+	}})
+	if !errors.Is(err, ErrInvalidCloid) {
+		t.Fatalf("ModifyOrders error = %v, want ErrInvalidCloid", err)
+	}
+}
+
+func TestModifyOrdersErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		body   string
+		want   error
+	}{
+		{name: "refused batch", status: http.StatusOK, body: `{"status":"err","response":"Cannot modify canceled or filled order"}`, want: ErrOrderRejected},
+		{name: "server error", status: http.StatusInternalServerError, body: `{}`, want: ErrOrderUnknown},
+		{name: "no statuses", status: http.StatusOK, body: `{"status":"ok","response":{"type":"order","data":{"statuses":[]}}}`, want: ErrOrderUnknown},
+		{name: "too few statuses", status: http.StatusOK, body: `{"status":"ok","response":{"type":"order","data":{"statuses":[{"resting":{"oid":8}}]}}}`, want: ErrOrderUnknown},
+		{name: "one refused modify", status: http.StatusOK,
+			body: `{"status":"ok","response":{"type":"order","data":{"statuses":[{"resting":{"oid":8}},{"error":"Order has zero size."}]}}}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := fakeExchangeWith(t, func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte(tt.body))
+			})
+			c, err := NewClient(&config.Settings{HLBaseURL: srv.URL}).
+				WithSigner(context.Background(), "0x0000000000000000000000000000000000000001", []byte(testKey))
+			if err != nil {
+				t.Fatalf("WithSigner: %v", err)
+			}
+			defer c.Close()
+
+			order := OrderRequest{Coin: "BTC", IsBuy: true, Size: 0.01, Price: 50000}
+			res, err := c.ModifyOrders(context.Background(), []ModifyRequest{{Oid: 7, Order: order}, {Oid: 9, Order: order}})
+			if tt.want == nil {
+				if err != nil || len(res) != 2 || res[0].Status != OrderStatusResting || res[1].Status != OrderStatusError {
+					t.Fatalf("ModifyOrders = %+v, %v, want one resting and one refused result", res, err)
+				}
+				return
+			}
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("ModifyOrders error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestOrderStatuses(t *testing.T) {
+	resting := hl.OrderStatus{Resting: &hl.OrderStatusResting{Oid: 7}}
+	tests := []struct {
+		name string
+		resp *hl.APIResponse[hl.OrderResponse]
+		err  error
+		want error
+	}{
+		{"transport error", nil, errors.New("connection reset by peer"), ErrOrderUnknown},
+		{"empty response", nil, nil, ErrOrderUnknown},
+		{"missing statuses", &hl.APIResponse[hl.OrderResponse]{Ok: true}, nil, ErrOrderUnknown},
+		{"refused action", &hl.APIResponse[hl.OrderResponse]{Err: "insufficient margin"}, errors.New("insufficient margin"), ErrOrderRejected},
+		{"ok", &hl.APIResponse[hl.OrderResponse]{Ok: true, Data: hl.OrderResponse{Statuses: []hl.OrderStatus{resting}}}, nil, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			statuses, err := orderStatuses(tt.resp, tt.err, 1)
+			if tt.want == nil {
+				if err != nil || len(statuses) != 1 {
+					t.Fatalf("orderStatuses = %v, %v, want one status", statuses, err)
+				}
+				return
+			}
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("orderStatuses error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestNormalizeCloid(t *testing.T) {
+	tests := []struct{ in, want string }{
+		{"", ""},
+		{"   ", ""},
+		{"0xABCDEF", "0xabcdef"},
+		{"abcdef", "0xabcdef"},
+		{" 0x0123456789abcdef0123456789abcdef ", "0x0123456789abcdef0123456789abcdef"},
+	}
+	for _, tt := range tests {
+		if got := NormalizeCloid(tt.in); got != tt.want {
+			t.Errorf("NormalizeCloid(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestDecisionCloid(t *testing.T) {
+	tests := []struct {
+		name string
+		id   int64
+		leg  string
+	}{
+		{"entry", 1, "entry"},
+		{"stop", 1, "sl"},
+		{"other decision", 2, "entry"},
+		{"zero id", 0, "entry"},
+		{"negative id", -1, "entry"},
+		{"empty leg", 1, ""},
+	}
+	seen := make(map[string]string, len(tests))
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := DecisionCloid(tt.id, tt.leg)
+			if parsed, err := ParseCloid(got); err != nil || parsed != got {
+				t.Fatalf("DecisionCloid(%d, %q) = %q is not a normalized cloid: %v", tt.id, tt.leg, got, err)
+			}
+			if again := DecisionCloid(tt.id, tt.leg); again != got {
+				t.Fatalf("DecisionCloid is not deterministic: %q then %q", got, again)
+			}
+			if prev, ok := seen[got]; ok {
+				t.Fatalf("DecisionCloid(%d, %q) collides with %s", tt.id, tt.leg, prev)
+			}
+			seen[got] = tt.name
+		})
+	}
+}
+
+// The derivation must not change: a restarted bot finds the orders of earlier runs by these ids.
+func TestDecisionCloidIsStable(t *testing.T) {
+	if got, want := DecisionCloid(1, "entry"), "0xc9f962bcfd29c39a7c6f340dd6ce85b4"; got != want {
+		t.Fatalf("DecisionCloid(1, entry) = %s, want %s", got, want)
+	}
+}
@@ -0,0 +1,337 @@
+package hyperliquid
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"go.uber.org/zap"
+)
+
+const (
+	wsPingInterval = 50 * time.Second
+	wsMaxBackoff   = 30 * time.Second
+)
+
+// WSClient streams market data and user events from the Hyperliquid WebSocket API: mids, L2 books and
+// candles of every interval go into a MarketState, the user's fills and order updates to the callbacks.
+// It reconnects with exponential backoff and resubscribes to every channel after each reconnect.
+type WSClient struct {
+	url       string
+	user      string
+	coins     []string
+	intervals []string
+	log       *zap.Logger
+
+	state *MarketState
+
+	// OnFills and OnOrderUpdates are invoked from the read goroutine; they must not block. OnFills also
+	// gets the snapshot of recent fills the exchange sends on every subscribe, so fills made while the
+	// client was disconnected still arrive; snapshot tells it apart.
+	OnFills        func(fills []UserFill, snapshot bool)
+	OnOrderUpdates func([]OrderQuery)
+
+	writeMx sync.Mutex
+}
+
+// NewWSClient streams the coins' candles of every interval; an empty user subscribes to market data only.
+func NewWSClient(url, user string, coins, intervals []string, log *zap.Logger) *WSClient {
+	return &WSClient{
+		url:       url,
+		user:      user,
+		coins:     coins,
+		intervals: intervals,
+		log:       log,
+		state:     NewMarketState(),
+	}
+}
+
+// State returns the live market state fed by this client.
+func (w *WSClient) State() *MarketState {
+	return w.state
+}
+
+// Run connects and consumes messages until ctx is canceled.
+func (w *WSClient) Run(ctx context.Context) {
+	backoff := time.Second
+	for {
+		started := time.Now()
+		err := w.session(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		w.log.Sugar().Warnw("hyperliquid websocket disconnected", "error", err)
+
+		if time.Since(started) > wsMaxBackoff {
+			backoff = time.Second
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, wsMaxBackoff)
+	}
+}
+
+// session runs a single connection until it fails or ctx is canceled.
+func (w *WSClient) session(ctx context.Context) error {
+	conn, resp, err := websocket.DefaultDialer.DialContext(ctx, w.url, nil)
+	if resp != nil && resp.Body != nil {
+		_ = resp.Body.Close()
+	}
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			_ = conn.Close()
+		case <-done:
+		}
+	}()
+
+	for _, sub := range w.subscriptions() {
+		if err := w.write(conn, map[string]any{"method": "subscribe", "subscription": sub}); err != nil {
+			return err
+		}
+	}
+
+	go w.pingLoop(conn, done)
+
+	for {
+		_, raw, err := conn.ReadMessage()
+		if err != nil {
+			return err
+		}
+		w.dispatch(raw)
+	}
+}
+
+func (w *WSClient) subscriptions() []map[string]any {
+	subs := []map[string]any{{"type": "allMids"}}
+	for _, coin := range w.coins {
+		subs = append(subs, map[string]any{"type": "l2Book", "coin": coin})
+		for _, interval := range w.intervals {
+			subs = append(subs, map[string]any{"type": "candle", "coin": coin, "interval": interval})
+		}
+	}
+	if w.user != "" {
+		subs = append(subs,
+			map[string]any{"type": "userFills", "user": w.user},
+			map[string]any{"type": "orderUpdates", "user": w.user},
+		)
+	}
+	return subs
+}
+
+func (w *WSClient) pingLoop(conn *websocket.Conn, done <-chan struct{}) {
+	ticker := time.NewTicker(wsPingInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+			if err := w.write(conn, map[string]any{"method": "ping"}); err != nil {
+				return
+			}
+		}
+	}
+}
+
+func (w *WSClient) write(conn *websocket.Conn, msg any) error {
+	w.writeMx.Lock()
+	defer w.writeMx.Unlock()
+	return conn.WriteJSON(msg)
+}
+
+func (w *WSClient) dispatch(raw []byte) {
+	var msg wsMessage
+	if err := json.Unmarshal(raw, &msg); err != nil {
+		w.log.Sugar().Warnw("failed to decode websocket message", "error", err)
+		return
+	}
+
+	var err error
+	switch msg.Channel {
+	case "allMids":
+		var data wsAllMids
+		if err = json.Unmarshal(msg.Data, &data); err == nil {
+			w.state.setMids(data.Mids)
+		}
+	case "l2Book":
+		var book OrderBookSnapshot
+		if err = json.Unmarshal(msg.Data, &book); err == nil {
+			w.state.setBook(book)
+		}
+	case "candle":
+		var c Candle
+		if err = json.Unmarshal(msg.Data, &c); err == nil {
+			w.state.upsertCandle(c)
+		}
+	case "userFills":
+		var data wsUserFills
+		if err = json.Unmarshal(msg.Data, &data); err == nil && w.OnFills != nil {
+			w.OnFills(data.Fills, data.IsSnapshot)
+		}
+	case "orderUpdates":
+		var updates []OrderQuery
+		if err = json.Unmarshal(msg.Data, &updates); err == nil && w.OnOrderUpdates != nil {
+			w.OnOrderUpdates(updates)
+		}
+	}
+	if err != nil {
+		w.log.Sugar().Warnw("failed to decode websocket payload", "channel", msg.Channel, "error", err)
+	}
+}
+
+// MarketState is the latest market data received over the WebSocket, safe for concurrent reads.
+type MarketState struct {
+	mx        sync.RWMutex
+	mids      map[string]string
+	books     map[string]streamedBook
+	candles   map[candleKey]*candleSeries
+	updatedAt time.Time
+}
+
+type candleKey struct{ coin, interval string }
+
+// candleSeries is a candle history seeded by polling and kept current by the stream, with the time it was
+// last updated. It keeps the length it was seeded with, dropping the oldest candle as a new one opens.
+type candleSeries struct {
+	candles []Candle
+	limit   int
+	at      time.Time
+}
+
+// streamedBook is a book with the time it arrived; a coin's book can go stale while mids keep flowing.
+type streamedBook struct {
+	book OrderBookSnapshot
+	at   time.Time
+}
+
+func NewMarketState() *MarketState {
+	return &MarketState{
+		mids:    make(map[string]string),
+		books:   make(map[string]streamedBook),
+		candles: make(map[candleKey]*candleSeries),
+	}
+}
+
+// Fresh reports whether mids were updated within maxAge. Books age on their own; see Book.
+func (m *MarketState) Fresh(maxAge time.Duration) bool {
+	m.mx.RLock()
+	defer m.mx.RUnlock()
+	return !m.updatedAt.IsZero() && time.Since(m.updatedAt) <= maxAge
+}
+
+// Mids returns a copy of the latest mid prices.
+func (m *MarketState) Mids() map[string]string {
+	m.mx.RLock()
+	defer m.mx.RUnlock()
+	out := make(map[string]string, len(m.mids))
+	for k, v := range m.mids {
+		out[k] = v
+	}
+	return out
+}
+
+// Book returns the latest L2 book for a coin if it arrived within maxAge.
+func (m *MarketState) Book(coin string, maxAge time.Duration) (OrderBookSnapshot, bool) {
+	m.mx.RLock()
+	defer m.mx.RUnlock()
+	b, ok := m.books[coin]
+	if !ok || time.Since(b.at) > maxAge {
+		return OrderBookSnapshot{}, false
+	}
+	return b.book, true
+}
+
+// SeedCandles stores a polled candle history of a coin and interval, oldest first, for the stream to extend.
+func (m *MarketState) SeedCandles(coin, interval string, candles []Candle) {
+	m.mx.Lock()
+	defer m.mx.Unlock()
+	m.candles[candleKey{coin, interval}] = &candleSeries{
+		candles: append([]Candle(nil), candles...),
+		limit:   len(candles),
+		at:      time.Now(),
+	}
+}
+
+// Candles returns the seeded candle history of a coin and interval as extended by the stream, oldest first.
+// It reports false when no history was seeded, the stream has not updated it within maxAge, or it missed a
+// candle; the history has to be polled and seeded again then.
+func (m *MarketState) Candles(coin, interval string, maxAge time.Duration) ([]Candle, bool) {
+	m.mx.RLock()
+	defer m.mx.RUnlock()
+	s, ok := m.candles[candleKey{coin, interval}]
+	if !ok || time.Since(s.at) > maxAge {
+		return nil, false
+	}
+	return append([]Candle(nil), s.candles...), true
+}
+
+func (m *MarketState) setMids(mids map[string]string) {
+	m.mx.Lock()
+	defer m.mx.Unlock()
+	for k, v := range mids {
+		m.mids[k] = v
+	}
+	m.updatedAt = time.Now()
+}
+
+func (m *MarketState) setBook(b OrderBookSnapshot) {
+	m.mx.Lock()
+	defer m.mx.Unlock()
+	m.books[b.Coin] = streamedBook{book: b, at: time.Now()}
+}
+
+// upsertCandle updates the open candle of a seeded series or appends the next one. A candle that does not
+// follow the last one means candles were missed while disconnected, so the series is dropped for re-seeding.
+func (m *MarketState) upsertCandle(c Candle) {
+	m.mx.Lock()
+	defer m.mx.Unlock()
+	key := candleKey{c.Symbol, c.Interval}
+	s, ok := m.candles[key]
+	if !ok {
+		return
+	}
+	n := len(s.candles)
+	switch {
+	case n > 0 && c.StartTime == s.candles[n-1].StartTime:
+		s.candles[n-1] = c
+	case n > 0 && c.StartTime < s.candles[n-1].StartTime:
+		return
+	case n == 0 || c.StartTime == s.candles[n-1].EndTime+1:
+		s.candles = append(s.candles, c)
+		if s.limit > 0 && len(s.candles) > s.limit {
+			s.candles = s.candles[len(s.candles)-s.limit:]
+		}
+	default:
+		delete(m.candles, key)
+		return
+	}
+	s.at = time.Now()
+}
+
+type wsMessage struct {
+	Channel string          `json:"channel"`
+	Data    json.RawMessage `json:"data"`
+}
+
+type wsAllMids struct {
+	Mids map[string]string `json:"mids"`
+}
+
+type wsUserFills struct {
+	IsSnapshot bool       `json:"isSnapshot"`
+	User       string     `json:"user"`
+	Fills      []UserFill `json:"fills"`
+}
@@ -0,0 +1,130 @@
+package hyperliquid
+
+import (
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+func TestMarketStateBookAge(t *testing.T) {
+	m := NewMarketState()
+	m.setBook(OrderBookSnapshot{Coin: "BTC"})
+	m.setBook(OrderBookSnapshot{Coin: "ETH"})
+	m.books["ETH"] = streamedBook{book: m.books["ETH"].book, at: time.Now().Add(-2 * time.Minute)}
+	m.setMids(map[string]string{"BTC": "50000", "ETH": "3000"})
+
+	tests := []struct {
+		coin string
+		want bool
+	}{
+		{"BTC", true},
+		{"ETH", false}, // mids keep flowing, but this book is stale
+		{"SOL", false},
+	}
+	for _, tt := range tests {
+		if _, ok := m.Book(tt.coin, time.Minute); ok != tt.want {
+			t.Errorf("Book(%s) ok = %v, want %v", tt.coin, ok, tt.want)
+		}
+	}
+	if !m.Fresh(time.Minute) {
+		t.Error("mids should be fresh")
+	}
+}
+
+func TestMarketStateCandles(t *testing.T) {
+	const minute = int64(60_000)
+	candle := func(i int64, close string) Candle {
+		return Candle{Symbol: "BTC", Interval: "1m", StartTime: i * minute, EndTime: (i+1)*minute - 1, Close: close}
+	}
+	seed := []Candle{candle(1, "1"), candle(2, "2"), candle(3, "3")}
+
+	tests := []struct {
+		name   string
+		pushed []Candle
+		want   []string // closes; nil when the series must be re-seeded
+	}{
+		{name: "open candle updated", pushed: []Candle{candle(3, "3.5")}, want: []string{"1", "2", "3.5"}},
+		{name: "next candle rolls the window", pushed: []Candle{candle(4, "4")}, want: []string{"2", "3", "4"}},
+		{name: "late update ignored", pushed: []Candle{candle(2, "9")}, want: []string{"1", "2", "3"}},
+		{name: "missed candle drops the series", pushed: []Candle{candle(5, "5")}},
+		{name: "other interval ignored", pushed: []Candle{{Symbol: "BTC", Interval: "5m", StartTime: 4 * minute}}, want: []string{"1", "2", "3"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewMarketState()
+			m.SeedCandles("BTC", "1m", seed)
+			for _, c := range tt.pushed {
+				m.upsertCandle(c)
+			}
+			got, ok := m.Candles("BTC", "1m", time.Minute)
+			if tt.want == nil {
+				if ok {
+					t.Fatalf("Candles = %v, want the series dropped", got)
+				}
+				return
+			}
+			if !ok || len(got) != len(tt.want) {
+				t.Fatalf("Candles = %v, %v, want closes %v", got, ok, tt.want)
+			}
+			for i, c := range got {
+				if c.Close != tt.want[i] {
+					t.Errorf("candle %d close = %s, want %s", i, c.Close, tt.want[i])
+				}
+			}
+		})
+	}
+
+	m := NewMarketState()
+	m.upsertCandle(candle(1, "1"))
+	if _, ok := m.Candles("BTC", "1m", time.Minute); ok {
+		t.Error("an unseeded series must be polled first")
+	}
+	m.SeedCandles("BTC", "1m", seed)
+	m.candles[candleKey{"BTC", "1m"}].at = time.Now().Add(-2 * time.Minute)
+	if _, ok := m.Candles("BTC", "1m", time.Minute); ok {
+		t.Error("a series the stream stopped updating must be polled again")
+	}
+}
+
+func TestDispatchUserFills(t *testing.T) {
+	w := NewWSClient("", "0xabc", nil, nil, zap.NewNop())
+	var got []UserFill
+	var snapshots []bool
+	w.OnFills = func(fills []UserFill, snapshot bool) {
+		got = append(got, fills...)
+		snapshots = append(snapshots, snapshot)
+	}
+
+	w.dispatch([]byte(`{"channel":"userFills","data":{"isSnapshot":true,"user":"0xabc","fills":[{"coin":"BTC","px":"50000","sz":"0.1","side":"B","oid":7,"tid":70}]}}`))
+	w.dispatch([]byte(`{"channel":"userFills","data":{"user":"0xabc","fills":[{"coin":"BTC","px":"50100","sz":"0.1","side":"A","oid":8,"tid":80,"closedPnl":"10"}]}}`))
+
+	if len(got) != 2 || got[0].Oid != 7 || got[1].Tid != 80 || got[1].Sz != "0.1" || got[1].ClosedPnl != "10" {
+		t.Fatalf("fills = %+v", got)
+	}
+	if len(snapshots) != 2 || !snapshots[0] || snapshots[1] {
+		t.Fatalf("snapshot flags = %v, want [true false]", snapshots)
+	}
+}
+
+func TestSubscriptions(t *testing.T) {
+	count := func(subs []map[string]any, typ string) int {
+		n := 0
+		for _, s := range subs {
+			if s["type"] == typ {
+				n++
+			}
+		}
+		return n
+	}
+	subs := NewWSClient("", "0xabc", []string{"BTC", "ETH"}, []string{"15m", "1h"}, zap.NewNop()).subscriptions()
+	for typ, want := range map[string]int{"allMids": 1, "l2Book": 2, "candle": 4, "userFills": 1, "orderUpdates": 1} {
+		if got := count(subs, typ); got != want {
+			t.Errorf("%s subscriptions = %d, want %d", typ, got, want)
+		}
+	}
+	market := NewWSClient("", "", []string{"BTC"}, []string{"15m"}, zap.NewNop()).subscriptions()
+	if count(market, "userFills")+count(market, "orderUpdates") != 0 {
+		t.Error("a client without a user must not subscribe to user channels")
+	}
+}
@@ -0,0 +1,58 @@
+package indicators
+
+import (
+	"strconv"
+
+	"deepseek-trader/hyperliquid"
+)
+
+// Book summarizes the top of an L2 book. Depths are notional (px*sz) over the first levels and
+// Imbalance is bid depth over total depth (0.5 = balanced, above favors bids).
+type Book struct {
+	BestBid   float64 `json:"bestBid"`
+	BestAsk   float64 `json:"bestAsk"`
+	Mid       float64 `json:"mid"`
+	Spread    float64 `json:"spread"`
+	SpreadPct float64 `json:"spreadPct"`
+	BidDepth  float64 `json:"bidDepth"`
+	AskDepth  float64 `json:"askDepth"`
+	Imbalance float64 `json:"imbalance"`
+}
+
+// BookStats computes spread and depth over the first `levels` levels of each side.
+// It reports false when either side is empty.
+func BookStats(book hyperliquid.OrderBookSnapshot, levels int) (Book, bool) {
+	if len(book.Levels) < 2 || len(book.Levels[0]) == 0 || len(book.Levels[1]) == 0 {
+		return Book{}, false
+	}
+	bids, asks := book.Levels[0], book.Levels[1]
+
+	b := Book{BestBid: parseF(bids[0].Px), BestAsk: parseF(asks[0].Px)}
+	b.Mid = (b.BestBid + b.BestAsk) / 2
+	b.Spread = b.BestAsk - b.BestBid
+	if b.Mid > 0 {
+		b.SpreadPct = b.Spread / b.Mid * 100
+	}
+	b.BidDepth = depth(bids, levels)
+	b.AskDepth = depth(asks, levels)
+	if total := b.BidDepth + b.AskDepth; total > 0 {
+		b.Imbalance = b.BidDepth / total
+	}
+	return b, true
+}
+
+func depth(side []hyperliquid.OrderBookLevel, levels int) float64 {
+	sum := 0.0
+	for i, l := range side {
+		if i >= levels {
+			break
+		}
+		sum += parseF(l.Px) * parseF(l.Sz)
+	}
+	return sum
+}
+
+func parseF(s string) float64 {
+	f, _ := strconv.ParseFloat(s, 64)
+	return f
+}
@@ -0,0 +1,123 @@
+package indicators
+
+import (
+	"math"
+	"strconv"
+
+	"deepseek-trader/hyperliquid"
+)
+
+// Feature periods. History must hold at least MinBars candles for every candle feature to be set.
+const (
+	MinBars = 60
+
+	bookLevels     = 5
+	swingWidth     = 2
+	levelTolerance = 0.003
+	maxLevels      = 3
+)
+
+// Features are the precomputed indicators for one coin and interval. Pointer fields are nil when
+// there is not enough history for them.
+type Features struct {
+	Price     float64    `json:"price"`
+	SMA20     *float64   `json:"sma20,omitempty"`
+	EMA20     *float64   `json:"ema20,omitempty"`
+	EMA50     *float64   `json:"ema50,omitempty"`
+	RSI14     *float64   `json:"rsi14,omitempty"`
+	ATR14     *float64   `json:"atr14,omitempty"`
+	ATRPct    *float64   `json:"atrPct,omitempty"` // ATR14 as a percent of price
+	VWAP      *float64   `json:"vwap,omitempty"`
+	Bollinger *Bands     `json:"bollinger,omitempty"`
+	MACD      *MACDValue `json:"macd,omitempty"`
+	Levels    Levels     `json:"levels"`
+}
+
+// Compute derives the features from candles (oldest first).
+// Values are rounded to 6 significant digits to keep the prompt short.
+func Compute(candles []hyperliquid.Candle) Features {
+	bars := FromCandles(candles)
+	closes := Closes(bars)
+
+	var f Features
+	if len(closes) > 0 {
+		f.Price = closes[len(closes)-1]
+	}
+	f.SMA20 = last(SMA(closes, 20))
+	f.EMA20 = last(EMA(closes, 20))
+	f.EMA50 = last(EMA(closes, 50))
+	f.RSI14 = last(RSI(closes, 14))
+	f.ATR14 = last(ATR(bars, 14))
+	if f.ATR14 != nil && f.Price > 0 {
+		f.ATRPct = ptr(round(*f.ATR14 / f.Price * 100))
+	}
+	if vwap := VWAP(bars); vwap > 0 {
+		f.VWAP = ptr(round(vwap))
+	}
+	if b, ok := Bollinger(closes, 20, 2); ok {
+		b = Bands{Upper: round(b.Upper), Middle: round(b.Middle), Lower: round(b.Lower), Width: round(b.Width), PercentB: round(b.PercentB)}
+		f.Bollinger = &b
+	}
+	if m, ok := MACD(closes, 12, 26, 9); ok {
+		m = MACDValue{MACD: round(m.MACD), Signal: round(m.Signal), Histogram: round(m.Histogram)}
+		f.MACD = &m
+	}
+	f.Levels = SupportResistance(bars, swingWidth, levelTolerance, maxLevels)
+	roundAll(f.Levels.Support)
+	roundAll(f.Levels.Resistance)
+	return f
+}
+
+// BookFeatures summarizes the first levels of a book, rounded like Compute.
+func BookFeatures(book hyperliquid.OrderBookSnapshot) (Book, bool) {
+	b, ok := BookStats(book, bookLevels)
+	if !ok {
+		return Book{}, false
+	}
+	return Book{
+		BestBid: b.BestBid, BestAsk: b.BestAsk, Mid: round(b.Mid), Spread: round(b.Spread), SpreadPct: round(b.SpreadPct),
+		BidDepth: round(b.BidDepth), AskDepth: round(b.AskDepth), Imbalance: round(b.Imbalance),
+	}, true
+}
+
+// FromCandles parses Hyperliquid candles into bars.
+func FromCandles(candles []hyperliquid.Candle) []Bar {
+	bars := make([]Bar, 0, len(candles))
+	for _, c := range candles {
+		bars = append(bars, Bar{
+			Time:   c.StartTime,
+			Open:   parseF(c.Open),
+			High:   parseF(c.High),
+			Low:    parseF(c.Low),
+			Close:  parseF(c.Close),
+			Volume: parseF(c.Volume),
+		})
+	}
+	return bars
+}
+
+func last(series []float64) *float64 {
+	v, ok := Last(series)
+	if !ok {
+		return nil
+	}
+	return ptr(round(v))
+}
+
+func ptr(v float64) *float64 {
+	return &v
+}
+
+func round(v float64) float64 {
+	if v == 0 || math.IsNaN(v) || math.IsInf(v, 0) {
+		return 0
+	}
+	r, _ := strconv.ParseFloat(strconv.FormatFloat(v, 'g', 6, 64), 64)
+	return r
+}
+
+func roundAll(vs []float64) {
+	for i := range vs {
+		vs[i] = round(vs[i])
+	}
+}
@@ -0,0 +1,218 @@
+// Package indicators computes technical indicators and order book features in Go, so the agent gets
+// precomputed, reproducible numbers instead of deriving them from raw candles.
+//
+// Series functions return only the defined values: a period-n indicator over m inputs yields m-n+1
+// outputs (nil when m < n), and the last element is the current value.
+package indicators
+
+import "math"
+
+// Bar is one OHLCV candle.
+type Bar struct {
+	Time   int64
+	Open   float64
+	High   float64
+	Low    float64
+	Close  float64
+	Volume float64
+}
+
+// SMA is the simple moving average. A NaN value makes the averages of the windows holding it NaN, but not
+// the ones after it.
+func SMA(values []float64, period int) []float64 {
+	if period <= 0 || len(values) < period {
+		return nil
+	}
+	out := make([]float64, 0, len(values)-period+1)
+	sum := 0.0
+	nans := 0
+	for i, v := range values {
+		if math.IsNaN(v) {
+			nans++
+		} else {
+			sum += v
+		}
+		if i >= period {
+			if old := values[i-period]; math.IsNaN(old) {
+				nans--
+			} else {
+				sum -= old
+			}
+		}
+		if i < period-1 {
+			continue
+		}
+		if nans > 0 {
+			out = append(out, math.NaN())
+		} else {
+			out = append(out, sum/float64(period))
+		}
+	}
+	return out
+}
+
+// EMA is the exponential moving average seeded with the SMA of the first period values.
+func EMA(values []float64, period int) []float64 {
+	if period <= 0 || len(values) < period {
+		return nil
+	}
+	k := 2 / float64(period+1)
+	out := make([]float64, 0, len(values)-period+1)
+	prev := SMA(values[:period], period)[0]
+	out = append(out, prev)
+	for _, v := range values[period:] {
+		prev = v*k + prev*(1-k)
+		out = append(out, prev)
+	}
+	return out
+}
+
+// RSI is Wilder's relative strength index. It needs period+1 values.
+func RSI(values []float64, period int) []float64 {
+	if period <= 0 || len(values) <= period {
+		return nil
+	}
+	var gain, loss float64
+	for i := 1; i <= period; i++ {
+		d := values[i] - values[i-1]
+		gain += math.Max(d, 0)
+		loss += math.Max(-d, 0)
+	}
+	gain /= float64(period)
+	loss /= float64(period)
+
+	out := make([]float64, 0, len(values)-period)
+	out = append(out, rsi(gain, loss))
+	for i := period + 1; i < len(values); i++ {
+		d := values[i] - values[i-1]
+		gain = (gain*float64(period-1) + math.Max(d, 0)) / float64(period)
+		loss = (loss*float64(period-1) + math.Max(-d, 0)) / float64(period)
+		out = append(out, rsi(gain, loss))
+	}
+	return out
+}
+
+func rsi(gain, loss float64) float64 {
+	if loss == 0 {
+		if gain == 0 {
+			return 50
+		}
+		return 100
+	}
+	return 100 - 100/(1+gain/loss)
+}
+
+// ATR is Wilder's average true range. It needs period+1 bars.
+func ATR(bars []Bar, period int) []float64 {
+	if period <= 0 || len(bars) <= period {
+		return nil
+	}
+	tr := make([]float64, 0, len(bars)-1)
+	for i := 1; i < len(bars); i++ {
+		prevClose := bars[i-1].Close
+		tr = append(tr, math.Max(bars[i].High-bars[i].Low, math.Max(math.Abs(bars[i].High-prevClose), math.Abs(bars[i].Low-prevClose))))
+	}
+
+	prev := SMA(tr[:period], period)[0]
+	out := make([]float64, 0, len(tr)-period+1)
+	out = append(out, prev)
+	for _, v := range tr[period:] {
+		prev = (prev*float64(period-1) + v) / float64(period)
+		out = append(out, prev)
+	}
+	return out
+}
+
+// VWAP is the volume-weighted average typical price over all bars. It is zero when there is no volume.
+func VWAP(bars []Bar) float64 {
+	var pv, vol float64
+	for _, b := range bars {
+		pv += (b.High + b.Low + b.Close) / 3 * b.Volume
+		vol += b.Volume
+	}
+	if vol == 0 {
+		return 0
+	}
+	return pv / vol
+}
+
+// Bands are Bollinger bands. PercentB is the last close's position within them (0 = lower, 1 = upper)
+// and Width is (upper-lower)/middle.
+type Bands struct {
+	Upper    float64 `json:"upper"`
+	Middle   float64 `json:"middle"`
+	Lower    float64 `json:"lower"`
+	Width    float64 `json:"width"`
+	PercentB float64 `json:"percentB"`
+}
+
+// Bollinger computes the current bands over the last period closes with k standard deviations.
+func Bollinger(values []float64, period int, k float64) (Bands, bool) {
+	if period <= 0 || len(values) < period {
+		return Bands{}, false
+	}
+	window := values[len(values)-period:]
+	mean := SMA(window, period)[0]
+	variance := 0.0
+	for _, v := range window {
+		variance += (v - mean) * (v - mean)
+	}
+	sd := math.Sqrt(variance / float64(period))
+
+	b := Bands{Upper: mean + k*sd, Middle: mean, Lower: mean - k*sd}
+	if mean != 0 {
+		b.Width = (b.Upper - b.Lower) / mean
+	}
+	b.PercentB = 0.5
+	if b.Upper != b.Lower {
+		b.PercentB = (values[len(values)-1] - b.Lower) / (b.Upper - b.Lower)
+	}
+	return b, true
+}
+
+// MACDValue is the current MACD line, its signal line and their difference.
+type MACDValue struct {
+	MACD      float64 `json:"macd"`
+	Signal    float64 `json:"signal"`
+	Histogram float64 `json:"histogram"`
+}
+
+// MACD computes the current MACD(fast, slow, signal). It needs slow+signal-1 values.
+func MACD(values []float64, fast, slow, signal int) (MACDValue, bool) {
+	if fast <= 0 || fast >= slow || signal <= 0 {
+		return MACDValue{}, false
+	}
+	slowEMA := EMA(values, slow)
+	if len(slowEMA) < signal {
+		return MACDValue{}, false
+	}
+	fastEMA := EMA(values, fast)
+	// align the fast series to the slow one: both end at the last value
+	fastEMA = fastEMA[len(fastEMA)-len(slowEMA):]
+
+	line := make([]float64, len(slowEMA))
+	for i := range slowEMA {
+		line[i] = fastEMA[i] - slowEMA[i]
+	}
+	sig := EMA(line, signal)
+	m := line[len(line)-1]
+	s := sig[len(sig)-1]
+	return MACDValue{MACD: m, Signal: s, Histogram: m - s}, true
+}
+
+// Last returns the last element of a series.
+func Last(series []float64) (float64, bool) {
+	if len(series) == 0 {
+		return 0, false
+	}
+	return series[len(series)-1], true
+}
+
+// Closes extracts closing prices.
+func Closes(bars []Bar) []float64 {
+	out := make([]float64, len(bars))
+	for i, b := range bars {
+		out[i] = b.Close
+	}
+	return out
+}
@@ -0,0 +1,207 @@
+package indicators
+
+import (
+	"math"
+	"testing"
+)
+
+const eps = 1e-9
+
+func near(a, b float64) bool {
+	if math.IsNaN(a) || math.IsNaN(b) {
+		return math.IsNaN(a) && math.IsNaN(b)
+	}
+	return math.Abs(a-b) < eps
+}
+
+func sameSeries(t *testing.T, got, want []float64) {
+	t.Helper()
+	if len(got) != len(want) || (got == nil) != (want == nil) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+	for i := range want {
+		if !near(got[i], want[i]) {
+			t.Fatalf("got %v, want %v", got, want)
+		}
+	}
+}
+
+func TestSMA(t *testing.T) {
+	nan := math.NaN()
+	tests := []struct {
+		name   string
+		values []float64
+		period int
+		want   []float64
+	}{
+		{"window of three", []float64{1, 2, 3, 4, 5}, 3, []float64{2, 3, 4}},
+		{"period equals length", []float64{1, 2, 3, 4, 5}, 5, []float64{3}},
+		{"period one", []float64{1, 2}, 1, []float64{1, 2}},
+		{"too few values", []float64{1, 2, 3, 4, 5}, 6, nil},
+		{"zero period", []float64{1, 2}, 0, nil},
+		{"negative period", []float64{1, 2}, -1, nil},
+		{"empty", nil, 3, nil},
+		{"NaN only spoils its windows", []float64{1, nan, 3, 4, 5}, 2, []float64{nan, nan, 3.5, 4.5}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sameSeries(t, SMA(tt.values, tt.period), tt.want)
+		})
+	}
+}
+
+func TestEMA(t *testing.T) {
+	tests := []struct {
+		name   string
+		values []float64
+		period int
+		want   []float64
+	}{
+		{"seeded with the SMA", []float64{1, 2, 3, 4, 5}, 3, []float64{2, 3, 4}},
+		{"constant", []float64{7, 7, 7, 7}, 2, []float64{7, 7, 7}},
+		{"period equals length", []float64{1, 2, 3}, 3, []float64{2}},
+		{"too few values", []float64{1, 2}, 3, nil},
+		{"zero period", []float64{1, 2}, 0, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sameSeries(t, EMA(tt.values, tt.period), tt.want)
+		})
+	}
+}
+
+func TestRSI(t *testing.T) {
+	tests := []struct {
+		name   string
+		values []float64
+		period int
+		want   []float64
+	}{
+		{"only gains", []float64{1, 2, 3, 4}, 3, []float64{100}},
+		{"only losses", []float64{4, 3, 2, 1}, 3, []float64{0}},
+		{"flat", []float64{5, 5, 5, 5}, 3, []float64{50}},
+		{"mixed", []float64{44, 45, 44, 46}, 3, []float64{75}},
+		// Wilder smoothing: gain (1*2+0)/3, loss (1/3*2+3)/3
+		{"smoothed", []float64{44, 45, 44, 46, 43}, 3, []float64{75, 100 - 100/(1+(2.0/3)/(11.0/9))}},
+		{"needs period plus one", []float64{1, 2, 3}, 3, nil},
+		{"zero period", []float64{1, 2, 3}, 0, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sameSeries(t, RSI(tt.values, tt.period), tt.want)
+		})
+	}
+}
+
+func TestATR(t *testing.T) {
+	bars := []Bar{
+		{Close: 10},
+		{High: 12, Low: 9, Close: 11},  // true range 3: the bar's own range
+		{High: 13, Low: 11, Close: 12}, // 2: high against the previous close
+		{High: 12, Low: 8, Close: 9},   // 4
+	}
+	tests := []struct {
+		name   string
+		bars   []Bar
+		period int
+		want   []float64
+	}{
+		{"wilder smoothing", bars, 2, []float64{2.5, 3.25}},
+		{"period of one", bars, 1, []float64{3, 2, 4}},
+		{"needs period plus one", bars, 4, nil},
+		{"zero period", bars, 0, nil},
+		{"gap counts", []Bar{{Close: 10}, {High: 15, Low: 14, Close: 15}}, 1, []float64{5}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sameSeries(t, ATR(tt.bars, tt.period), tt.want)
+		})
+	}
+}
+
+func TestVWAP(t *testing.T) {
+	tests := []struct {
+		name string
+		bars []Bar
+		want float64
+	}{
+		{"weighted typical price", []Bar{{High: 3, Low: 1, Close: 2, Volume: 1}, {High: 6, Low: 3, Close: 6, Volume: 3}}, 4.25},
+		{"no volume", []Bar{{High: 3, Low: 1, Close: 2}}, 0},
+		{"empty", nil, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := VWAP(tt.bars); !near(got, tt.want) {
+				t.Fatalf("VWAP = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBollinger(t *testing.T) {
+	tests := []struct {
+		name   string
+		values []float64
+		period int
+		want   Bands
+		ok     bool
+	}{
+		{"population deviation", []float64{2, 4, 4, 4, 5, 5, 7, 9}, 8, Bands{Upper: 9, Middle: 5, Lower: 1, Width: 1.6, PercentB: 1}, true},
+		{"last period values only", []float64{100, 2, 4, 4, 4, 5, 5, 7, 9}, 8, Bands{Upper: 9, Middle: 5, Lower: 1, Width: 1.6, PercentB: 1}, true},
+		{"flat", []float64{3, 3, 3}, 3, Bands{Upper: 3, Middle: 3, Lower: 3, PercentB: 0.5}, true},
+		{"zero mean", []float64{-1, 1}, 2, Bands{Upper: 2, Middle: 0, Lower: -2, PercentB: 0.75}, true},
+		{"too few values", []float64{1, 2}, 3, Bands{}, false},
+		{"zero period", []float64{1, 2}, 0, Bands{}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := Bollinger(tt.values, tt.period, 2)
+			if ok != tt.ok || !near(got.Upper, tt.want.Upper) || !near(got.Middle, tt.want.Middle) || !near(got.Lower, tt.want.Lower) ||
+				!near(got.Width, tt.want.Width) || !near(got.PercentB, tt.want.PercentB) {
+				t.Fatalf("Bollinger = %+v, %v, want %+v, %v", got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
+
+func TestMACD(t *testing.T) {
+	linear := make([]float64, 30)
+	for i := range linear {
+		linear[i] = float64(i)
+	}
+	flat := []float64{5, 5, 5, 5, 5, 5}
+
+	tests := []struct {
+		name               string
+		values             []float64
+		fast, slow, signal int
+		want               MACDValue
+		ok                 bool
+	}{
+		// An EMA of a linear series lags it by (period-1)/2, so the line is the difference of the lags.
+		{"linear", linear, 3, 5, 3, MACDValue{MACD: 1, Signal: 1}, true},
+		{"flat", flat, 2, 3, 2, MACDValue{}, true},
+		{"exactly slow+signal-1 values", flat[:4], 2, 3, 2, MACDValue{}, true},
+		{"too few values", flat[:3], 2, 3, 2, MACDValue{}, false},
+		{"fast not below slow", flat, 3, 3, 2, MACDValue{}, false},
+		{"zero fast", flat, 0, 3, 2, MACDValue{}, false},
+		{"zero signal", flat, 2, 3, 0, MACDValue{}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := MACD(tt.values, tt.fast, tt.slow, tt.signal)
+			if ok != tt.ok || !near(got.MACD, tt.want.MACD) || !near(got.Signal, tt.want.Signal) || !near(got.Histogram, tt.want.Histogram) {
+				t.Fatalf("MACD = %+v, %v, want %+v, %v", got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
+
+func TestLast(t *testing.T) {
+	if _, ok := Last(nil); ok {
+		t.Error("Last of an empty series should not be ok")
+	}
+	if v, ok := Last([]float64{1, 2}); !ok || v != 2 {
+		t.Errorf("Last = %v, %v, want 2, true", v, ok)
+	}
+}
@@ -0,0 +1,85 @@
+package indicators
+
+import (
+	"math"
+	"sort"
+)
+
+// Levels are support and resistance prices around the last close, nearest first.
+type Levels struct {
+	Support    []float64 `json:"support"`
+	Resistance []float64 `json:"resistance"`
+}
+
+// SupportResistance finds swing highs and lows (a bar whose high/low is the extreme of the `width`
+// bars on either side), merges swings within tolerance (a fraction of price) into one level and
+// returns up to limit levels below and above the last close.
+func SupportResistance(bars []Bar, width int, tolerance float64, limit int) Levels {
+	if width <= 0 || len(bars) < 2*width+1 {
+		return Levels{}
+	}
+
+	var swings []float64
+	for i := width; i < len(bars)-width; i++ {
+		isHigh, isLow := true, true
+		for j := i - width; j <= i+width; j++ {
+			if j == i {
+				continue
+			}
+			if bars[j].High >= bars[i].High {
+				isHigh = false
+			}
+			if bars[j].Low <= bars[i].Low {
+				isLow = false
+			}
+		}
+		if isHigh {
+			swings = append(swings, bars[i].High)
+		}
+		if isLow {
+			swings = append(swings, bars[i].Low)
+		}
+	}
+
+	price := bars[len(bars)-1].Close
+	var out Levels
+	for _, lvl := range cluster(swings, tolerance) {
+		if lvl < price {
+			out.Support = append(out.Support, lvl)
+		} else {
+			out.Resistance = append(out.Resistance, lvl)
+		}
+	}
+	sort.Sort(sort.Reverse(sort.Float64Slice(out.Support)))
+	sort.Float64s(out.Resistance)
+	if len(out.Support) > limit {
+		out.Support = out.Support[:limit]
+	}
+	if len(out.Resistance) > limit {
+		out.Resistance = out.Resistance[:limit]
+	}
+	return out
+}
+
+// cluster merges sorted prices closer than tolerance (relative) to the running cluster mean.
+func cluster(prices []float64, tolerance float64) []float64 {
+	if len(prices) == 0 {
+		return nil
+	}
+	sorted := append([]float64(nil), prices...)
+	sort.Float64s(sorted)
+
+	var out []float64
+	sum, n := sorted[0], 1.0
+	for _, p := range sorted[1:] {
+		mean := sum / n
+		if math.Abs(p-mean) <= mean*tolerance {
+			sum += p
+			n++
+			continue
+		}
+		out = append(out, mean)
+		sum, n = p, 1
+	}
+	return append(out, sum/n)
+}
@@ -6,9 +6,11 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 	"time"
 
+	"deepseek-trader/agent"
 	"deepseek-trader/api"
 	"deepseek-trader/api/handlers"
 	"deepseek-trader/bot"
@@ -16,6 +18,8 @@ import (
 	"deepseek-trader/db"
 	"deepseek-trader/hyperliquid"
 	"deepseek-trader/logger"
+	"deepseek-trader/marketdata"
+	"deepseek-trader/replay"
 	"deepseek-trader/repository"
 	"deepseek-trader/services"
 )
@@ -49,12 +53,33 @@ func main() {
 
 	hlClient := hyperliquid.NewClient(cfg)
 
-	walletSvc := services.NewWalletService(repos.Wallets, hlClient, cfg)
-	tradesSvc := services.NewTradesService(repos.Trades, hlClient)
+	walletSvc := services.NewWalletService(repos.Wallets, cfg)
+	tradesSvc := services.NewTradesService(repos.Trades)
+	ordersSvc := services.NewOrdersService(repos.Orders)
 	statsSvc := services.NewStatsService(repos.Stats, repos.Trades)
-	botSvc := bot.NewService(hlClient, tradesSvc, statsSvc, cfg, log)
+	botsSvc := services.NewBotsService(repos.Bots)
+	marketSvc := services.NewMarketService(repos.Market)
+	callsSvc := services.NewAgentCallsService(repos.Calls)
+	promptsSvc := services.NewPromptsService(repos.Prompts)
+	bots := bot.NewManager(hlClient, walletSvc, botsSvc, tradesSvc, ordersSvc, statsSvc, callsSvc, promptsSvc, repos.Paper, cfg, log)
+	bots.Resume(mainCtx)
 	authSvc := services.NewAuthService(repos.Users, cfg)
-	handlers := handlers.New(walletSvc, botSvc, statsSvc, tradesSvc, authSvc, hlClient)
+	replayer := replay.NewReplayer(callsSvc, tradesSvc, botsSvc, promptsSvc, cfg, log)
+	handlers := handlers.New(walletSvc, bots, statsSvc, tradesSvc, marketSvc, callsSvc, promptsSvc, replayer, authSvc, hlClient)
+
+	if cfg.MarketRecord {
+		recorder, err := marketdata.NewRecorder(hlClient, marketSvc, marketdata.Config{
+			Coins:         agent.Coins,
+			Intervals:     strings.Split(cfg.MarketIntervals, ","),
+			History:       time.Duration(cfg.MarketHistory) * 24 * time.Hour,
+			BackfillEvery: time.Duration(cfg.MarketBackfill) * time.Minute,
+			BookEvery:     time.Duration(cfg.MarketBookEvery) * time.Second,
+		}, log)
+		if err != nil {
+			log.Sugar().Fatalw("invalid market data config", "error", err)
+		}
+		go recorder.Run(mainCtx)
+	}
 
 	router := api.NewRouter(handlers, cfg)
 
@@ -77,5 +102,5 @@ func main() {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 	_ = srv.Shutdown(ctx)
-	botSvc.Stop()
+	bots.StopAll()
 }
@@ -0,0 +1,170 @@
+// Package marketdata keeps a local Postgres history of candles and order books: it backfills candle
+// gaps from candleSnapshot and samples L2 books on a timer.
+package marketdata
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"deepseek-trader/hyperliquid"
+	"deepseek-trader/services"
+
+	"go.uber.org/zap"
+)
+
+// maxCandlesPerRequest is the most candles candleSnapshot returns; it also bounds how far back the
+// exchange serves history for an interval.
+const maxCandlesPerRequest = 5000
+
+// Source is the market data the recorder pulls: the live client.
+type Source interface {
+	CandleSnapshot(ctx context.Context, coin, interval string, startTime, endTime int64) ([]hyperliquid.Candle, error)
+	L2Book(ctx context.Context, coin string) (hyperliquid.OrderBookSnapshot, error)
+}
+
+type Config struct {
+	Coins     []string
+	Intervals []string
+	// History is how far back candles are kept complete.
+	History       time.Duration
+	BackfillEvery time.Duration
+	// BookEvery is the L2 sampling period; zero disables book sampling.
+	BookEvery time.Duration
+}
+
+type Recorder struct {
+	src    Source
+	market *services.MarketService
+	cfg    Config
+	log    *zap.Logger
+
+	mx sync.Mutex
+	// empty remembers gaps the exchange had no candles for (before listing, outages) so they are
+	// not refetched every pass.
+	empty map[string]bool
+}
+
+func NewRecorder(src Source, market *services.MarketService, cfg Config, log *zap.Logger) (*Recorder, error) {
+	for _, interval := range cfg.Intervals {
+		if _, ok := hyperliquid.IntervalDuration(interval); !ok {
+			return nil, fmt.Errorf("unknown candle interval %q", interval)
+		}
+	}
+	if cfg.BackfillEvery <= 0 {
+		return nil, errors.New("backfill period must be positive")
+	}
+	return &Recorder{src: src, market: market, cfg: cfg, log: log, empty: make(map[string]bool)}, nil
+}
+
+// Run backfills immediately and then every BackfillEvery, and samples books every BookEvery,
+// until ctx is done.
+func (r *Recorder) Run(ctx context.Context) {
+	if r.cfg.BookEvery > 0 {
+		go r.sampleBooks(ctx)
+	}
+
+	ticker := time.NewTicker(r.cfg.BackfillEvery)
+	defer ticker.Stop()
+	for {
+		r.Backfill(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Backfill fills every missing candle within History for all coins and intervals and refreshes the
+// latest bars, which may still have been forming when last stored.
+func (r *Recorder) Backfill(ctx context.Context) {
+	now := time.Now()
+	for _, coin := range r.cfg.Coins {
+		for _, interval := range r.cfg.Intervals {
+			if ctx.Err() != nil {
+				return
+			}
+			if err := r.backfill(ctx, coin, interval, now); err != nil {
+				r.log.Sugar().Errorw("candle backfill failed", "coin", coin, "interval", interval, "error", err)
+			}
+		}
+	}
+}
+
+func (r *Recorder) backfill(ctx context.Context, coin, interval string, now time.Time) error {
+	d, _ := hyperliquid.IntervalDuration(interval)
+	step := d.Milliseconds()
+	to := now.UnixMilli()
+	to -= to % step
+	from := now.Add(-min(r.cfg.History, maxCandlesPerRequest*d)).UnixMilli()
+	from = from - from%step + step // first whole bar inside the window
+
+	gaps, err := r.market.Gaps(ctx, coin, interval, from, to, step)
+	if err != nil {
+		return err
+	}
+	// the last stored bars may have been saved while still forming
+	gaps = append(gaps, [2]int64{max(from, to-step), to})
+
+	filled := 0
+	for _, g := range gaps {
+		key := fmt.Sprintf("%s/%s/%d-%d", coin, interval, g[0], g[1])
+		if r.isEmpty(key) {
+			continue
+		}
+		for start := g[0]; start <= g[1]; start += maxCandlesPerRequest * step {
+			end := min(start+(maxCandlesPerRequest-1)*step, g[1])
+			candles, err := r.src.CandleSnapshot(ctx, coin, interval, start, end+step-1)
+			if err != nil {
+				return err
+			}
+			if len(candles) == 0 {
+				r.markEmpty(key)
+				continue
+			}
+			if err := r.market.SaveCandles(ctx, candles); err != nil {
+				return err
+			}
+			filled += len(candles)
+		}
+	}
+	r.log.Sugar().Debugw("candles backfilled", "coin", coin, "interval", interval, "gaps", len(gaps)-1, "candles", filled)
+	return nil
+}
+
+func (r *Recorder) sampleBooks(ctx context.Context) {
+	ticker := time.NewTicker(r.cfg.BookEvery)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		for _, coin := range r.cfg.Coins {
+			book, err := r.src.L2Book(ctx, coin)
+			if err != nil {
+				r.log.Sugar().Errorw("failed to sample book", "coin", coin, "error", err)
+				continue
+			}
+			if err := r.market.SaveBook(ctx, book); err != nil {
+				r.log.Sugar().Errorw("failed to store book", "coin", coin, "error", err)
+			}
+		}
+	}
+}
+
+func (r *Recorder) isEmpty(key string) bool {
+	r.mx.Lock()
+	defer r.mx.Unlock()
+	return r.empty[key]
+}
+
+func (r *Recorder) markEmpty(key string) {
+	r.mx.Lock()
+	defer r.mx.Unlock()
+	r.empty[key] = true
+}
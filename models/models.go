@@ -1,6 +1,9 @@
 package models
 
-import "time"
+import (
+	"encoding/json"
+	"time"
+)
 
 type Wallet struct {
 	ID        int64     `db:"id" json:"id"`
@@ -12,6 +15,7 @@ type Wallet struct {
 
 type Trade struct {
 	ID        int64     `db:"id" json:"id"`
+	UserID    *int64    `db:"user_id" json:"userId,omitempty"`
 	Symbol    string    `db:"symbol" json:"symbol"`
 	Side      string    `db:"side" json:"side"`
 	Qty       float64   `db:"qty" json:"qty"`
@@ -21,17 +25,80 @@ type Trade struct {
 }
 
 type Decision struct {
-	ID         int64     `db:"id" json:"id"`
-	Action     string    `db:"action" json:"action"`
-	Symbol     string    `db:"symbol" json:"symbol"`
-	Size       float64   `db:"size" json:"size"`
-	OrderType  string    `db:"order_type" json:"order"`
-	LimitPrice float64   `db:"limit_price" json:"limitPrice"`
-	TP1        float64   `db:"tp1" json:"tp1"`
-	TP2        float64   `db:"tp2" json:"tp2"`
-	TP3        float64   `db:"tp3" json:"tp3"`
-	SL         float64   `db:"sl" json:"sl"`
+	ID         int64   `db:"id" json:"id"`
+	UserID     *int64  `db:"user_id" json:"userId,omitempty"`
+	Action     string  `db:"action" json:"action"`
+	Symbol     string  `db:"symbol" json:"symbol"`
+	Size       float64 `db:"size" json:"size"`
+	OrderType  string  `db:"order_type" json:"order"`
+	LimitPrice float64 `db:"limit_price" json:"limitPrice"`
+	Fraction   float64 `db:"fraction" json:"fraction,omitempty"` // share of the position a reduce closes
+	TP1        float64 `db:"tp1" json:"tp1"`
+	TP2        float64 `db:"tp2" json:"tp2"`
+	TP3        float64 `db:"tp3" json:"tp3"`
+	SL         float64 `db:"sl" json:"sl"`
+	Status     string  `db:"status" json:"status"`
+	Reason     string  `db:"reason" json:"reason"`
+	// PromptVersionID is the prompt version the decision was made with; nil for the built-in prompt.
+	PromptVersionID *int64 `db:"prompt_version_id" json:"promptVersionId,omitempty"`
+	// AgentCallID is the archived agent call the decision came from, shared by a cycle's decisions.
+	AgentCallID *int64 `db:"agent_call_id" json:"agentCallId,omitempty"`
+	// Confidence, Scores (symbol -> 0-100, as JSON) and Rationale are the agent's own assessment.
+	Confidence float64         `db:"confidence" json:"confidence"`
+	Scores     json.RawMessage `db:"scores" json:"scores,omitempty"`
+	Rationale  string          `db:"rationale" json:"rationale,omitempty"`
+	CreatedAt  time.Time       `db:"created_at" json:"createdAt"`
+}
+
+const (
+	DecisionPending  = "pending"
+	DecisionSkipped  = "skipped"
+	DecisionExecuted = "executed"
+	DecisionPartial  = "partial"
+	DecisionRejected = "rejected"
+	DecisionFailed   = "failed"
+)
+
+const (
+	OrderPending  = "pending"
+	OrderResting  = "resting"
+	OrderFilled   = "filled"
+	OrderRejected = "rejected"
+	OrderFailed   = "failed"
+	OrderCanceled = "canceled"
+)
+
+// Order kinds: the entry leg, the reduce-only exits attached to it, and reduce-only orders that
+// close or reduce a position on the agent's decision.
+const (
+	OrderKindEntry = "entry"
+	OrderKindExit  = "exit"
+	OrderKindTP1   = "tp1"
+	OrderKindTP2   = "tp2"
+	OrderKindTP3   = "tp3"
+	OrderKindSL    = "sl"
+)
+
+type Order struct {
+	ID          int64   `db:"id" json:"id"`
+	UserID      *int64  `db:"user_id" json:"userId,omitempty"`
+	DecisionID  *int64  `db:"decision_id" json:"decisionId,omitempty"`
+	Symbol      string  `db:"symbol" json:"symbol"`
+	Side        string  `db:"side" json:"side"`
+	OrderType   string  `db:"order_type" json:"orderType"`
+	Kind        string  `db:"kind" json:"kind"`
+	Size        float64 `db:"size" json:"size"`
+	Price       float64 `db:"price" json:"price"`
+	TriggerPx   float64 `db:"trigger_price" json:"triggerPrice,omitempty"`
+	ExchangeOID *int64  `db:"exchange_oid" json:"exchangeOid,omitempty"`
+	// Cloid is the client order id the order is sent with, derived from its decision and leg.
+	Cloid      string    `db:"cloid" json:"cloid,omitempty"`
+	Status     string    `db:"status" json:"status"`
+	FilledSize float64   `db:"filled_size" json:"filledSize"`
+	AvgPrice   float64   `db:"avg_price" json:"avgPrice"`
+	Error      string    `db:"error" json:"error,omitempty"`
 	CreatedAt  time.Time `db:"created_at" json:"createdAt"`
+	UpdatedAt  time.Time `db:"updated_at" json:"updatedAt"`
 }
 
 type Stats struct {
@@ -48,3 +115,135 @@ type User struct {
 	PasswordHash string    `db:"password_hash" json:"-"`
 	CreatedAt    time.Time `db:"created_at" json:"createdAt"`
 }
+
+type PaperAccount struct {
+	ID             int64     `db:"id" json:"id"`
+	Name           string    `db:"name" json:"name"`
+	InitialBalance float64   `db:"initial_balance" json:"initialBalance"`
+	Cash           float64   `db:"cash" json:"cash"`
+	RealizedPnL    float64   `db:"realized_pnl" json:"realizedPnl"`
+	FeesPaid       float64   `db:"fees_paid" json:"feesPaid"`
+	CreatedAt      time.Time `db:"created_at" json:"createdAt"`
+	UpdatedAt      time.Time `db:"updated_at" json:"updatedAt"`
+}
+
+type PaperPosition struct {
+	AccountID  int64     `db:"account_id" json:"accountId"`
+	Coin       string    `db:"coin" json:"coin"`
+	Size       float64   `db:"size" json:"size"` // signed: >0 long, <0 short
+	EntryPrice float64   `db:"entry_price" json:"entryPrice"`
+	UpdatedAt  time.Time `db:"updated_at" json:"updatedAt"`
+}
+
+// PaperLeverage is the leverage a paper account set for a coin; coins without one use the default.
+type PaperLeverage struct {
+	AccountID int64     `db:"account_id" json:"accountId"`
+	Coin      string    `db:"coin" json:"coin"`
+	Leverage  int       `db:"leverage" json:"leverage"`
+	Isolated  bool      `db:"isolated" json:"isolated"`
+	UpdatedAt time.Time `db:"updated_at" json:"updatedAt"`
+}
+
+type PaperOrder struct {
+	ID         int64     `db:"id" json:"id"`
+	AccountID  int64     `db:"account_id" json:"accountId"`
+	Coin       string    `db:"coin" json:"coin"`
+	IsBuy      bool      `db:"is_buy" json:"isBuy"`
+	Size       float64   `db:"size" json:"size"`
+	LimitPrice float64   `db:"limit_price" json:"limitPrice"`
+	TriggerPx  float64   `db:"trigger_price" json:"triggerPrice"`
+	Tpsl       string    `db:"tpsl" json:"tpsl"`
+	ReduceOnly bool      `db:"reduce_only" json:"reduceOnly"`
+	Cloid      string    `db:"cloid" json:"cloid,omitempty"`
+	Status     string    `db:"status" json:"status"`
+	FilledSize float64   `db:"filled_size" json:"filledSize"`
+	AvgPrice   float64   `db:"avg_price" json:"avgPrice"`
+	CreatedAt  time.Time `db:"created_at" json:"createdAt"`
+	UpdatedAt  time.Time `db:"updated_at" json:"updatedAt"`
+}
+
+type PaperFill struct {
+	ID            int64     `db:"id" json:"id"`
+	AccountID     int64     `db:"account_id" json:"accountId"`
+	OrderID       int64     `db:"order_id" json:"orderId"`
+	Coin          string    `db:"coin" json:"coin"`
+	IsBuy         bool      `db:"is_buy" json:"isBuy"`
+	Size          float64   `db:"size" json:"size"`
+	Price         float64   `db:"price" json:"price"`
+	Fee           float64   `db:"fee" json:"fee"`
+	StartPosition float64   `db:"start_position" json:"startPosition"`
+	ClosedPnL     float64   `db:"closed_pnl" json:"closedPnl"`
+	CreatedAt     time.Time `db:"created_at" json:"createdAt"`
+}
+
+// Bot is a user's bot: its configuration (bot.Config as JSON) and whether it should be running.
+type Bot struct {
+	UserID  int64  `db:"user_id" json:"userId"`
+	Config  []byte `db:"config" json:"-"`
+	Enabled bool   `db:"enabled" json:"enabled"`
+	// TrippedAt is set when the circuit breaker or kill switch stopped the bot; it stays stopped until re-armed.
+	TrippedAt  *time.Time `db:"tripped_at" json:"trippedAt,omitempty"`
+	TripReason string     `db:"trip_reason" json:"tripReason,omitempty"`
+	CreatedAt  time.Time  `db:"created_at" json:"createdAt"`
+	UpdatedAt  time.Time  `db:"updated_at" json:"updatedAt"`
+}
+
+// Candle is a stored OHLCV bar. Times are unix milliseconds, like Hyperliquid's candles.
+type Candle struct {
+	Coin      string  `db:"coin" json:"coin"`
+	Interval  string  `db:"interval" json:"interval"`
+	StartTime int64   `db:"start_time" json:"startTime"`
+	EndTime   int64   `db:"end_time" json:"endTime"`
+	Open      float64 `db:"open" json:"open"`
+	High      float64 `db:"high" json:"high"`
+	Low       float64 `db:"low" json:"low"`
+	Close     float64 `db:"close" json:"close"`
+	Volume    float64 `db:"volume" json:"volume"`
+	NumTrades int     `db:"num_trades" json:"numTrades"`
+}
+
+// BookSnapshot is a sampled L2 book. Bids and asks are the exchange's level arrays as JSON.
+type BookSnapshot struct {
+	ID        int64           `db:"id" json:"id"`
+	Coin      string          `db:"coin" json:"coin"`
+	Time      int64           `db:"time" json:"time"`
+	Bids      json.RawMessage `db:"bids" json:"bids"`
+	Asks      json.RawMessage `db:"asks" json:"asks"`
+	CreatedAt time.Time       `db:"created_at" json:"createdAt"`
+}
+
+// AgentCall archives one model call of a decision cycle: the snapshot the model saw, the rendered
+// prompts, its raw answers and what the provider reported. Listings leave the large fields empty.
+type AgentCall struct {
+	ID               int64           `db:"id" json:"id"`
+	UserID           *int64          `db:"user_id" json:"userId,omitempty"`
+	DecisionID       *int64          `db:"decision_id" json:"decisionId,omitempty"` // the cycle's first decision
+	Provider         string          `db:"provider" json:"provider"`
+	Model            string          `db:"model" json:"model"`
+	Snapshot         json.RawMessage `db:"snapshot" json:"snapshot,omitempty"`
+	SystemPrompt     string          `db:"system_prompt" json:"systemPrompt,omitempty"`
+	UserPrompt       string          `db:"user_prompt" json:"userPrompt,omitempty"`
+	Response         string          `db:"response" json:"response,omitempty"`
+	RepairPrompt     string          `db:"repair_prompt" json:"repairPrompt,omitempty"`
+	RepairResponse   string          `db:"repair_response" json:"repairResponse,omitempty"`
+	LatencyMS        int64           `db:"latency_ms" json:"latencyMs"`
+	PromptTokens     int             `db:"prompt_tokens" json:"promptTokens"`
+	CompletionTokens int             `db:"completion_tokens" json:"completionTokens"`
+	Error            string          `db:"error" json:"error,omitempty"`
+	CreatedAt        time.Time       `db:"created_at" json:"createdAt"`
+}
+
+// PromptVersion is a stored pair of prompt templates for a user's bot. Versions are numbered per
+// bot; at most one is active, and none means the built-in templates.
+type PromptVersion struct {
+	ID             int64      `db:"id" json:"id"`
+	UserID         int64      `db:"user_id" json:"userId"`
+	Name           string     `db:"name" json:"name"`
+	Version        int        `db:"version" json:"version"`
+	SystemTemplate string     `db:"system_template" json:"systemTemplate"`
+	UserTemplate   string     `db:"user_template" json:"userTemplate"`
+	Note           string     `db:"note" json:"note"`
+	Active         bool       `db:"active" json:"active"`
+	ActivatedAt    *time.Time `db:"activated_at" json:"activatedAt,omitempty"`
+	CreatedAt      time.Time  `db:"created_at" json:"createdAt"`
+}
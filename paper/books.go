@@ -0,0 +1,71 @@
+package paper
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"deepseek-trader/hyperliquid"
+)
+
+// RecordedBooks replays captured order books: L2Book returns the latest snapshot for a coin at or
+// before the cursor set with SetTime (or the newest one when no cursor is set).
+type RecordedBooks struct {
+	mx    sync.RWMutex
+	books map[string][]hyperliquid.OrderBookSnapshot
+	now   int64
+}
+
+func NewRecordedBooks(snaps []hyperliquid.OrderBookSnapshot) *RecordedBooks {
+	books := make(map[string][]hyperliquid.OrderBookSnapshot)
+	for _, s := range snaps {
+		books[s.Coin] = append(books[s.Coin], s)
+	}
+	for coin := range books {
+		list := books[coin]
+		sort.Slice(list, func(i, j int) bool { return list[i].Time < list[j].Time })
+	}
+	return &RecordedBooks{books: books}
+}
+
+// LoadRecordedBooks reads a JSON array of l2Book snapshots, as returned by the /info endpoint.
+func LoadRecordedBooks(path string) (*RecordedBooks, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var snaps []hyperliquid.OrderBookSnapshot
+	if err := json.Unmarshal(raw, &snaps); err != nil {
+		return nil, err
+	}
+	return NewRecordedBooks(snaps), nil
+}
+
+// SetTime moves the replay cursor.
+func (r *RecordedBooks) SetTime(t time.Time) {
+	r.mx.Lock()
+	defer r.mx.Unlock()
+	r.now = t.UnixMilli()
+}
+
+func (r *RecordedBooks) L2Book(_ context.Context, coin string) (hyperliquid.OrderBookSnapshot, error) {
+	r.mx.RLock()
+	defer r.mx.RUnlock()
+
+	list := r.books[coin]
+	if len(list) == 0 {
+		return hyperliquid.OrderBookSnapshot{}, fmt.Errorf("no recorded book for %s", coin)
+	}
+	if r.now == 0 {
+		return list[len(list)-1], nil
+	}
+	i := sort.Search(len(list), func(i int) bool { return list[i].Time > r.now })
+	if i == 0 {
+		return hyperliquid.OrderBookSnapshot{}, fmt.Errorf("no recorded book for %s before %d", coin, r.now)
+	}
+	return list[i-1], nil
+}
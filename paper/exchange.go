@@ -0,0 +1,738 @@
+package paper
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"deepseek-trader/hyperliquid"
+	"deepseek-trader/models"
+)
+
+const (
+	statusOpen               = "open"
+	statusFilled             = "filled"
+	statusCanceled           = "canceled"
+	statusReduceOnlyCanceled = "reduceOnlyCanceled"
+)
+
+// Books is the order book source fills are matched against: the live client or RecordedBooks.
+type Books interface {
+	L2Book(ctx context.Context, coin string) (hyperliquid.OrderBookSnapshot, error)
+}
+
+// Store keeps the paper accounts; *repository.PaperRepository keeps them in Postgres.
+type Store interface {
+	EnsureAccount(ctx context.Context, name string, initial float64) (models.PaperAccount, error)
+	Positions(ctx context.Context, accountID int64) ([]models.PaperPosition, error)
+	Position(ctx context.Context, accountID int64, coin string) (models.PaperPosition, error)
+	CreateOrder(ctx context.Context, o *models.PaperOrder) error
+	UpdateOrder(ctx context.Context, o models.PaperOrder) error
+	FindOrder(ctx context.Context, accountID, id int64) (models.PaperOrder, error)
+	FindOrderByCloid(ctx context.Context, accountID int64, cloid string) (models.PaperOrder, error)
+	OpenOrders(ctx context.Context, accountID int64) ([]models.PaperOrder, error)
+	RecordFill(ctx context.Context, a models.PaperAccount, p models.PaperPosition, f *models.PaperFill) error
+	Fills(ctx context.Context, accountID int64, limit int) ([]models.PaperFill, error)
+	SetLeverage(ctx context.Context, l models.PaperLeverage) error
+	Leverage(ctx context.Context, accountID int64) ([]models.PaperLeverage, error)
+}
+
+// Exchange simulates Hyperliquid order handling against real order books and keeps the resulting
+// account, positions, orders, fills and leverage in a Store. It satisfies the same interface the bot
+// uses for the live client.
+type Exchange struct {
+	mx       sync.Mutex
+	books    Books
+	repo     Store
+	name     string
+	initial  float64
+	feeRate  float64
+	leverage float64
+	// coins caches the per-coin leverage stored with UpdateLeverage, loaded with the account; other
+	// coins use leverage.
+	coins map[string]coinLeverage
+}
+
+type coinLeverage struct {
+	leverage float64
+	isolated bool
+}
+
+// NewExchange creates a paper exchange for the named account. The account is created with the
+// initial balance on first use and reused afterwards.
+func NewExchange(books Books, repo Store, name string, initial, feeRate, leverage float64) *Exchange {
+	if leverage <= 0 {
+		leverage = 1
+	}
+	return &Exchange{
+		books:    books,
+		repo:     repo,
+		name:     name,
+		initial:  initial,
+		feeRate:  feeRate,
+		leverage: leverage,
+	}
+}
+
+// account returns the paper account, creating it on first use, and loads the leverage it set the first
+// time it is called.
+func (e *Exchange) account(ctx context.Context) (models.PaperAccount, error) {
+	acct, err := e.repo.EnsureAccount(ctx, e.name, e.initial)
+	if err != nil || e.coins != nil {
+		return acct, err
+	}
+	levs, err := e.repo.Leverage(ctx, acct.ID)
+	if err != nil {
+		return models.PaperAccount{}, err
+	}
+	e.coins = make(map[string]coinLeverage, len(levs))
+	for _, l := range levs {
+		e.coins[l.Coin] = coinLeverage{leverage: float64(l.Leverage), isolated: l.Isolated}
+	}
+	return acct, nil
+}
+
+func (e *Exchange) PlaceOrder(ctx context.Context, req hyperliquid.OrderRequest) (hyperliquid.OrderResult, error) {
+	e.mx.Lock()
+	defer e.mx.Unlock()
+
+	acct, err := e.account(ctx)
+	if err != nil {
+		return hyperliquid.OrderResult{}, err
+	}
+	coin := hyperliquid.NormalizeSymbol(req.Coin)
+	book, err := e.books.L2Book(ctx, coin)
+	if err != nil {
+		return hyperliquid.OrderResult{}, err
+	}
+
+	size := req.Size
+	if req.ReduceOnly {
+		if size, err = e.reducible(ctx, acct.ID, coin, req.IsBuy, size); err != nil {
+			return hyperliquid.OrderResult{}, err
+		}
+		if size <= 0 {
+			return reject("reduce only order would increase position")
+		}
+	} else if err := e.checkMargin(ctx, acct, book, size, req); err != nil {
+		return reject(err.Error())
+	}
+
+	limit := req.Price
+	ioc := req.Market || req.Tif == hyperliquid.TifIoc
+	if req.Market {
+		// Like the live client's IOC price, the slippage bound limits how deep a market order may sweep.
+		slippage := req.Slippage
+		if slippage == 0 {
+			slippage = hyperliquid.DefaultSlippage
+		}
+		ref := req.Price
+		if ref <= 0 {
+			ref = Mid(book)
+		}
+		limit = ref * (1 - slippage)
+		if req.IsBuy {
+			limit = ref * (1 + slippage)
+		}
+	} else if req.Tif == hyperliquid.TifAlo {
+		if filled, _ := Sweep(book, req.IsBuy, size, limit); filled > 0 {
+			return reject("post only order would have immediately matched")
+		}
+	}
+
+	o := models.PaperOrder{
+		AccountID:  acct.ID,
+		Coin:       coin,
+		IsBuy:      req.IsBuy,
+		Size:       size,
+		LimitPrice: limit,
+		ReduceOnly: req.ReduceOnly,
+		Cloid:      hyperliquid.NormalizeCloid(req.Cloid),
+		Status:     statusOpen,
+	}
+	if err := e.repo.CreateOrder(ctx, &o); err != nil {
+		return hyperliquid.OrderResult{}, err
+	}
+
+	if filled, px := Sweep(book, o.IsBuy, o.Size, o.LimitPrice); filled > 0 {
+		if err := e.fill(ctx, &acct, &o, filled, px); err != nil {
+			return hyperliquid.OrderResult{}, err
+		}
+	}
+
+	switch {
+	case o.FilledSize >= o.Size-1e-12:
+		o.Status = statusFilled
+	case ioc && o.FilledSize > 0:
+		o.Status = statusFilled // IOC: the unfilled remainder is dropped
+	case ioc:
+		o.Status = statusCanceled
+	}
+	if err := e.repo.UpdateOrder(ctx, o); err != nil {
+		return hyperliquid.OrderResult{}, err
+	}
+
+	if o.Status == statusCanceled {
+		return reject("order could not immediately match against any resting orders")
+	}
+	if o.Status == statusFilled {
+		return hyperliquid.OrderResult{Oid: o.ID, Cloid: o.Cloid, Status: hyperliquid.OrderStatusFilled, FilledSize: o.FilledSize, AvgPrice: o.AvgPrice}, nil
+	}
+	return hyperliquid.OrderResult{Oid: o.ID, Cloid: o.Cloid, Status: hyperliquid.OrderStatusResting}, nil
+}
+
+// PlaceTriggerOrder stores a reduce-only trigger; it fires once the mid reaches its trigger price, checked by
+// Match and QueryOrder.
+func (e *Exchange) PlaceTriggerOrder(ctx context.Context, req hyperliquid.TriggerOrderRequest) (hyperliquid.OrderResult, error) {
+	e.mx.Lock()
+	defer e.mx.Unlock()
+
+	acct, err := e.account(ctx)
+	if err != nil {
+		return hyperliquid.OrderResult{}, err
+	}
+	o := models.PaperOrder{
+		AccountID:  acct.ID,
+		Coin:       hyperliquid.NormalizeSymbol(req.Coin),
+		IsBuy:      req.IsBuy,
+		Size:       req.Size,
+		TriggerPx:  req.TriggerPx,
+		Tpsl:       req.Tpsl,
+		ReduceOnly: true,
+		Cloid:      hyperliquid.NormalizeCloid(req.Cloid),
+		Status:     statusOpen,
+	}
+	if err := e.repo.CreateOrder(ctx, &o); err != nil {
+		return hyperliquid.OrderResult{}, err
+	}
+	return hyperliquid.OrderResult{Oid: o.ID, Cloid: o.Cloid, Status: hyperliquid.OrderStatusResting}, nil
+}
+
+func (e *Exchange) CancelOrder(ctx context.Context, _ string, oid int64) error {
+	e.mx.Lock()
+	defer e.mx.Unlock()
+
+	acct, err := e.account(ctx)
+	if err != nil {
+		return err
+	}
+	o, err := e.repo.FindOrder(ctx, acct.ID, oid)
+	if err != nil {
+		return err
+	}
+	if o.Status != statusOpen {
+		return fmt.Errorf("order %d is %s", oid, o.Status)
+	}
+	o.Status = statusCanceled
+	return e.repo.UpdateOrder(ctx, o)
+}
+
+// CancelAll cancels every open order on coin, triggers included, or on every coin when coin is empty.
+func (e *Exchange) CancelAll(ctx context.Context, coin string) (int, error) {
+	e.mx.Lock()
+	defer e.mx.Unlock()
+
+	acct, err := e.account(ctx)
+	if err != nil {
+		return 0, err
+	}
+	orders, err := e.repo.OpenOrders(ctx, acct.ID)
+	if err != nil {
+		return 0, err
+	}
+	canceled := 0
+	for _, o := range orders {
+		if coin != "" && o.Coin != hyperliquid.NormalizeSymbol(coin) {
+			continue
+		}
+		o.Status = statusCanceled
+		if err := e.repo.UpdateOrder(ctx, o); err != nil {
+			return canceled, err
+		}
+		canceled++
+	}
+	return canceled, nil
+}
+
+// QueryOrder reports an order's state, first matching it against the current book if it is still open.
+func (e *Exchange) QueryOrder(ctx context.Context, oid int64) (hyperliquid.OrderQuery, error) {
+	e.mx.Lock()
+	defer e.mx.Unlock()
+
+	acct, err := e.account(ctx)
+	if err != nil {
+		return hyperliquid.OrderQuery{}, err
+	}
+	o, err := e.repo.FindOrder(ctx, acct.ID, oid)
+	if err != nil {
+		return hyperliquid.OrderQuery{}, err
+	}
+	return e.query(ctx, &acct, o)
+}
+
+// Match works every open order, resting limits and triggers alike, against the current book of its coin,
+// so orders fill as the market moves even when nobody queries them. The bot calls it on a short interval.
+func (e *Exchange) Match(ctx context.Context) error {
+	e.mx.Lock()
+	defer e.mx.Unlock()
+
+	acct, err := e.account(ctx)
+	if err != nil {
+		return err
+	}
+	orders, err := e.repo.OpenOrders(ctx, acct.ID)
+	if err != nil {
+		return err
+	}
+	books := make(map[string]hyperliquid.OrderBookSnapshot)
+	for i := range orders {
+		o := &orders[i]
+		book, ok := books[o.Coin]
+		if !ok {
+			if book, err = e.books.L2Book(ctx, o.Coin); err != nil {
+				return err
+			}
+			books[o.Coin] = book
+		}
+		if err := e.work(ctx, &acct, o, book); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// QueryOrderByCloid is QueryOrder by client order id; orders never placed are "unknownOid".
+func (e *Exchange) QueryOrderByCloid(ctx context.Context, cloid string) (hyperliquid.OrderQuery, error) {
+	e.mx.Lock()
+	defer e.mx.Unlock()
+
+	acct, err := e.account(ctx)
+	if err != nil {
+		return hyperliquid.OrderQuery{}, err
+	}
+	o, err := e.repo.FindOrderByCloid(ctx, acct.ID, hyperliquid.NormalizeCloid(cloid))
+	if errors.Is(err, sql.ErrNoRows) {
+		return hyperliquid.OrderQuery{Status: "unknownOid"}, nil
+	}
+	if err != nil {
+		return hyperliquid.OrderQuery{}, err
+	}
+	return e.query(ctx, &acct, o)
+}
+
+// query matches an open order against the current book and reports its state.
+func (e *Exchange) query(ctx context.Context, acct *models.PaperAccount, o models.PaperOrder) (hyperliquid.OrderQuery, error) {
+	if o.Status == statusOpen {
+		book, err := e.books.L2Book(ctx, o.Coin)
+		if err != nil {
+			return hyperliquid.OrderQuery{}, err
+		}
+		if err := e.work(ctx, acct, &o, book); err != nil {
+			return hyperliquid.OrderQuery{}, err
+		}
+	}
+	return toQuery(o), nil
+}
+
+// GetLiveStats reports account value, PnL and ROE computed the same way as the live client:
+// balance is cash plus unrealized PnL, ROE is PnL relative to balance in percent.
+func (e *Exchange) GetLiveStats(ctx context.Context) (*hyperliquid.LiveStats, error) {
+	e.mx.Lock()
+	defer e.mx.Unlock()
+
+	acct, err := e.account(ctx)
+	if err != nil {
+		return nil, err
+	}
+	unrealized, _, err := e.exposure(ctx, acct.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	bal := acct.Cash + unrealized
+	pnl := bal - acct.InitialBalance
+	roe := 0.0
+	if bal != 0 {
+		roe = (pnl / bal) * 100
+	}
+	return &hyperliquid.LiveStats{Balance: bal, PnL: pnl, ROE: roe}, nil
+}
+
+// HistoricalOrders returns the most recent paper fills shaped like Hyperliquid userFills.
+func (e *Exchange) HistoricalOrders(ctx context.Context) ([]hyperliquid.UserFill, error) {
+	e.mx.Lock()
+	defer e.mx.Unlock()
+
+	acct, err := e.account(ctx)
+	if err != nil {
+		return nil, err
+	}
+	fills, err := e.repo.Fills(ctx, acct.ID, 200)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]hyperliquid.UserFill, 0, len(fills))
+	for _, f := range fills {
+		side := "A"
+		if f.IsBuy {
+			side = "B"
+		}
+		out = append(out, hyperliquid.UserFill{
+			Coin:          f.Coin,
+			Px:            formatF(f.Price),
+			Sz:            formatF(f.Size),
+			Time:          f.CreatedAt.UnixMilli(),
+			Side:          side,
+			StartPosition: formatF(f.StartPosition),
+			Dir:           Direction(f.StartPosition, f.Size, f.IsBuy),
+			ClosedPnl:     formatF(f.ClosedPnL),
+			Oid:           f.OrderID,
+			Tid:           f.ID,
+			Fee:           formatF(f.Fee),
+		})
+	}
+	return out, nil
+}
+
+// AccountState reports the paper account like clearinghouseState, with positions marked to the current mid.
+// Paper accounts are never liquidated, so LiquidationPrice is left zero.
+func (e *Exchange) AccountState(ctx context.Context) (hyperliquid.AccountState, error) {
+	e.mx.Lock()
+	defer e.mx.Unlock()
+
+	acct, err := e.account(ctx)
+	if err != nil {
+		return hyperliquid.AccountState{}, err
+	}
+	positions, err := e.repo.Positions(ctx, acct.ID)
+	if err != nil {
+		return hyperliquid.AccountState{}, err
+	}
+
+	st := hyperliquid.AccountState{Time: time.Now().UnixMilli()}
+	var unrealized float64
+	for _, p := range positions {
+		if p.Size == 0 {
+			continue
+		}
+		mark := p.EntryPrice
+		if book, err := e.books.L2Book(ctx, p.Coin); err == nil {
+			if mid := Mid(book); mid > 0 {
+				mark = mid
+			}
+		}
+		value := math.Abs(p.Size) * mark
+		lev := e.leverageOf(p.Coin)
+		margin := value / lev.leverage
+		upnl := p.Size * (mark - p.EntryPrice)
+		roe := 0.0
+		if margin > 0 {
+			roe = upnl / margin
+		}
+		unrealized += upnl
+		st.TotalNotional += value
+		st.MarginUsed += margin
+		st.Positions = append(st.Positions, hyperliquid.Position{
+			Coin:           p.Coin,
+			Size:           p.Size,
+			EntryPrice:     p.EntryPrice,
+			PositionValue:  value,
+			UnrealizedPnL:  upnl,
+			ReturnOnEquity: roe,
+			MarginUsed:     margin,
+			Leverage:       int(lev.leverage),
+			LeverageType:   lev.marginType(),
+		})
+	}
+	st.AccountValue = acct.Cash + unrealized
+	st.Withdrawable = math.Max(st.AccountValue-st.MarginUsed, 0)
+	return st, nil
+}
+
+// OpenOrders lists the account's open paper orders shaped like frontendOpenOrders.
+func (e *Exchange) OpenOrders(ctx context.Context) ([]hyperliquid.OpenOrder, error) {
+	e.mx.Lock()
+	defer e.mx.Unlock()
+
+	acct, err := e.account(ctx)
+	if err != nil {
+		return nil, err
+	}
+	orders, err := e.repo.OpenOrders(ctx, acct.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]hyperliquid.OpenOrder, 0, len(orders))
+	for _, o := range orders {
+		oo := hyperliquid.OpenOrder{
+			Coin:       o.Coin,
+			Oid:        o.ID,
+			Cloid:      o.Cloid,
+			IsBuy:      o.IsBuy,
+			LimitPrice: o.LimitPrice,
+			Size:       o.Size - o.FilledSize,
+			OrigSize:   o.Size,
+			OrderType:  "Limit",
+			Tif:        "Gtc",
+			ReduceOnly: o.ReduceOnly,
+			IsTrigger:  o.Tpsl != "",
+			TriggerPx:  o.TriggerPx,
+			Timestamp:  o.CreatedAt.UnixMilli(),
+		}
+		switch o.Tpsl {
+		case hyperliquid.TriggerTakeProfit:
+			oo.OrderType, oo.Tif = "Take Profit Market", ""
+		case hyperliquid.TriggerStopLoss:
+			oo.OrderType, oo.Tif = "Stop Market", ""
+		}
+		out = append(out, oo)
+	}
+	return out, nil
+}
+
+// UpdateLeverage stores the leverage margin is computed at for coin. The mode is only reported: paper
+// positions are always margined against the whole account.
+func (e *Exchange) UpdateLeverage(ctx context.Context, coin string, leverage int, isolated bool) error {
+	if leverage < 1 {
+		return fmt.Errorf("invalid leverage %d", leverage)
+	}
+	e.mx.Lock()
+	defer e.mx.Unlock()
+
+	acct, err := e.account(ctx)
+	if err != nil {
+		return err
+	}
+	coin = hyperliquid.NormalizeSymbol(coin)
+	if err := e.repo.SetLeverage(ctx, models.PaperLeverage{AccountID: acct.ID, Coin: coin, Leverage: leverage, Isolated: isolated}); err != nil {
+		return err
+	}
+	e.coins[coin] = coinLeverage{leverage: float64(leverage), isolated: isolated}
+	return nil
+}
+
+// UpdateIsolatedMargin is not supported: the paper account has no isolated margin to move.
+func (e *Exchange) UpdateIsolatedMargin(context.Context, string, float64) error {
+	return errors.New("isolated margin is not simulated on paper accounts")
+}
+
+// leverageOf returns the leverage set for coin, or the account's default.
+func (e *Exchange) leverageOf(coin string) coinLeverage {
+	if lev, ok := e.coins[hyperliquid.NormalizeSymbol(coin)]; ok {
+		return lev
+	}
+	return coinLeverage{leverage: e.leverage}
+}
+
+func (l coinLeverage) marginType() string {
+	if l.isolated {
+		return "isolated"
+	}
+	return "cross"
+}
+
+// work advances an open order against the book: resting limits fill when the book crosses them, triggers
+// fire once the mid reaches the trigger price and fill their whole size at triggerPrice.
+func (e *Exchange) work(ctx context.Context, acct *models.PaperAccount, o *models.PaperOrder, book hyperliquid.OrderBookSnapshot) error {
+	var px float64
+	if o.Tpsl != "" {
+		mid := Mid(book)
+		if mid == 0 || !triggered(o, mid) {
+			return nil
+		}
+		px = triggerPrice(o, mid)
+	}
+
+	remaining := o.Size - o.FilledSize
+	if o.ReduceOnly {
+		var err error
+		if remaining, err = e.reducible(ctx, acct.ID, o.Coin, o.IsBuy, remaining); err != nil {
+			return err
+		}
+		if remaining <= 0 {
+			o.Status = statusReduceOnlyCanceled
+			return e.repo.UpdateOrder(ctx, *o)
+		}
+	}
+
+	filled := remaining
+	if o.Tpsl == "" {
+		if filled, px = Sweep(book, o.IsBuy, remaining, o.LimitPrice); filled == 0 {
+			return nil
+		}
+	}
+	if err := e.fill(ctx, acct, o, filled, px); err != nil {
+		return err
+	}
+	if o.FilledSize >= o.Size-1e-12 || o.Tpsl != "" {
+		o.Status = statusFilled
+	}
+	return e.repo.UpdateOrder(ctx, *o)
+}
+
+// fill books qty at px for the order: updates the position, charges the fee and records the fill, all or
+// nothing. acct and o are only updated once the fill is stored.
+func (e *Exchange) fill(ctx context.Context, acct *models.PaperAccount, o *models.PaperOrder, qty, px float64) error {
+	pos, err := e.repo.Position(ctx, acct.ID, o.Coin)
+	if err != nil {
+		return err
+	}
+
+	signed := qty
+	if !o.IsBuy {
+		signed = -qty
+	}
+	start := pos.Size
+	var closed float64
+	pos.Size, pos.EntryPrice, closed = ApplyFill(pos.Size, pos.EntryPrice, signed, px)
+	fee := qty * px * e.feeRate
+
+	next := *acct
+	next.Cash += closed - fee
+	next.RealizedPnL += closed
+	next.FeesPaid += fee
+
+	f := models.PaperFill{
+		AccountID:     acct.ID,
+		OrderID:       o.ID,
+		Coin:          o.Coin,
+		IsBuy:         o.IsBuy,
+		Size:          qty,
+		Price:         px,
+		Fee:           fee,
+		StartPosition: start,
+		ClosedPnL:     closed,
+	}
+	if err := e.repo.RecordFill(ctx, next, pos, &f); err != nil {
+		return err
+	}
+	*acct = next
+
+	o.AvgPrice = (o.AvgPrice*o.FilledSize + px*qty) / (o.FilledSize + qty)
+	o.FilledSize += qty
+	return nil
+}
+
+// reducible clamps size to what would only reduce the current position.
+func (e *Exchange) reducible(ctx context.Context, accountID int64, coin string, isBuy bool, size float64) (float64, error) {
+	pos, err := e.repo.Position(ctx, accountID, coin)
+	if err != nil {
+		return 0, err
+	}
+	if (isBuy && pos.Size >= 0) || (!isBuy && pos.Size <= 0) {
+		return 0, nil
+	}
+	return math.Min(size, math.Abs(pos.Size)), nil
+}
+
+// checkMargin rejects orders whose initial margin exceeds free collateral.
+func (e *Exchange) checkMargin(ctx context.Context, acct models.PaperAccount, book hyperliquid.OrderBookSnapshot, size float64, req hyperliquid.OrderRequest) error {
+	px := req.Price
+	if px <= 0 {
+		px = Mid(book)
+	}
+	unrealized, marginUsed, err := e.exposure(ctx, acct.ID)
+	if err != nil {
+		return err
+	}
+	free := acct.Cash + unrealized - marginUsed
+	if need := size * px / e.leverageOf(req.Coin).leverage; need > free {
+		return fmt.Errorf("insufficient margin: need %.2f, free %.2f", need, free)
+	}
+	return nil
+}
+
+// exposure marks open positions to the current mid and returns unrealized PnL and margin in use.
+func (e *Exchange) exposure(ctx context.Context, accountID int64) (unrealized, marginUsed float64, err error) {
+	positions, err := e.repo.Positions(ctx, accountID)
+	if err != nil {
+		return 0, 0, err
+	}
+	for _, p := range positions {
+		mark := p.EntryPrice
+		if book, err := e.books.L2Book(ctx, p.Coin); err == nil {
+			if mid := Mid(book); mid > 0 {
+				mark = mid
+			}
+		}
+		unrealized += p.Size * (mark - p.EntryPrice)
+		marginUsed += math.Abs(p.Size) * mark / e.leverageOf(p.Coin).leverage
+	}
+	return unrealized, marginUsed, nil
+}
+
+func triggered(o *models.PaperOrder, mid float64) bool {
+	// A sell closes a long: TP above, SL below. A buy closes a short: the reverse.
+	above := (o.Tpsl == hyperliquid.TriggerTakeProfit) != o.IsBuy
+	if above {
+		return mid >= o.TriggerPx
+	}
+	return mid <= o.TriggerPx
+}
+
+// triggerPrice is what a fired trigger fills at: a take-profit at its trigger price, a stop at the mid it
+// fired on, which is worse than its trigger when the price gapped through it.
+func triggerPrice(o *models.PaperOrder, mid float64) float64 {
+	if o.Tpsl == hyperliquid.TriggerTakeProfit {
+		return o.TriggerPx
+	}
+	return mid
+}
+
+func toQuery(o models.PaperOrder) hyperliquid.OrderQuery {
+	side := "A"
+	if o.IsBuy {
+		side = "B"
+	}
+	return hyperliquid.OrderQuery{
+		Status:          o.Status,
+		StatusTimestamp: o.UpdatedAt.UnixMilli(),
+		Order: hyperliquid.QueriedOrder{
+			Coin:      o.Coin,
+			Side:      side,
+			LimitPx:   formatF(o.LimitPrice),
+			Sz:        formatF(o.Size - o.FilledSize),
+			OrigSz:    formatF(o.Size),
+			Oid:       o.ID,
+			Cloid:     o.Cloid,
+			IsTrigger: o.Tpsl != "",
+			TriggerPx: formatF(o.TriggerPx),
+			Timestamp: o.CreatedAt.UnixMilli(),
+		},
+	}
+}
+
+// Direction is the userFills dir of a fill of size on the signed start position, as Hyperliquid reports
+// it: "Open Long", "Close Long", "Open Short", "Close Short", or "Long > Short" and "Short > Long" for a
+// fill that flips the position.
+func Direction(start, size float64, isBuy bool) string {
+	switch {
+	case isBuy && start < 0 && size+start > 1e-9:
+		return "Short > Long"
+	case isBuy && start < 0:
+		return "Close Short"
+	case isBuy:
+		return "Open Long"
+	case start > 0 && size-start > 1e-9:
+		return "Long > Short"
+	case start > 0:
+		return "Close Long"
+	default:
+		return "Open Short"
+	}
+}
+
+func reject(reason string) (hyperliquid.OrderResult, error) {
+	return hyperliquid.OrderResult{Status: hyperliquid.OrderStatusError, Error: reason},
+		fmt.Errorf("%w: %s", hyperliquid.ErrOrderRejected, reason)
+}
+
+func formatF(f float64) string {
+	return fmt.Sprintf("%g", f)
+}
@@ -0,0 +1,361 @@
+package paper
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"deepseek-trader/hyperliquid"
+	"deepseek-trader/models"
+)
+
+// memStore is an in-memory Store for one or more accounts.
+type memStore struct {
+	accounts  map[string]*models.PaperAccount
+	positions map[int64]map[string]models.PaperPosition
+	orders    []models.PaperOrder
+	fills     []models.PaperFill
+	leverage  map[int64][]models.PaperLeverage
+}
+
+func newMemStore() *memStore {
+	return &memStore{
+		accounts:  make(map[string]*models.PaperAccount),
+		positions: make(map[int64]map[string]models.PaperPosition),
+		leverage:  make(map[int64][]models.PaperLeverage),
+	}
+}
+
+func (m *memStore) EnsureAccount(_ context.Context, name string, initial float64) (models.PaperAccount, error) {
+	if a, ok := m.accounts[name]; ok {
+		return *a, nil
+	}
+	a := &models.PaperAccount{ID: int64(len(m.accounts) + 1), Name: name, InitialBalance: initial, Cash: initial}
+	m.accounts[name] = a
+	m.positions[a.ID] = make(map[string]models.PaperPosition)
+	return *a, nil
+}
+
+func (m *memStore) Positions(_ context.Context, accountID int64) ([]models.PaperPosition, error) {
+	var out []models.PaperPosition
+	for _, p := range m.positions[accountID] {
+		if p.Size != 0 {
+			out = append(out, p)
+		}
+	}
+	return out, nil
+}
+
+func (m *memStore) Position(_ context.Context, accountID int64, coin string) (models.PaperPosition, error) {
+	if p, ok := m.positions[accountID][coin]; ok {
+		return p, nil
+	}
+	return models.PaperPosition{AccountID: accountID, Coin: coin}, nil
+}
+
+func (m *memStore) CreateOrder(_ context.Context, o *models.PaperOrder) error {
+	o.ID = int64(len(m.orders) + 1)
+	o.CreatedAt, o.UpdatedAt = time.Now(), time.Now()
+	m.orders = append(m.orders, *o)
+	return nil
+}
+
+func (m *memStore) UpdateOrder(_ context.Context, o models.PaperOrder) error {
+	m.orders[o.ID-1] = o
+	return nil
+}
+
+func (m *memStore) FindOrder(_ context.Context, accountID, id int64) (models.PaperOrder, error) {
+	if id < 1 || id > int64(len(m.orders)) || m.orders[id-1].AccountID != accountID {
+		return models.PaperOrder{}, sql.ErrNoRows
+	}
+	return m.orders[id-1], nil
+}
+
+func (m *memStore) FindOrderByCloid(_ context.Context, accountID int64, cloid string) (models.PaperOrder, error) {
+	for i := len(m.orders) - 1; i >= 0; i-- {
+		if m.orders[i].AccountID == accountID && m.orders[i].Cloid == cloid {
+			return m.orders[i], nil
+		}
+	}
+	return models.PaperOrder{}, sql.ErrNoRows
+}
+
+func (m *memStore) OpenOrders(_ context.Context, accountID int64) ([]models.PaperOrder, error) {
+	var out []models.PaperOrder
+	for _, o := range m.orders {
+		if o.AccountID == accountID && o.Status == statusOpen {
+			out = append(out, o)
+		}
+	}
+	return out, nil
+}
+
+func (m *memStore) RecordFill(_ context.Context, a models.PaperAccount, p models.PaperPosition, f *models.PaperFill) error {
+	for _, acct := range m.accounts {
+		if acct.ID == a.ID {
+			*acct = a
+		}
+	}
+	m.positions[a.ID][p.Coin] = p
+	f.ID = int64(len(m.fills) + 1)
+	f.CreatedAt = time.Now()
+	m.fills = append(m.fills, *f)
+	return nil
+}
+
+func (m *memStore) Fills(_ context.Context, accountID int64, limit int) ([]models.PaperFill, error) {
+	var out []models.PaperFill
+	for i := len(m.fills) - 1; i >= 0 && len(out) < limit; i-- {
+		if m.fills[i].AccountID == accountID {
+			out = append(out, m.fills[i])
+		}
+	}
+	return out, nil
+}
+
+func (m *memStore) SetLeverage(_ context.Context, l models.PaperLeverage) error {
+	levs := m.leverage[l.AccountID]
+	for i := range levs {
+		if levs[i].Coin == l.Coin {
+			levs[i] = l
+			return nil
+		}
+	}
+	m.leverage[l.AccountID] = append(levs, l)
+	return nil
+}
+
+func (m *memStore) Leverage(_ context.Context, accountID int64) ([]models.PaperLeverage, error) {
+	return m.leverage[accountID], nil
+}
+
+// staticBooks serves a fixed book per coin; tests move the market by replacing it.
+type staticBooks map[string]hyperliquid.OrderBookSnapshot
+
+func (b staticBooks) L2Book(_ context.Context, coin string) (hyperliquid.OrderBookSnapshot, error) {
+	book, ok := b[coin]
+	if !ok {
+		return hyperliquid.OrderBookSnapshot{}, errors.New("no book for " + coin)
+	}
+	return book, nil
+}
+
+// bookAround is a BTC book with one level of size 10 a dollar either side of mid.
+func bookAround(mid float64) hyperliquid.OrderBookSnapshot {
+	return testBook("BTC", [][2]string{{formatF(mid - 1), "10"}}, [][2]string{{formatF(mid + 1), "10"}})
+}
+
+func newTestExchange(store *memStore, books staticBooks) *Exchange {
+	return NewExchange(books, store, "test", 10_000, 0, 10)
+}
+
+func TestPlaceOrder(t *testing.T) {
+	tests := []struct {
+		name       string
+		position   float64 // signed BTC position held before the order
+		req        hyperliquid.OrderRequest
+		wantStatus string
+		wantErr    string
+		wantFilled float64
+		wantPx     float64
+		wantPos    float64
+	}{
+		{name: "market buy", req: hyperliquid.OrderRequest{Coin: "BTC", IsBuy: true, Size: 1, Market: true},
+			wantStatus: hyperliquid.OrderStatusFilled, wantFilled: 1, wantPx: 101, wantPos: 1},
+		{name: "pair symbol", req: hyperliquid.OrderRequest{Coin: "BTCUSDT", IsBuy: false, Size: 2, Market: true},
+			wantStatus: hyperliquid.OrderStatusFilled, wantFilled: 2, wantPx: 99, wantPos: -2},
+		{name: "marketable limit", req: hyperliquid.OrderRequest{Coin: "BTC", IsBuy: true, Size: 1, Price: 105},
+			wantStatus: hyperliquid.OrderStatusFilled, wantFilled: 1, wantPx: 101, wantPos: 1},
+		{name: "resting limit", req: hyperliquid.OrderRequest{Coin: "BTC", IsBuy: true, Size: 1, Price: 95},
+			wantStatus: hyperliquid.OrderStatusResting},
+		{name: "post only that would cross", req: hyperliquid.OrderRequest{Coin: "BTC", IsBuy: true, Size: 1, Price: 105, Tif: hyperliquid.TifAlo},
+			wantErr: "post only"},
+		{name: "ioc away from the book", req: hyperliquid.OrderRequest{Coin: "BTC", IsBuy: true, Size: 1, Price: 95, Tif: hyperliquid.TifIoc},
+			wantErr: "could not immediately match"},
+		{name: "insufficient margin", req: hyperliquid.OrderRequest{Coin: "BTC", IsBuy: true, Size: 1001, Market: true},
+			wantErr: "insufficient margin"},
+		{name: "reduce only clamped to the position", position: 0.5,
+			req:        hyperliquid.OrderRequest{Coin: "BTC", IsBuy: false, Size: 2, Market: true, ReduceOnly: true},
+			wantStatus: hyperliquid.OrderStatusFilled, wantFilled: 0.5, wantPx: 99, wantPos: 0},
+		{name: "reduce only on the same side", position: 0.5,
+			req:     hyperliquid.OrderRequest{Coin: "BTC", IsBuy: true, Size: 1, Market: true, ReduceOnly: true},
+			wantErr: "reduce only", wantPos: 0.5},
+		{name: "reduce only without a position", req: hyperliquid.OrderRequest{Coin: "BTC", IsBuy: false, Size: 1, Market: true, ReduceOnly: true},
+			wantErr: "reduce only"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			store := newMemStore()
+			ex := newTestExchange(store, staticBooks{"BTC": bookAround(100)})
+			if tt.position != 0 {
+				acct, _ := store.EnsureAccount(ctx, "test", 10_000)
+				store.positions[acct.ID]["BTC"] = models.PaperPosition{AccountID: acct.ID, Coin: "BTC", Size: tt.position, EntryPrice: 100}
+			}
+
+			res, err := ex.PlaceOrder(ctx, tt.req)
+			if tt.wantErr != "" {
+				if !errors.Is(err, hyperliquid.ErrOrderRejected) || !strings.Contains(res.Error, tt.wantErr) {
+					t.Fatalf("PlaceOrder = %+v, %v, want a rejection containing %q", res, err, tt.wantErr)
+				}
+			} else {
+				if err != nil {
+					t.Fatalf("PlaceOrder: %v", err)
+				}
+				if res.Status != tt.wantStatus || math.Abs(res.FilledSize-tt.wantFilled) > 1e-9 || math.Abs(res.AvgPrice-tt.wantPx) > 1e-9 {
+					t.Fatalf("PlaceOrder = %+v, want %s %v @ %v", res, tt.wantStatus, tt.wantFilled, tt.wantPx)
+				}
+			}
+			st, err := ex.AccountState(ctx)
+			if err != nil {
+				t.Fatalf("AccountState: %v", err)
+			}
+			if got := positionSize(st, "BTC"); math.Abs(got-tt.wantPos) > 1e-9 {
+				t.Errorf("position = %v, want %v", got, tt.wantPos)
+			}
+		})
+	}
+}
+
+func TestTriggers(t *testing.T) {
+	tests := []struct {
+		name       string
+		position   float64
+		trigger    hyperliquid.TriggerOrderRequest
+		mid        float64
+		wantStatus string
+		wantPx     float64
+		wantPos    float64
+	}{
+		{name: "take profit of a long not reached", position: 1, mid: 105,
+			trigger:    hyperliquid.TriggerOrderRequest{Coin: "BTC", IsBuy: false, Size: 1, TriggerPx: 110, Tpsl: hyperliquid.TriggerTakeProfit},
+			wantStatus: statusOpen, wantPos: 1},
+		{name: "take profit of a long fills at its trigger", position: 1, mid: 112,
+			trigger:    hyperliquid.TriggerOrderRequest{Coin: "BTC", IsBuy: false, Size: 0.4, TriggerPx: 110, Tpsl: hyperliquid.TriggerTakeProfit},
+			wantStatus: statusFilled, wantPx: 110, wantPos: 0.6},
+		{name: "stop of a long fills at the mid it gapped to", position: 1, mid: 85,
+			trigger:    hyperliquid.TriggerOrderRequest{Coin: "BTC", IsBuy: false, Size: 1, TriggerPx: 90, Tpsl: hyperliquid.TriggerStopLoss},
+			wantStatus: statusFilled, wantPx: 85, wantPos: 0},
+		{name: "stop of a short", position: -2, mid: 111,
+			trigger:    hyperliquid.TriggerOrderRequest{Coin: "BTC", IsBuy: true, Size: 2, TriggerPx: 110, Tpsl: hyperliquid.TriggerStopLoss},
+			wantStatus: statusFilled, wantPx: 111, wantPos: 0},
+		{name: "stop larger than the position is clamped", position: 0.5, mid: 85,
+			trigger:    hyperliquid.TriggerOrderRequest{Coin: "BTC", IsBuy: false, Size: 1, TriggerPx: 90, Tpsl: hyperliquid.TriggerStopLoss},
+			wantStatus: statusFilled, wantPx: 85, wantPos: 0},
+		{name: "stop after the position closed", mid: 85,
+			trigger:    hyperliquid.TriggerOrderRequest{Coin: "BTC", IsBuy: false, Size: 1, TriggerPx: 90, Tpsl: hyperliquid.TriggerStopLoss},
+			wantStatus: statusReduceOnlyCanceled},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			store := newMemStore()
+			books := staticBooks{"BTC": bookAround(100)}
+			ex := newTestExchange(store, books)
+			acct, _ := store.EnsureAccount(ctx, "test", 10_000)
+			if tt.position != 0 {
+				store.positions[acct.ID]["BTC"] = models.PaperPosition{AccountID: acct.ID, Coin: "BTC", Size: tt.position, EntryPrice: 100}
+			}
+			res, err := ex.PlaceTriggerOrder(ctx, tt.trigger)
+			if err != nil {
+				t.Fatalf("PlaceTriggerOrder: %v", err)
+			}
+
+			// Nobody queries the order: Match alone must fire it once the market moves.
+			books["BTC"] = bookAround(tt.mid)
+			if err := ex.Match(ctx); err != nil {
+				t.Fatalf("Match: %v", err)
+			}
+
+			o := store.orders[res.Oid-1]
+			if o.Status != tt.wantStatus {
+				t.Fatalf("status = %s, want %s", o.Status, tt.wantStatus)
+			}
+			if tt.wantStatus == statusFilled && math.Abs(o.AvgPrice-tt.wantPx) > 1e-9 {
+				t.Errorf("filled at %v, want %v", o.AvgPrice, tt.wantPx)
+			}
+			if got := store.positions[acct.ID]["BTC"].Size; math.Abs(got-tt.wantPos) > 1e-9 {
+				t.Errorf("position = %v, want %v", got, tt.wantPos)
+			}
+		})
+	}
+}
+
+func TestMatchFillsRestingLimits(t *testing.T) {
+	ctx := context.Background()
+	store := newMemStore()
+	books := staticBooks{"BTC": bookAround(100)}
+	ex := newTestExchange(store, books)
+
+	res, err := ex.PlaceOrder(ctx, hyperliquid.OrderRequest{Coin: "BTC", IsBuy: true, Size: 1, Price: 95})
+	if err != nil || res.Status != hyperliquid.OrderStatusResting {
+		t.Fatalf("PlaceOrder = %+v, %v, want resting", res, err)
+	}
+	books["BTC"] = bookAround(94)
+	if err := ex.Match(ctx); err != nil {
+		t.Fatalf("Match: %v", err)
+	}
+	if o := store.orders[res.Oid-1]; o.Status != statusFilled || o.AvgPrice != 95 {
+		t.Fatalf("order = %s @ %v, want filled @ 95", o.Status, o.AvgPrice)
+	}
+}
+
+func TestLeverageSurvivesRestart(t *testing.T) {
+	ctx := context.Background()
+	store := newMemStore()
+	books := staticBooks{"BTC": bookAround(100)}
+
+	if err := newTestExchange(store, books).UpdateLeverage(ctx, "BTCUSDT", 3, true); err != nil {
+		t.Fatalf("UpdateLeverage: %v", err)
+	}
+	ex := newTestExchange(store, books)
+	if _, err := ex.PlaceOrder(ctx, hyperliquid.OrderRequest{Coin: "BTC", IsBuy: true, Size: 3, Market: true}); err != nil {
+		t.Fatalf("PlaceOrder: %v", err)
+	}
+	st, err := ex.AccountState(ctx)
+	if err != nil {
+		t.Fatalf("AccountState: %v", err)
+	}
+	if len(st.Positions) != 1 || st.Positions[0].Leverage != 3 || st.Positions[0].LeverageType != "isolated" {
+		t.Fatalf("positions = %+v, want BTC at 3x isolated", st.Positions)
+	}
+	if math.Abs(st.MarginUsed-100) > 1e-9 {
+		t.Errorf("margin used = %v, want 100 (3 BTC at 100 and 3x)", st.MarginUsed)
+	}
+}
+
+func positionSize(st hyperliquid.AccountState, coin string) float64 {
+	for _, p := range st.Positions {
+		if p.Coin == coin {
+			return p.Size
+		}
+	}
+	return 0
+}
+
+func TestDirection(t *testing.T) {
+	tests := []struct {
+		start, size float64
+		isBuy       bool
+		want        string
+	}{
+		{start: 0, size: 1, isBuy: true, want: "Open Long"},
+		{start: 1, size: 1, isBuy: true, want: "Open Long"},
+		{start: 1, size: 0.4, want: "Close Long"},
+		{start: 0.3, size: 0.1 + 0.2, want: "Close Long"},
+		{start: 1, size: 3, want: "Long > Short"},
+		{start: 0, size: 1, want: "Open Short"},
+		{start: -2, size: 2, isBuy: true, want: "Close Short"},
+		{start: -2, size: 3, isBuy: true, want: "Short > Long"},
+	}
+	for _, tt := range tests {
+		if got := Direction(tt.start, tt.size, tt.isBuy); got != tt.want {
+			t.Errorf("Direction(%v, %v, %v) = %q, want %q", tt.start, tt.size, tt.isBuy, got, tt.want)
+		}
+	}
+}
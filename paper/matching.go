@@ -0,0 +1,83 @@
+package paper
+
+import (
+	"math"
+	"strconv"
+
+	"deepseek-trader/hyperliquid"
+)
+
+// Sweep walks the side of the book an order would take liquidity from, best level first, and
+// returns how much of size fills and at what average price. limit bounds the worst acceptable price
+// (0 = unbounded, i.e. a market order).
+func Sweep(book hyperliquid.OrderBookSnapshot, isBuy bool, size, limit float64) (filled, avgPx float64) {
+	if len(book.Levels) < 2 {
+		return 0, 0
+	}
+	levels := book.Levels[0] // bids: a sell hits them
+	if isBuy {
+		levels = book.Levels[1] // asks: a buy lifts them
+	}
+
+	notional := 0.0
+	for _, lvl := range levels {
+		if filled >= size {
+			break
+		}
+		px := parseF(lvl.Px)
+		if limit > 0 && ((isBuy && px > limit) || (!isBuy && px < limit)) {
+			break
+		}
+		qty := math.Min(parseF(lvl.Sz), size-filled)
+		filled += qty
+		notional += qty * px
+	}
+	if filled == 0 {
+		return 0, 0
+	}
+	return filled, notional / filled
+}
+
+// Mid returns the book mid price, or zero when either side is empty.
+func Mid(book hyperliquid.OrderBookSnapshot) float64 {
+	if len(book.Levels) < 2 || len(book.Levels[0]) == 0 || len(book.Levels[1]) == 0 {
+		return 0
+	}
+	return (parseF(book.Levels[0][0].Px) + parseF(book.Levels[1][0].Px)) / 2
+}
+
+// ApplyFill updates a signed position (size, entry) with a signed fill qty at px and returns the new
+// position plus the PnL realized by any part of the fill that reduced it.
+func ApplyFill(size, entry, qty, px float64) (newSize, newEntry, closedPnL float64) {
+	if size == 0 || sameSign(size, qty) {
+		total := math.Abs(size) + math.Abs(qty)
+		return size + qty, (math.Abs(size)*entry + math.Abs(qty)*px) / total, 0
+	}
+
+	closing := math.Min(math.Abs(qty), math.Abs(size))
+	if size > 0 {
+		closedPnL = closing * (px - entry)
+	} else {
+		closedPnL = closing * (entry - px)
+	}
+
+	newSize = size + qty
+	switch {
+	case math.Abs(newSize) < 1e-12:
+		return 0, 0, closedPnL
+	case sameSign(newSize, size):
+		return newSize, entry, closedPnL
+	default:
+		// flipped through zero: the remainder opens at the fill price
+		return newSize, px, closedPnL
+	}
+}
+
+func sameSign(a, b float64) bool {
+	return (a > 0 && b > 0) || (a < 0 && b < 0)
+}
+
+func parseF(s string) float64 {
+	f, _ := strconv.ParseFloat(s, 64)
+	return f
+}
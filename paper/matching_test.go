@@ -0,0 +1,87 @@
+package paper
+
+import (
+	"math"
+	"testing"
+
+	"deepseek-trader/hyperliquid"
+)
+
+// testBook builds a book from bid and ask levels given as price, size pairs, best first.
+func testBook(coin string, bids, asks [][2]string) hyperliquid.OrderBookSnapshot {
+	side := func(levels [][2]string) []hyperliquid.OrderBookLevel {
+		out := make([]hyperliquid.OrderBookLevel, 0, len(levels))
+		for _, l := range levels {
+			out = append(out, hyperliquid.OrderBookLevel{Px: l[0], Sz: l[1], N: 1})
+		}
+		return out
+	}
+	return hyperliquid.OrderBookSnapshot{Coin: coin, Levels: [][]hyperliquid.OrderBookLevel{side(bids), side(asks)}}
+}
+
+func TestSweep(t *testing.T) {
+	book := testBook("BTC",
+		[][2]string{{"99", "1"}, {"98", "2"}, {"97", "5"}},
+		[][2]string{{"101", "1"}, {"102", "2"}, {"103", "5"}},
+	)
+	tests := []struct {
+		name       string
+		book       hyperliquid.OrderBookSnapshot
+		isBuy      bool
+		size       float64
+		limit      float64
+		wantFilled float64
+		wantPx     float64
+	}{
+		{name: "buy within the best ask", book: book, isBuy: true, size: 0.5, wantFilled: 0.5, wantPx: 101},
+		{name: "buy across levels", book: book, isBuy: true, size: 2, wantFilled: 2, wantPx: 101.5},
+		{name: "sell across levels", book: book, isBuy: false, size: 3, wantFilled: 3, wantPx: (99 + 2*98) / 3.0},
+		{name: "limit stops the sweep", book: book, isBuy: true, size: 5, limit: 102, wantFilled: 3, wantPx: (101 + 2*102) / 3.0},
+		{name: "limit at the touch", book: book, isBuy: false, size: 5, limit: 99, wantFilled: 1, wantPx: 99},
+		{name: "limit away from the book", book: book, isBuy: true, size: 1, limit: 100},
+		{name: "deeper than the book", book: book, isBuy: true, size: 10, wantFilled: 8, wantPx: (101 + 2*102 + 5*103) / 8.0},
+		{name: "empty book", book: hyperliquid.OrderBookSnapshot{Coin: "BTC"}, isBuy: true, size: 1},
+		{name: "empty side", book: testBook("BTC", [][2]string{{"99", "1"}}, nil), isBuy: true, size: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filled, px := Sweep(tt.book, tt.isBuy, tt.size, tt.limit)
+			if math.Abs(filled-tt.wantFilled) > 1e-9 || math.Abs(px-tt.wantPx) > 1e-9 {
+				t.Fatalf("Sweep = %v @ %v, want %v @ %v", filled, px, tt.wantFilled, tt.wantPx)
+			}
+		})
+	}
+}
+
+func TestMid(t *testing.T) {
+	if got := Mid(testBook("BTC", [][2]string{{"99", "1"}}, [][2]string{{"101", "1"}})); got != 100 {
+		t.Errorf("Mid = %v, want 100", got)
+	}
+	if got := Mid(testBook("BTC", nil, [][2]string{{"101", "1"}})); got != 0 {
+		t.Errorf("Mid of a one-sided book = %v, want 0", got)
+	}
+}
+
+func TestApplyFill(t *testing.T) {
+	tests := []struct {
+		name                         string
+		size, entry, qty, px         float64
+		wantSize, wantEntry, wantPnL float64
+	}{
+		{name: "open long", qty: 2, px: 100, wantSize: 2, wantEntry: 100},
+		{name: "add to long", size: 2, entry: 100, qty: 2, px: 110, wantSize: 4, wantEntry: 105},
+		{name: "reduce long at a profit", size: 2, entry: 100, qty: -1, px: 110, wantSize: 1, wantEntry: 100, wantPnL: 10},
+		{name: "close long at a loss", size: 2, entry: 100, qty: -2, px: 95, wantSize: 0, wantEntry: 0, wantPnL: -10},
+		{name: "reduce short at a profit", size: -3, entry: 100, qty: 1, px: 90, wantSize: -2, wantEntry: 100, wantPnL: 10},
+		{name: "flip long to short", size: 1, entry: 100, qty: -3, px: 120, wantSize: -2, wantEntry: 120, wantPnL: 20},
+		{name: "flip short to long", size: -1, entry: 100, qty: 2, px: 120, wantSize: 1, wantEntry: 120, wantPnL: -20},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			size, entry, pnl := ApplyFill(tt.size, tt.entry, tt.qty, tt.px)
+			if math.Abs(size-tt.wantSize) > 1e-9 || math.Abs(entry-tt.wantEntry) > 1e-9 || math.Abs(pnl-tt.wantPnL) > 1e-9 {
+				t.Fatalf("ApplyFill = %v, %v, %v, want %v, %v, %v", size, entry, pnl, tt.wantSize, tt.wantEntry, tt.wantPnL)
+			}
+		})
+	}
+}
@@ -27,26 +27,38 @@ func EncryptString(key []byte, plaintext string) (string, error) {
 }
 
 func DecryptString(key []byte, b64 string) (string, error) {
-	data, err := base64.StdEncoding.DecodeString(b64)
+	pt, err := Decrypt(key, b64)
 	if err != nil {
 		return "", err
 	}
+	return string(pt), nil
+}
+
+// Decrypt is DecryptString for secrets the caller wants to wipe with Zero once used.
+func Decrypt(key []byte, b64 string) ([]byte, error) {
+	data, err := base64.StdEncoding.DecodeString(b64)
+	if err != nil {
+		return nil, err
+	}
 	block, err := aes.NewCipher(key)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 	gcm, err := cipher.NewGCM(block)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 	if len(data) < gcm.NonceSize() {
-		return "", errors.New("ciphertext too short")
+		return nil, errors.New("ciphertext too short")
 	}
 	nonce := data[:gcm.NonceSize()]
 	ciphertext := data[gcm.NonceSize():]
-	pt, err := gcm.Open(nil, nonce, ciphertext, nil)
-	if err != nil {
-		return "", err
+	return gcm.Open(nil, nonce, ciphertext, nil)
+}
+
+// Zero overwrites b in place.
+func Zero(b []byte) {
+	for i := range b {
+		b[i] = 0
 	}
-	return string(pt), nil
 }
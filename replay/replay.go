@@ -0,0 +1,319 @@
+// Package replay re-runs archived decision cycles through another agent configuration and diffs
+// the answers against what was originally decided, to evaluate a model or prompt on real past
+// market states before switching to it.
+package replay
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"deepseek-trader/agent"
+	"deepseek-trader/bot"
+	"deepseek-trader/config"
+	"deepseek-trader/models"
+	"deepseek-trader/services"
+
+	"go.uber.org/zap"
+)
+
+// MaxCycles bounds how many cycles one replay runs; each one is a model call.
+const MaxCycles = 100
+
+// Request selects the cycles to replay, either one archived call or a time range, and the agent
+// config to replay them with. Empty fields keep the user's bot config.
+type Request struct {
+	CallID      int64     `json:"callId,omitempty"`
+	From        time.Time `json:"from,omitempty"`
+	To          time.Time `json:"to,omitempty"`
+	Limit       int       `json:"limit,omitempty"`
+	Provider    string    `json:"provider,omitempty"`
+	Model       string    `json:"model,omitempty"`
+	BaseURL     string    `json:"baseUrl,omitempty"`
+	Temperature *float64  `json:"temperature,omitempty"`
+	// PromptID is the prompt version to replay with; zero uses the bot's active one and -1 the built-in one.
+	PromptID int64 `json:"promptId,omitempty"`
+}
+
+// Cycle is one replayed call. Original is empty when the archived call produced no decision.
+type Cycle struct {
+	CallID    int64            `json:"callId"`
+	Time      time.Time        `json:"time"`
+	Original  []Outcome        `json:"original,omitempty"`
+	Replayed  []agent.Decision `json:"replayed"`
+	Model     string           `json:"model"`
+	LatencyMS int64            `json:"latencyMs"`
+	Rejected  []string         `json:"rejected,omitempty"` // validation errors of the replayed answer
+	Error     string           `json:"error,omitempty"`
+	Changes   []Change         `json:"changes,omitempty"`
+}
+
+// Outcome is an original decision with its id and how it ended.
+type Outcome struct {
+	agent.Decision
+	ID     int64  `json:"id"`
+	Status string `json:"status"`
+}
+
+// Change is a field that differs between the original and the replayed decision on a symbol.
+type Change struct {
+	Symbol   string `json:"symbol,omitempty"`
+	Field    string `json:"field"`
+	Original any    `json:"original"`
+	Replayed any    `json:"replayed"`
+}
+
+// Summary counts the replayed cycles. Actions counts per-symbol action transitions such as "buy->none".
+type Summary struct {
+	Cycles    int            `json:"cycles"`
+	Unchanged int            `json:"unchanged"`
+	Changed   int            `json:"changed"`
+	Failed    int            `json:"failed"`
+	Actions   map[string]int `json:"actions"`
+}
+
+type Result struct {
+	Agent   agent.ProviderConfig `json:"agent"`
+	Prompt  int64                `json:"promptId"`
+	Cycles  []Cycle              `json:"cycles"`
+	Summary Summary              `json:"summary"`
+}
+
+// Replayer loads archived cycles and replays them.
+type Replayer struct {
+	calls   *services.AgentCallsService
+	trades  *services.TradesService
+	botsSvc *services.BotsService
+	prompts *services.PromptsService
+	cfg     *config.Settings
+	log     *zap.Logger
+}
+
+func NewReplayer(
+	calls *services.AgentCallsService, trades *services.TradesService, botsSvc *services.BotsService,
+	prompts *services.PromptsService, cfg *config.Settings, log *zap.Logger,
+) *Replayer {
+	return &Replayer{calls: calls, trades: trades, botsSvc: botsSvc, prompts: prompts, cfg: cfg, log: log}
+}
+
+// Run replays the user's selected cycles with the user's bot config and the request's overrides.
+func (r *Replayer) Run(ctx context.Context, userID int64, req Request) (Result, error) {
+	botCfg, err := bot.LoadConfig(ctx, r.botsSvc, r.cfg, userID)
+	if err != nil {
+		return Result{}, err
+	}
+	ac := botCfg.Agent.Override(req.Provider, req.Model, req.BaseURL, r.cfg.LLMAPIKey)
+	if req.Temperature != nil {
+		ac.Temperature = *req.Temperature
+	}
+	if ac.RequiresAPIKey() && ac.APIKey == "" {
+		return Result{}, fmt.Errorf("no API key configured for provider %q", ac.Provider)
+	}
+	prompt, err := r.prompt(ctx, userID, req.PromptID)
+	if err != nil {
+		return Result{}, err
+	}
+	ag, err := agent.NewLLMAgent(ac, botCfg.Validation, prompt)
+	if err != nil {
+		return Result{}, err
+	}
+
+	calls, err := r.load(ctx, userID, req)
+	if err != nil {
+		return Result{}, err
+	}
+
+	res := Result{Agent: ac, Prompt: prompt.ID, Summary: Summary{Actions: make(map[string]int)}}
+	for _, call := range calls {
+		if ctx.Err() != nil {
+			return Result{}, ctx.Err()
+		}
+		cycle := r.replay(ctx, userID, ag, call)
+		res.Cycles = append(res.Cycles, cycle)
+		res.Summary.add(cycle)
+	}
+	return res, nil
+}
+
+// prompt compiles the prompt version to replay with.
+func (r *Replayer) prompt(ctx context.Context, userID, id int64) (*agent.Prompt, error) {
+	var (
+		p   models.PromptVersion
+		err error
+	)
+	switch id {
+	case -1:
+		return agent.DefaultPrompt(), nil
+	case 0:
+		p, err = r.prompts.Active(ctx, userID)
+	default:
+		p, err = r.prompts.Version(ctx, userID, id)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return r.prompts.Compile(p)
+}
+
+// load returns the archived calls the request selects, oldest first.
+func (r *Replayer) load(ctx context.Context, userID int64, req Request) ([]models.AgentCall, error) {
+	if req.CallID != 0 {
+		call, err := r.calls.Call(ctx, userID, req.CallID)
+		if err != nil {
+			return nil, err
+		}
+		return []models.AgentCall{call}, nil
+	}
+	if req.From.IsZero() {
+		return nil, errors.New("either a call id or a time range is required")
+	}
+	to := req.To
+	if to.IsZero() {
+		to = time.Now()
+	}
+	if !req.From.Before(to) {
+		return nil, errors.New("from must be before to")
+	}
+	limit := req.Limit
+	if limit <= 0 || limit > MaxCycles {
+		limit = MaxCycles
+	}
+	return r.calls.Range(ctx, userID, req.From, to, limit)
+}
+
+func (r *Replayer) replay(ctx context.Context, userID int64, ag agent.DecisionAgent, call models.AgentCall) Cycle {
+	cycle := Cycle{CallID: call.ID, Time: call.CreatedAt}
+
+	ds, err := r.trades.DecisionsByCall(ctx, userID, call.ID)
+	if err != nil {
+		r.log.Sugar().Warnw("failed to load original decisions", "call", call.ID, "error", err)
+	}
+	original := make([]agent.Decision, 0, len(ds))
+	for _, d := range ds {
+		cycle.Original = append(cycle.Original, Outcome{Decision: fromModel(d), ID: d.ID, Status: d.Status})
+		original = append(original, fromModel(d))
+	}
+
+	var snap agent.Snapshot
+	if err := json.Unmarshal(call.Snapshot, &snap); err != nil {
+		cycle.Error = fmt.Sprintf("invalid archived snapshot: %v", err)
+		return cycle
+	}
+
+	decs, replayed, err := ag.Decide(ctx, snap)
+	cycle.Model, cycle.LatencyMS = replayed.Model, replayed.Latency.Milliseconds()
+	var verr *agent.ValidationError
+	switch {
+	case errors.As(err, &verr):
+		cycle.Rejected = verr.Errors
+	case err != nil:
+		cycle.Error = err.Error()
+		return cycle
+	}
+	cycle.Replayed = decs
+	cycle.Changes = Diff(original, decs)
+	return cycle
+}
+
+func (s *Summary) add(c Cycle) {
+	s.Cycles++
+	switch {
+	case c.Error != "":
+		s.Failed++
+		return
+	case len(c.Changes) == 0:
+		s.Unchanged++
+	default:
+		s.Changed++
+	}
+	original := make([]agent.Decision, 0, len(c.Original))
+	for _, o := range c.Original {
+		original = append(original, o.Decision)
+	}
+	orig, replayed, symbols := bySymbol(original), bySymbol(c.Replayed), symbolsOf(original, c.Replayed)
+	if len(symbols) == 0 {
+		s.Actions["none->none"]++
+	}
+	for _, sym := range symbols {
+		s.Actions[actionOf(orig[sym])+"->"+actionOf(replayed[sym])]++
+	}
+}
+
+// Diff lists, per symbol, the fields in which the replayed decisions differ from the original ones.
+// A symbol missing on one side counts as action "none" there, so only symbols acted on show up.
+func Diff(original, replayed []agent.Decision) []Change {
+	orig, repl := bySymbol(original), bySymbol(replayed)
+	var changes []Change
+	for _, sym := range symbolsOf(original, replayed) {
+		o, r := orig[sym], repl[sym]
+		add := func(field string, a, b any) {
+			if a != b {
+				changes = append(changes, Change{Symbol: sym, Field: field, Original: a, Replayed: b})
+			}
+		}
+		add("action", actionOf(o), actionOf(r))
+		add("size", o.Size, r.Size)
+		add("order", strings.ToLower(o.Order), strings.ToLower(r.Order))
+		add("limitPrice", o.LimitPrice, r.LimitPrice)
+		add("fraction", o.Fraction, r.Fraction)
+		add("tp1", o.Targets.TP1, r.Targets.TP1)
+		add("tp2", o.Targets.TP2, r.Targets.TP2)
+		add("tp3", o.Targets.TP3, r.Targets.TP3)
+		add("sl", o.Targets.SL, r.Targets.SL)
+		add("confidence", o.Confidence, r.Confidence)
+	}
+	return changes
+}
+
+// bySymbol indexes the decisions that act on a symbol by their uppercased symbol.
+func bySymbol(decs []agent.Decision) map[string]agent.Decision {
+	out := make(map[string]agent.Decision, len(decs))
+	for _, d := range decs {
+		if actionOf(d) != "none" {
+			out[strings.ToUpper(d.Symbol)] = d
+		}
+	}
+	return out
+}
+
+// symbolsOf returns the symbols acted on in either list, sorted.
+func symbolsOf(original, replayed []agent.Decision) []string {
+	seen := bySymbol(original)
+	for sym, d := range bySymbol(replayed) {
+		seen[sym] = d
+	}
+	symbols := make([]string, 0, len(seen))
+	for sym := range seen {
+		symbols = append(symbols, sym)
+	}
+	sort.Strings(symbols)
+	return symbols
+}
+
+func actionOf(d agent.Decision) string {
+	if d.Action == "" {
+		return "none"
+	}
+	return strings.ToLower(d.Action)
+}
+
+func fromModel(d models.Decision) agent.Decision {
+	var scores map[string]float64
+	_ = json.Unmarshal(d.Scores, &scores)
+	return agent.Decision{
+		Action:     d.Action,
+		Symbol:     d.Symbol,
+		Size:       d.Size,
+		Order:      d.OrderType,
+		LimitPrice: d.LimitPrice,
+		Fraction:   d.Fraction,
+		Targets:    agent.Targets{TP1: d.TP1, TP2: d.TP2, TP3: d.TP3, SL: d.SL},
+		Confidence: d.Confidence,
+		Scores:     scores,
+		Rationale:  d.Rationale,
+	}
+}
@@ -0,0 +1,105 @@
+package replay
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"deepseek-trader/agent"
+)
+
+func TestDiff(t *testing.T) {
+	btc := agent.Decision{Action: "buy", Symbol: "BTCUSDT", Size: 0.1, Order: "market", Targets: agent.Targets{TP1: 110, SL: 95}, Confidence: 70}
+	eth := agent.Decision{Action: "sell", Symbol: "ETHUSDT", Size: 2, Order: "limit", LimitPrice: 3000, Confidence: 60}
+	with := func(d agent.Decision, f func(*agent.Decision)) agent.Decision { f(&d); return d }
+
+	tests := []struct {
+		name     string
+		original []agent.Decision
+		replayed []agent.Decision
+		want     []Change
+	}{
+		{name: "same decisions", original: []agent.Decision{btc, eth}, replayed: []agent.Decision{eth, btc}},
+		{name: "nothing on either side", original: []agent.Decision{{Action: "none", Symbol: "BTCUSDT"}}, replayed: nil},
+		{name: "symbol and order case do not matter", original: []agent.Decision{btc},
+			replayed: []agent.Decision{with(btc, func(d *agent.Decision) { d.Symbol, d.Order = "btcusdt", "MARKET" })}},
+		{name: "fields in a fixed order", original: []agent.Decision{btc},
+			replayed: []agent.Decision{with(btc, func(d *agent.Decision) { d.Confidence, d.Targets.SL, d.Size = 80, 90, 0.2 })},
+			want: []Change{
+				{Symbol: "BTCUSDT", Field: "size", Original: 0.1, Replayed: 0.2},
+				{Symbol: "BTCUSDT", Field: "sl", Original: 95.0, Replayed: 90.0},
+				{Symbol: "BTCUSDT", Field: "confidence", Original: 70.0, Replayed: 80.0},
+			}},
+		{name: "symbols sorted whatever the answer order", original: []agent.Decision{eth, btc},
+			replayed: []agent.Decision{
+				with(eth, func(d *agent.Decision) { d.LimitPrice = 3100 }),
+				with(btc, func(d *agent.Decision) { d.Action = "sell" }),
+			},
+			want: []Change{
+				{Symbol: "BTCUSDT", Field: "action", Original: "buy", Replayed: "sell"},
+				{Symbol: "ETHUSDT", Field: "limitPrice", Original: 3000.0, Replayed: 3100.0},
+			}},
+		{name: "dropped symbol becomes none", original: []agent.Decision{eth},
+			replayed: []agent.Decision{{Action: "none", Symbol: "ETHUSDT"}},
+			want: []Change{
+				{Symbol: "ETHUSDT", Field: "action", Original: "sell", Replayed: "none"},
+				{Symbol: "ETHUSDT", Field: "size", Original: 2.0, Replayed: 0.0},
+				{Symbol: "ETHUSDT", Field: "order", Original: "limit", Replayed: ""},
+				{Symbol: "ETHUSDT", Field: "limitPrice", Original: 3000.0, Replayed: 0.0},
+				{Symbol: "ETHUSDT", Field: "confidence", Original: 60.0, Replayed: 0.0},
+			}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Diff(tt.original, tt.replayed); !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("Diff = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSummary(t *testing.T) {
+	buy := agent.Decision{Action: "buy", Symbol: "BTCUSDT", Size: 1}
+	sell := agent.Decision{Action: "sell", Symbol: "ETHUSDT", Size: 1}
+	cycles := []Cycle{
+		{Original: []Outcome{{Decision: buy}}, Replayed: []agent.Decision{buy}},
+		{Original: []Outcome{{Decision: buy}}, Replayed: nil, Changes: Diff([]agent.Decision{buy}, nil)},
+		{Replayed: []agent.Decision{sell, buy}, Changes: Diff(nil, []agent.Decision{sell, buy})},
+		{},
+		{Original: []Outcome{{Decision: buy}}, Error: "timeout"},
+	}
+	s := Summary{Actions: make(map[string]int)}
+	for _, c := range cycles {
+		s.add(c)
+	}
+	want := Summary{Cycles: 5, Unchanged: 2, Changed: 2, Failed: 1, Actions: map[string]int{
+		"buy->buy": 1, "buy->none": 1, "none->buy": 1, "none->sell": 1, "none->none": 1,
+	}}
+	if !reflect.DeepEqual(s, want) {
+		t.Fatalf("summary = %+v, want %+v", s, want)
+	}
+}
+
+func TestLoadRange(t *testing.T) {
+	from := time.Date(2025, 11, 20, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name    string
+		req     Request
+		wantErr string
+	}{
+		{name: "neither call nor range", req: Request{To: from}, wantErr: "either a call id or a time range is required"},
+		{name: "empty range", req: Request{From: from, To: from}, wantErr: "from must be before to"},
+		{name: "reversed range", req: Request{From: from, To: from.Add(-time.Hour)}, wantErr: "from must be before to"},
+		{name: "from in the future", req: Request{From: time.Now().Add(time.Hour)}, wantErr: "from must be before to"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := (&Replayer{}).load(context.Background(), 1, tt.req)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("load error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
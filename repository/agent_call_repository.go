@@ -0,0 +1,101 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"deepseek-trader/models"
+
+	_ "embed"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+var (
+	//go:embed sql/agent_call/create.sql
+	createAgentCallSQL string
+
+	//go:embed sql/agent_call/find.sql
+	findAgentCallSQL string
+
+	//go:embed sql/agent_call/find_by_decision.sql
+	findAgentCallByDecisionSQL string
+
+	//go:embed sql/agent_call/list.sql
+	listAgentCallsSQL string
+
+	//go:embed sql/agent_call/list_range.sql
+	listAgentCallsRangeSQL string
+
+	//go:embed sql/agent_call/link_decisions.sql
+	linkAgentCallDecisionsSQL string
+)
+
+type AgentCallRepository struct {
+	db *sqlx.DB
+}
+
+// Create stores a call and links the user's decisions it produced to it, in one transaction.
+func (r *AgentCallRepository) Create(ctx context.Context, c *models.AgentCall, decisionIDs []int64) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	// lib/pq sends []byte as bytea, so the snapshot goes over as text.
+	err = tx.
+		QueryRowxContext(ctx, createAgentCallSQL,
+			c.UserID, c.DecisionID, c.Provider, c.Model, string(c.Snapshot), c.SystemPrompt, c.UserPrompt, c.Response,
+			c.RepairPrompt, c.RepairResponse, c.LatencyMS, c.PromptTokens, c.CompletionTokens, c.Error).
+		Scan(&c.ID, &c.CreatedAt)
+	if err != nil {
+		return err
+	}
+	if len(decisionIDs) > 0 {
+		if _, err := tx.ExecContext(ctx, linkAgentCallDecisionsSQL, c.ID, pq.Array(decisionIDs), c.UserID); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func (r *AgentCallRepository) Find(ctx context.Context, userID, id int64) (models.AgentCall, error) {
+	var c models.AgentCall
+
+	if err := r.db.GetContext(ctx, &c, findAgentCallSQL, id, userID); err != nil {
+		return models.AgentCall{}, err
+	}
+	return c, nil
+}
+
+// FindByDecision returns the call that produced the decision.
+func (r *AgentCallRepository) FindByDecision(ctx context.Context, userID, decisionID int64) (models.AgentCall, error) {
+	var c models.AgentCall
+
+	if err := r.db.GetContext(ctx, &c, findAgentCallByDecisionSQL, decisionID, userID); err != nil {
+		return models.AgentCall{}, err
+	}
+	return c, nil
+}
+
+// List returns the user's latest calls without snapshots, prompts or answers.
+func (r *AgentCallRepository) List(ctx context.Context, userID int64, limit int) ([]models.AgentCall, error) {
+	var items []models.AgentCall
+
+	if err := r.db.SelectContext(ctx, &items, listAgentCallsSQL, userID, limit); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// Range returns the user's calls created in [from, to) in full, oldest first.
+func (r *AgentCallRepository) Range(ctx context.Context, userID int64, from, to time.Time, limit int) ([]models.AgentCall, error) {
+	var items []models.AgentCall
+
+	if err := r.db.SelectContext(ctx, &items, listAgentCallsRangeSQL, userID, from, to, limit); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
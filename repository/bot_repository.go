@@ -0,0 +1,74 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"deepseek-trader/models"
+
+	_ "embed"
+
+	"github.com/jmoiron/sqlx"
+)
+
+var (
+	//go:embed sql/bot/find.sql
+	findBotSQL string
+
+	//go:embed sql/bot/upsert.sql
+	upsertBotSQL string
+
+	//go:embed sql/bot/list_enabled.sql
+	listEnabledBotsSQL string
+
+	//go:embed sql/bot/trip.sql
+	tripBotSQL string
+
+	//go:embed sql/bot/rearm.sql
+	rearmBotSQL string
+)
+
+type BotRepository struct {
+	db *sqlx.DB
+}
+
+func (r *BotRepository) Find(ctx context.Context, userID int64) (models.Bot, error) {
+	var b models.Bot
+
+	if err := r.db.GetContext(ctx, &b, findBotSQL, userID); err != nil {
+		return models.Bot{}, err
+	}
+	return b, nil
+}
+
+func (r *BotRepository) Upsert(ctx context.Context, b *models.Bot) error {
+	// lib/pq sends []byte as bytea, so the JSON goes over as text.
+	return r.db.
+		QueryRowxContext(ctx, upsertBotSQL, b.UserID, string(b.Config), b.Enabled).
+		Scan(&b.CreatedAt, &b.UpdatedAt)
+}
+
+// ListEnabled returns the bots that should be running.
+func (r *BotRepository) ListEnabled(ctx context.Context) ([]models.Bot, error) {
+	var items []models.Bot
+
+	if err := r.db.SelectContext(ctx, &items, listEnabledBotsSQL); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// Trip disables the bot and records why it was stopped, creating the row for a bot that never ran.
+func (r *BotRepository) Trip(ctx context.Context, userID int64, reason string) (time.Time, error) {
+	var at time.Time
+	if err := r.db.QueryRowxContext(ctx, tripBotSQL, userID, reason).Scan(&at); err != nil {
+		return time.Time{}, err
+	}
+	return at, nil
+}
+
+// Rearm clears the bot's trip so it may be started again.
+func (r *BotRepository) Rearm(ctx context.Context, userID int64) error {
+	_, err := r.db.ExecContext(ctx, rearmBotSQL, userID)
+	return err
+}
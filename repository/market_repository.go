@@ -0,0 +1,92 @@
+package repository
+
+import (
+	"context"
+
+	"deepseek-trader/models"
+
+	_ "embed"
+
+	"github.com/jmoiron/sqlx"
+)
+
+var (
+	//go:embed sql/market/upsert_candle.sql
+	upsertCandleSQL string
+
+	//go:embed sql/market/list_candles.sql
+	listCandlesSQL string
+
+	//go:embed sql/market/list_candle_times.sql
+	listCandleTimesSQL string
+
+	//go:embed sql/market/create_book.sql
+	createBookSnapshotSQL string
+
+	//go:embed sql/market/list_books.sql
+	listBookSnapshotsSQL string
+)
+
+type MarketRepository struct {
+	db *sqlx.DB
+}
+
+// UpsertCandles inserts or overwrites candles by (coin, interval, start_time) in one transaction.
+func (r *MarketRepository) UpsertCandles(ctx context.Context, candles []models.Candle) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	stmt, err := tx.PreparexContext(ctx, upsertCandleSQL)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for _, c := range candles {
+		if _, err := stmt.ExecContext(ctx, c.Coin, c.Interval, c.StartTime, c.EndTime,
+			c.Open, c.High, c.Low, c.Close, c.Volume, c.NumTrades); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// Candles returns up to limit candles starting in [from, to] (unix ms), oldest first.
+func (r *MarketRepository) Candles(ctx context.Context, coin, interval string, from, to int64, limit int) ([]models.Candle, error) {
+	var items []models.Candle
+
+	if err := r.db.SelectContext(ctx, &items, listCandlesSQL, coin, interval, from, to, limit); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// CandleTimes returns the start times of the stored candles in [from, to], ascending.
+func (r *MarketRepository) CandleTimes(ctx context.Context, coin, interval string, from, to int64) ([]int64, error) {
+	var items []int64
+
+	if err := r.db.SelectContext(ctx, &items, listCandleTimesSQL, coin, interval, from, to); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+func (r *MarketRepository) CreateBookSnapshot(ctx context.Context, b *models.BookSnapshot) error {
+	// lib/pq sends []byte as bytea, so the JSON goes over as text.
+	return r.db.
+		QueryRowxContext(ctx, createBookSnapshotSQL, b.Coin, b.Time, string(b.Bids), string(b.Asks)).
+		Scan(&b.ID, &b.CreatedAt)
+}
+
+// BookSnapshots returns up to limit snapshots taken in [from, to] (unix ms), oldest first.
+func (r *MarketRepository) BookSnapshots(ctx context.Context, coin string, from, to int64, limit int) ([]models.BookSnapshot, error) {
+	var items []models.BookSnapshot
+
+	if err := r.db.SelectContext(ctx, &items, listBookSnapshotsSQL, coin, from, to, limit); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
@@ -0,0 +1,99 @@
+package repository
+
+import (
+	"context"
+
+	"deepseek-trader/models"
+
+	_ "embed"
+
+	"github.com/jmoiron/sqlx"
+)
+
+var (
+	//go:embed sql/order/create.sql
+	createOrderSQL string
+
+	//go:embed sql/order/update_result.sql
+	updateOrderResultSQL string
+
+	//go:embed sql/order/list_active.sql
+	listActiveOrdersSQL string
+
+	//go:embed sql/order/list_by_decision.sql
+	listOrdersByDecisionSQL string
+
+	//go:embed sql/order/find_by_exchange_oid.sql
+	findOrderByExchangeOIDSQL string
+
+	//go:embed sql/order/find_by_cloid.sql
+	findOrderByCloidSQL string
+
+	//go:embed sql/order/list_pending.sql
+	listPendingOrdersSQL string
+)
+
+type OrderRepository struct {
+	db *sqlx.DB
+}
+
+// Create inserts the order. An order whose cloid is already stored is not inserted and Create returns
+// sql.ErrNoRows.
+func (r *OrderRepository) Create(ctx context.Context, o *models.Order) error {
+	return r.db.
+		QueryRowxContext(ctx, createOrderSQL, o.UserID, o.DecisionID, o.Symbol, o.Side, o.OrderType, o.Kind, o.Size, o.Price, o.TriggerPx, o.Status, o.Cloid).
+		Scan(&o.ID, &o.CreatedAt, &o.UpdatedAt)
+}
+
+func (r *OrderRepository) UpdateResult(ctx context.Context, o *models.Order) error {
+	return r.db.
+		QueryRowxContext(ctx, updateOrderResultSQL, o.ID, o.ExchangeOID, o.Status, o.FilledSize, o.AvgPrice, o.Error).
+		Scan(&o.UpdatedAt)
+}
+
+// ListActive returns a user's orders still resting on the exchange.
+func (r *OrderRepository) ListActive(ctx context.Context, userID int64) ([]models.Order, error) {
+	var items []models.Order
+
+	if err := r.db.SelectContext(ctx, &items, listActiveOrdersSQL, userID); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+func (r *OrderRepository) ListByDecision(ctx context.Context, decisionID int64) ([]models.Order, error) {
+	var items []models.Order
+
+	if err := r.db.SelectContext(ctx, &items, listOrdersByDecisionSQL, decisionID); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+func (r *OrderRepository) FindByExchangeOID(ctx context.Context, userID, oid int64) (models.Order, error) {
+	var o models.Order
+
+	if err := r.db.GetContext(ctx, &o, findOrderByExchangeOIDSQL, userID, oid); err != nil {
+		return models.Order{}, err
+	}
+	return o, nil
+}
+
+func (r *OrderRepository) FindByCloid(ctx context.Context, cloid string) (models.Order, error) {
+	var o models.Order
+
+	if err := r.db.GetContext(ctx, &o, findOrderByCloidSQL, cloid); err != nil {
+		return models.Order{}, err
+	}
+	return o, nil
+}
+
+// ListPending returns a user's orders stored with a cloid but never updated with the exchange's answer.
+func (r *OrderRepository) ListPending(ctx context.Context, userID int64) ([]models.Order, error) {
+	var items []models.Order
+
+	if err := r.db.SelectContext(ctx, &items, listPendingOrdersSQL, userID); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
@@ -0,0 +1,187 @@
+package repository
+
+import (
+	"context"
+
+	"deepseek-trader/models"
+
+	_ "embed"
+
+	"github.com/jmoiron/sqlx"
+)
+
+var (
+	//go:embed sql/paper/find_account.sql
+	findPaperAccountSQL string
+
+	//go:embed sql/paper/create_account.sql
+	createPaperAccountSQL string
+
+	//go:embed sql/paper/update_account.sql
+	updatePaperAccountSQL string
+
+	//go:embed sql/paper/list_positions.sql
+	listPaperPositionsSQL string
+
+	//go:embed sql/paper/find_position.sql
+	findPaperPositionSQL string
+
+	//go:embed sql/paper/upsert_position.sql
+	upsertPaperPositionSQL string
+
+	//go:embed sql/paper/create_order.sql
+	createPaperOrderSQL string
+
+	//go:embed sql/paper/update_order.sql
+	updatePaperOrderSQL string
+
+	//go:embed sql/paper/find_order.sql
+	findPaperOrderSQL string
+
+	//go:embed sql/paper/find_order_by_cloid.sql
+	findPaperOrderByCloidSQL string
+
+	//go:embed sql/paper/list_open_orders.sql
+	listOpenPaperOrdersSQL string
+
+	//go:embed sql/paper/create_fill.sql
+	createPaperFillSQL string
+
+	//go:embed sql/paper/list_fills.sql
+	listPaperFillsSQL string
+
+	//go:embed sql/paper/upsert_leverage.sql
+	upsertPaperLeverageSQL string
+
+	//go:embed sql/paper/list_leverage.sql
+	listPaperLeverageSQL string
+)
+
+type PaperRepository struct {
+	db *sqlx.DB
+}
+
+// EnsureAccount returns the named paper account, creating it with the initial balance if missing.
+func (r *PaperRepository) EnsureAccount(ctx context.Context, name string, initial float64) (models.PaperAccount, error) {
+	if _, err := r.db.ExecContext(ctx, createPaperAccountSQL, name, initial); err != nil {
+		return models.PaperAccount{}, err
+	}
+	return r.FindAccount(ctx, name)
+}
+
+func (r *PaperRepository) FindAccount(ctx context.Context, name string) (models.PaperAccount, error) {
+	var a models.PaperAccount
+
+	if err := r.db.GetContext(ctx, &a, findPaperAccountSQL, name); err != nil {
+		return models.PaperAccount{}, err
+	}
+	return a, nil
+}
+
+func (r *PaperRepository) Positions(ctx context.Context, accountID int64) ([]models.PaperPosition, error) {
+	var items []models.PaperPosition
+
+	if err := r.db.SelectContext(ctx, &items, listPaperPositionsSQL, accountID); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// Position returns the position for a coin; a flat zero position when none was ever opened.
+func (r *PaperRepository) Position(ctx context.Context, accountID int64, coin string) (models.PaperPosition, error) {
+	var items []models.PaperPosition
+
+	if err := r.db.SelectContext(ctx, &items, findPaperPositionSQL, accountID, coin); err != nil {
+		return models.PaperPosition{}, err
+	}
+	if len(items) == 0 {
+		return models.PaperPosition{AccountID: accountID, Coin: coin}, nil
+	}
+	return items[0], nil
+}
+
+func (r *PaperRepository) CreateOrder(ctx context.Context, o *models.PaperOrder) error {
+	return r.db.
+		QueryRowxContext(ctx, createPaperOrderSQL, o.AccountID, o.Coin, o.IsBuy, o.Size, o.LimitPrice, o.TriggerPx, o.Tpsl, o.ReduceOnly, o.Status, o.Cloid).
+		Scan(&o.ID, &o.CreatedAt, &o.UpdatedAt)
+}
+
+func (r *PaperRepository) UpdateOrder(ctx context.Context, o models.PaperOrder) error {
+	_, err := r.db.ExecContext(ctx, updatePaperOrderSQL, o.ID, o.Status, o.FilledSize, o.AvgPrice)
+	return err
+}
+
+func (r *PaperRepository) FindOrder(ctx context.Context, accountID, id int64) (models.PaperOrder, error) {
+	var o models.PaperOrder
+
+	if err := r.db.GetContext(ctx, &o, findPaperOrderSQL, accountID, id); err != nil {
+		return models.PaperOrder{}, err
+	}
+	return o, nil
+}
+
+// FindOrderByCloid returns the account's latest order placed with cloid.
+func (r *PaperRepository) FindOrderByCloid(ctx context.Context, accountID int64, cloid string) (models.PaperOrder, error) {
+	var o models.PaperOrder
+
+	if err := r.db.GetContext(ctx, &o, findPaperOrderByCloidSQL, accountID, cloid); err != nil {
+		return models.PaperOrder{}, err
+	}
+	return o, nil
+}
+
+func (r *PaperRepository) OpenOrders(ctx context.Context, accountID int64) ([]models.PaperOrder, error) {
+	var items []models.PaperOrder
+
+	if err := r.db.SelectContext(ctx, &items, listOpenPaperOrdersSQL, accountID); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// RecordFill stores a fill together with the position and account balances it leads to, in one transaction.
+func (r *PaperRepository) RecordFill(ctx context.Context, a models.PaperAccount, p models.PaperPosition, f *models.PaperFill) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if _, err := tx.ExecContext(ctx, upsertPaperPositionSQL, p.AccountID, p.Coin, p.Size, p.EntryPrice); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, updatePaperAccountSQL, a.ID, a.Cash, a.RealizedPnL, a.FeesPaid); err != nil {
+		return err
+	}
+	err = tx.
+		QueryRowxContext(ctx, createPaperFillSQL, f.AccountID, f.OrderID, f.Coin, f.IsBuy, f.Size, f.Price, f.Fee, f.StartPosition, f.ClosedPnL).
+		Scan(&f.ID, &f.CreatedAt)
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (r *PaperRepository) Fills(ctx context.Context, accountID int64, limit int) ([]models.PaperFill, error) {
+	var items []models.PaperFill
+
+	if err := r.db.SelectContext(ctx, &items, listPaperFillsSQL, accountID, limit); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// SetLeverage stores the leverage the account uses for a coin.
+func (r *PaperRepository) SetLeverage(ctx context.Context, l models.PaperLeverage) error {
+	_, err := r.db.ExecContext(ctx, upsertPaperLeverageSQL, l.AccountID, l.Coin, l.Leverage, l.Isolated)
+	return err
+}
+
+func (r *PaperRepository) Leverage(ctx context.Context, accountID int64) ([]models.PaperLeverage, error) {
+	var items []models.PaperLeverage
+
+	if err := r.db.SelectContext(ctx, &items, listPaperLeverageSQL, accountID); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
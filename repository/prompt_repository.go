@@ -0,0 +1,110 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"deepseek-trader/models"
+
+	_ "embed"
+
+	"github.com/jmoiron/sqlx"
+)
+
+var (
+	//go:embed sql/prompt/create.sql
+	createPromptSQL string
+
+	//go:embed sql/prompt/list.sql
+	listPromptsSQL string
+
+	//go:embed sql/prompt/find.sql
+	findPromptSQL string
+
+	//go:embed sql/prompt/find_active.sql
+	findActivePromptSQL string
+
+	//go:embed sql/prompt/find_previous.sql
+	findPreviousPromptSQL string
+
+	//go:embed sql/prompt/deactivate.sql
+	deactivatePromptsSQL string
+
+	//go:embed sql/prompt/activate.sql
+	activatePromptSQL string
+)
+
+type PromptRepository struct {
+	db *sqlx.DB
+}
+
+// Create stores p as the user's next version.
+func (r *PromptRepository) Create(ctx context.Context, p *models.PromptVersion) error {
+	return r.db.
+		QueryRowxContext(ctx, createPromptSQL, p.UserID, p.Name, p.SystemTemplate, p.UserTemplate, p.Note).
+		Scan(&p.ID, &p.Version, &p.CreatedAt)
+}
+
+// List returns the user's versions, newest first.
+func (r *PromptRepository) List(ctx context.Context, userID int64) ([]models.PromptVersion, error) {
+	var items []models.PromptVersion
+
+	if err := r.db.SelectContext(ctx, &items, listPromptsSQL, userID); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+func (r *PromptRepository) Find(ctx context.Context, userID, id int64) (models.PromptVersion, error) {
+	var p models.PromptVersion
+
+	if err := r.db.GetContext(ctx, &p, findPromptSQL, id, userID); err != nil {
+		return models.PromptVersion{}, err
+	}
+	return p, nil
+}
+
+func (r *PromptRepository) FindActive(ctx context.Context, userID int64) (models.PromptVersion, error) {
+	var p models.PromptVersion
+
+	if err := r.db.GetContext(ctx, &p, findActivePromptSQL, userID); err != nil {
+		return models.PromptVersion{}, err
+	}
+	return p, nil
+}
+
+// FindPrevious returns the user's highest version below version.
+func (r *PromptRepository) FindPrevious(ctx context.Context, userID int64, version int) (models.PromptVersion, error) {
+	var p models.PromptVersion
+
+	if err := r.db.GetContext(ctx, &p, findPreviousPromptSQL, userID, version); err != nil {
+		return models.PromptVersion{}, err
+	}
+	return p, nil
+}
+
+// Activate makes the version the user's only active one; id 0 deactivates all, which selects the
+// built-in prompt.
+func (r *PromptRepository) Activate(ctx context.Context, userID, id int64) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if _, err := tx.ExecContext(ctx, deactivatePromptsSQL, userID); err != nil {
+		return err
+	}
+	if id != 0 {
+		res, err := tx.ExecContext(ctx, activatePromptSQL, id, userID)
+		if err != nil {
+			return err
+		}
+		if n, err := res.RowsAffected(); err != nil {
+			return err
+		} else if n == 0 {
+			return sql.ErrNoRows
+		}
+	}
+	return tx.Commit()
+}
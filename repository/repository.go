@@ -9,6 +9,12 @@ type Repositories struct {
 	Trades  *TradeRepository
 	Stats   *StatsRepository
 	Users   *UserRepository
+	Orders  *OrderRepository
+	Paper   *PaperRepository
+	Bots    *BotRepository
+	Market  *MarketRepository
+	Calls   *AgentCallRepository
+	Prompts *PromptRepository
 }
 
 func NewRepositories(db *sqlx.DB) *Repositories {
@@ -17,5 +23,11 @@ func NewRepositories(db *sqlx.DB) *Repositories {
 		Trades:  &TradeRepository{db: db},
 		Stats:   &StatsRepository{db: db},
 		Users:   &UserRepository{db: db},
+		Orders:  &OrderRepository{db: db},
+		Paper:   &PaperRepository{db: db},
+		Bots:    &BotRepository{db: db},
+		Market:  &MarketRepository{db: db},
+		Calls:   &AgentCallRepository{db: db},
+		Prompts: &PromptRepository{db: db},
 	}
 }
@@ -0,0 +1,5 @@
+INSERT INTO agent_calls (
+    user_id, decision_id, provider, model, snapshot, system_prompt, user_prompt, response,
+    repair_prompt, repair_response, latency_ms, prompt_tokens, completion_tokens, error
+) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
+RETURNING id, created_at;
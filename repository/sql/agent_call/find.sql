@@ -0,0 +1,5 @@
+SELECT
+    id, user_id, decision_id, provider, model, snapshot, system_prompt, user_prompt, response,
+    repair_prompt, repair_response, latency_ms, prompt_tokens, completion_tokens, error, created_at
+FROM agent_calls
+WHERE id = $1 AND user_id = $2;
@@ -0,0 +1,6 @@
+SELECT
+    c.id, c.user_id, c.decision_id, c.provider, c.model, c.snapshot, c.system_prompt, c.user_prompt, c.response,
+    c.repair_prompt, c.repair_response, c.latency_ms, c.prompt_tokens, c.completion_tokens, c.error, c.created_at
+FROM agent_calls c
+JOIN decisions d ON d.agent_call_id = c.id
+WHERE d.id = $1 AND c.user_id = $2;
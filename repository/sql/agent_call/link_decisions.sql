@@ -0,0 +1 @@
+UPDATE decisions SET agent_call_id = $1 WHERE id = ANY($2) AND user_id = $3;
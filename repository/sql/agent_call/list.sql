@@ -0,0 +1,6 @@
+SELECT
+    id, user_id, decision_id, provider, model, latency_ms, prompt_tokens, completion_tokens, error, created_at
+FROM agent_calls
+WHERE user_id = $1
+ORDER BY id DESC
+LIMIT $2;
@@ -0,0 +1,7 @@
+SELECT
+    id, user_id, decision_id, provider, model, snapshot, system_prompt, user_prompt, response,
+    repair_prompt, repair_response, latency_ms, prompt_tokens, completion_tokens, error, created_at
+FROM agent_calls
+WHERE user_id = $1 AND created_at >= $2 AND created_at < $3
+ORDER BY id
+LIMIT $4;
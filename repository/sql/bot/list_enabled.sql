@@ -0,0 +1 @@
+SELECT user_id, config, enabled, tripped_at, trip_reason, created_at, updated_at FROM bots WHERE enabled ORDER BY user_id;
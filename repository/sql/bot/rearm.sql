@@ -0,0 +1 @@
+UPDATE bots SET tripped_at = NULL, trip_reason = '', updated_at = NOW() WHERE user_id = $1;
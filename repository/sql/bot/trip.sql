@@ -0,0 +1,4 @@
+INSERT INTO bots (user_id, enabled, tripped_at, trip_reason)
+VALUES ($1, FALSE, NOW(), $2)
+ON CONFLICT (user_id) DO UPDATE SET enabled = FALSE, tripped_at = NOW(), trip_reason = EXCLUDED.trip_reason, updated_at = NOW()
+RETURNING tripped_at;
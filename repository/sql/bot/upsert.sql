@@ -0,0 +1,4 @@
+INSERT INTO bots (user_id, config, enabled)
+VALUES ($1, $2, $3)
+ON CONFLICT (user_id) DO UPDATE SET config = EXCLUDED.config, enabled = EXCLUDED.enabled, updated_at = NOW()
+RETURNING created_at, updated_at;
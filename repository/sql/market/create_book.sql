@@ -0,0 +1 @@
+INSERT INTO book_snapshots (coin, time, bids, asks) VALUES ($1, $2, $3, $4) RETURNING id, created_at
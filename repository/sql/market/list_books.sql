@@ -0,0 +1,4 @@
+SELECT id, coin, time, bids, asks, created_at
+FROM book_snapshots WHERE coin=$1 AND time >= $2 AND time <= $3
+ORDER BY time
+LIMIT $4
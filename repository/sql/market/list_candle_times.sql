@@ -0,0 +1 @@
+SELECT start_time FROM candles WHERE coin=$1 AND interval=$2 AND start_time >= $3 AND start_time <= $4 ORDER BY start_time
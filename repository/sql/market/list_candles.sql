@@ -0,0 +1,4 @@
+SELECT coin, interval, start_time, end_time, open, high, low, close, volume, num_trades
+FROM candles WHERE coin=$1 AND interval=$2 AND start_time >= $3 AND start_time <= $4
+ORDER BY start_time
+LIMIT $5
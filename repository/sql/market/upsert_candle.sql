@@ -0,0 +1,5 @@
+INSERT INTO candles (coin, interval, start_time, end_time, open, high, low, close, volume, num_trades)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+ON CONFLICT (coin, interval, start_time) DO UPDATE SET
+    end_time = EXCLUDED.end_time, open = EXCLUDED.open, high = EXCLUDED.high, low = EXCLUDED.low,
+    close = EXCLUDED.close, volume = EXCLUDED.volume, num_trades = EXCLUDED.num_trades, updated_at = NOW();
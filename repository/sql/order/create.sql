@@ -0,0 +1,4 @@
+INSERT INTO orders (user_id, decision_id, symbol, side, order_type, kind, size, price, trigger_price, status, cloid)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+ON CONFLICT (cloid) WHERE cloid <> '' DO NOTHING
+RETURNING id, created_at, updated_at;
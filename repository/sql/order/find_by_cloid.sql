@@ -0,0 +1,5 @@
+SELECT
+    id, user_id, decision_id, symbol, side, order_type, kind, size, price, trigger_price,
+    exchange_oid, cloid, status, filled_size, avg_price, error, created_at, updated_at
+FROM orders
+WHERE cloid = $1;
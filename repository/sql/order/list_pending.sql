@@ -0,0 +1,6 @@
+SELECT
+    id, user_id, decision_id, symbol, side, order_type, kind, size, price, trigger_price,
+    exchange_oid, cloid, status, filled_size, avg_price, error, created_at, updated_at
+FROM orders
+WHERE user_id = $1 AND status = 'pending' AND cloid <> ''
+ORDER BY id;
@@ -0,0 +1,4 @@
+UPDATE orders
+SET exchange_oid = $2, status = $3, filled_size = $4, avg_price = $5, error = $6, updated_at = NOW()
+WHERE id = $1
+RETURNING updated_at;
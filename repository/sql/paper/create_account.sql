@@ -0,0 +1,2 @@
+INSERT INTO paper_accounts (name, initial_balance, cash) VALUES ($1, $2, $2)
+ON CONFLICT (name) DO NOTHING;
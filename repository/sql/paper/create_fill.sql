@@ -0,0 +1,3 @@
+INSERT INTO paper_fills (account_id, order_id, coin, is_buy, size, price, fee, start_position, closed_pnl)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+RETURNING id, created_at;
@@ -0,0 +1,3 @@
+INSERT INTO paper_orders (account_id, coin, is_buy, size, limit_price, trigger_price, tpsl, reduce_only, status, cloid)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+RETURNING id, created_at, updated_at;
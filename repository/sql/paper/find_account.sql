@@ -0,0 +1 @@
+SELECT id, name, initial_balance, cash, realized_pnl, fees_paid, created_at, updated_at FROM paper_accounts WHERE name=$1
\ No newline at end of file
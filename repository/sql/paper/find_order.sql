@@ -0,0 +1,2 @@
+SELECT id, account_id, coin, is_buy, size, limit_price, trigger_price, tpsl, reduce_only, cloid, status, filled_size, avg_price, created_at, updated_at
+FROM paper_orders WHERE account_id=$1 AND id=$2
\ No newline at end of file
@@ -0,0 +1 @@
+SELECT account_id, coin, size, entry_price, updated_at FROM paper_positions WHERE account_id=$1 AND coin=$2
\ No newline at end of file
@@ -0,0 +1,2 @@
+SELECT id, account_id, order_id, coin, is_buy, size, price, fee, start_position, closed_pnl, created_at
+FROM paper_fills WHERE account_id=$1 ORDER BY id DESC LIMIT $2
\ No newline at end of file
@@ -0,0 +1 @@
+SELECT account_id, coin, leverage, isolated, updated_at FROM paper_leverage WHERE account_id=$1 ORDER BY coin
\ No newline at end of file
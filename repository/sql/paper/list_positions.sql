@@ -0,0 +1 @@
+SELECT account_id, coin, size, entry_price, updated_at FROM paper_positions WHERE account_id=$1 AND size <> 0 ORDER BY coin
\ No newline at end of file
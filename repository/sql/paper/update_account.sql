@@ -0,0 +1 @@
+UPDATE paper_accounts SET cash=$2, realized_pnl=$3, fees_paid=$4, updated_at=NOW() WHERE id=$1
\ No newline at end of file
@@ -0,0 +1 @@
+UPDATE paper_orders SET status=$2, filled_size=$3, avg_price=$4, updated_at=NOW() WHERE id=$1
\ No newline at end of file
@@ -0,0 +1,4 @@
+INSERT INTO paper_leverage (account_id, coin, leverage, isolated)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (account_id, coin) DO UPDATE
+SET leverage = EXCLUDED.leverage, isolated = EXCLUDED.isolated, updated_at = NOW();
@@ -0,0 +1,4 @@
+INSERT INTO paper_positions (account_id, coin, size, entry_price)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (account_id, coin) DO UPDATE
+SET size = EXCLUDED.size, entry_price = EXCLUDED.entry_price, updated_at = NOW();
@@ -0,0 +1 @@
+UPDATE prompt_versions SET active = TRUE, activated_at = NOW() WHERE id = $1 AND user_id = $2;
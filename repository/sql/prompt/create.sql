@@ -0,0 +1,5 @@
+INSERT INTO prompt_versions (user_id, name, version, system_template, user_template, note)
+SELECT $1, $2, COALESCE(MAX(version), 0) + 1, $3, $4, $5
+FROM prompt_versions
+WHERE user_id = $1
+RETURNING id, version, created_at;
@@ -0,0 +1 @@
+UPDATE prompt_versions SET active = FALSE WHERE user_id = $1 AND active;
@@ -0,0 +1,3 @@
+SELECT id, user_id, name, version, system_template, user_template, note, active, activated_at, created_at
+FROM prompt_versions
+WHERE user_id = $1 AND active;
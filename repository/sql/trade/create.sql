@@ -0,0 +1 @@
+INSERT INTO trades (user_id, symbol, side, qty, price, pnl) VALUES ($1, $2, $3, $4, $5, $6) RETURNING id, created_at
\ No newline at end of file
@@ -1,6 +1,5 @@
 INSERT INTO decisions (
-    action, symbol, size, order_type, limit_price, tp1, tp2, tp3, sl
-) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+    user_id, action, symbol, size, order_type, limit_price, tp1, tp2, tp3, sl, prompt_version_id,
+    confidence, scores, rationale, fraction
+) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)
 RETURNING id, created_at;
-
-
@@ -0,0 +1,24 @@
+select 
+    id,
+    user_id,
+    action,
+    symbol, 
+    size, 
+    order_type, 
+    limit_price, 
+    fraction,
+    tp1, 
+    tp2, 
+    tp3, 
+    sl, 
+    status,
+    reason,
+    prompt_version_id,
+    agent_call_id,
+    confidence,
+    scores,
+    rationale,
+    created_at 
+from decisions 
+where agent_call_id = $1 and user_id = $2
+order by id;
@@ -0,0 +1,23 @@
+select 
+    id,
+    user_id,
+    action,
+    symbol, 
+    size, 
+    order_type, 
+    limit_price, 
+    fraction,
+    tp1, 
+    tp2, 
+    tp3, 
+    sl, 
+    status,
+    reason,
+    prompt_version_id,
+    agent_call_id,
+    confidence,
+    scores,
+    rationale,
+    created_at 
+from decisions 
+where id = $1;
\ No newline at end of file
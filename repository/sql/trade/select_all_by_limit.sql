@@ -0,0 +1 @@
+SELECT id, user_id, symbol, side, qty, price, pnl, created_at FROM trades WHERE user_id = $1 ORDER BY id DESC LIMIT $2
\ No newline at end of file
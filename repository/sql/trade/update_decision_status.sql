@@ -0,0 +1 @@
+UPDATE decisions SET status = $2, reason = $3 WHERE id = $1;
@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"deepseek-trader/models"
 
@@ -22,6 +23,18 @@ var (
 
 	//go:embed sql/trade/latest_dicisions.sql
 	latestDecisionsSQL string
+
+	//go:embed sql/trade/decisions_since.sql
+	decisionsSinceSQL string
+
+	//go:embed sql/trade/find_decision.sql
+	findDecisionSQL string
+
+	//go:embed sql/trade/decisions_by_call.sql
+	decisionsByCallSQL string
+
+	//go:embed sql/trade/update_decision_status.sql
+	updateDecisionStatusSQL string
 )
 
 type TradeRepository struct {
@@ -30,30 +43,70 @@ type TradeRepository struct {
 
 func (r *TradeRepository) Create(ctx context.Context, t *models.Trade) error {
 	return r.db.
-		QueryRowxContext(ctx, createTradeSQL, t.Symbol, t.Side, t.Qty, t.Price, t.PnL).
+		QueryRowxContext(ctx, createTradeSQL, t.UserID, t.Symbol, t.Side, t.Qty, t.Price, t.PnL).
 		Scan(&t.ID, &t.CreatedAt)
 }
 
-func (r *TradeRepository) List(ctx context.Context, limit int) ([]models.Trade, error) {
+func (r *TradeRepository) List(ctx context.Context, userID int64, limit int) ([]models.Trade, error) {
 	var items []models.Trade
 
-	if err := r.db.SelectContext(ctx, &items, listTradesSQL, limit); err != nil {
+	if err := r.db.SelectContext(ctx, &items, listTradesSQL, userID, limit); err != nil {
 		return nil, err
 	}
 	return items, nil
 }
 
 func (r *TradeRepository) CreateDecision(ctx context.Context, d *models.Decision) error {
+	// lib/pq sends []byte as bytea, so the scores go over as text.
+	scores := "{}"
+	if len(d.Scores) > 0 {
+		scores = string(d.Scores)
+	}
 	return r.db.
-		QueryRowxContext(ctx, createDecisionSQL, d.Action, d.Symbol, d.Size, d.OrderType, d.LimitPrice, d.TP1, d.TP2, d.TP3, d.SL).
+		QueryRowxContext(ctx, createDecisionSQL,
+			d.UserID, d.Action, d.Symbol, d.Size, d.OrderType, d.LimitPrice, d.TP1, d.TP2, d.TP3, d.SL, d.PromptVersionID,
+			d.Confidence, scores, d.Rationale, d.Fraction).
 		Scan(&d.ID, &d.CreatedAt)
 }
 
-func (r *TradeRepository) LatestDecisions(ctx context.Context, limit int) ([]models.Decision, error) {
+func (r *TradeRepository) LatestDecisions(ctx context.Context, userID int64, limit int) ([]models.Decision, error) {
 	var items []models.Decision
 
-	if err := r.db.SelectContext(ctx, &items, latestDecisionsSQL, limit); err != nil {
+	if err := r.db.SelectContext(ctx, &items, latestDecisionsSQL, userID, limit); err != nil {
 		return nil, err
 	}
 	return items, nil
 }
+
+// DecisionsByCall returns the user's decisions made from one agent call, in the order they were recorded.
+func (r *TradeRepository) DecisionsByCall(ctx context.Context, userID, callID int64) ([]models.Decision, error) {
+	var items []models.Decision
+
+	if err := r.db.SelectContext(ctx, &items, decisionsByCallSQL, callID, userID); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+func (r *TradeRepository) DecisionsSince(ctx context.Context, userID int64, since time.Time) ([]models.Decision, error) {
+	var items []models.Decision
+
+	if err := r.db.SelectContext(ctx, &items, decisionsSinceSQL, userID, since); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+func (r *TradeRepository) UpdateDecisionStatus(ctx context.Context, id int64, status, reason string) error {
+	_, err := r.db.ExecContext(ctx, updateDecisionStatusSQL, id, status, reason)
+	return err
+}
+
+func (r *TradeRepository) FindDecision(ctx context.Context, id int64) (models.Decision, error) {
+	var d models.Decision
+
+	if err := r.db.GetContext(ctx, &d, findDecisionSQL, id); err != nil {
+		return models.Decision{}, err
+	}
+	return d, nil
+}
@@ -0,0 +1,61 @@
+package risk
+
+import (
+	"fmt"
+	"time"
+
+	"deepseek-trader/hyperliquid"
+)
+
+// Breaker sets when a bot's circuit breaker trips. A zero field disables its rule.
+type Breaker struct {
+	// MaxDailyDrawdown is the loss since 00:00 UTC, as a fraction of the account value at that time.
+	MaxDailyDrawdown float64 `json:"maxDailyDrawdown"`
+	// MaxLossStreak consecutive losing closes trip the breaker.
+	MaxLossStreak int `json:"maxLossStreak"`
+	// MaxAPIFailures consecutive failed exchange calls trip the breaker.
+	MaxAPIFailures int `json:"maxApiFailures"`
+	// Flatten closes every position once the open orders are cancelled.
+	Flatten bool `json:"flatten"`
+}
+
+// Trip reports why the breaker trips on the account, empty when it holds. The day's loss is the realized
+// PnL of today's closing fills plus unrealized, the open positions' unrealized PnL, against the balance
+// before that loss. Only fills since armed, when the bot was started, count, so a re-armed bot is not
+// tripped again by the losses that stopped it.
+func (b Breaker) Trip(acct Account, unrealized float64, armed time.Time) string {
+	if b.MaxDailyDrawdown > 0 {
+		from := acct.Now.UTC().Truncate(24 * time.Hour)
+		if armed.After(from) {
+			from = armed
+		}
+		pnl := unrealized
+		for _, f := range since(acct.Fills, from) {
+			pnl += parseF(f.ClosedPnl)
+		}
+		if open := acct.Balance - pnl; pnl < 0 && open > 0 && -pnl/open >= b.MaxDailyDrawdown {
+			return fmt.Sprintf("breaker: daily drawdown of %.2f%% reached the %.2f%% limit", -pnl/open*100, b.MaxDailyDrawdown*100)
+		}
+	}
+	if b.MaxLossStreak > 0 {
+		if losses, _ := lossStreak(since(acct.Fills, armed), b.MaxLossStreak); losses >= b.MaxLossStreak {
+			return fmt.Sprintf("breaker: %d consecutive losing trades", losses)
+		}
+	}
+	return ""
+}
+
+// Failed reports whether failures consecutive failed exchange calls trip the breaker.
+func (b Breaker) Failed(failures int) bool {
+	return b.MaxAPIFailures > 0 && failures >= b.MaxAPIFailures
+}
+
+func since(fills []hyperliquid.UserFill, from time.Time) []hyperliquid.UserFill {
+	out := make([]hyperliquid.UserFill, 0, len(fills))
+	for _, f := range fills {
+		if !time.UnixMilli(f.Time).Before(from) {
+			out = append(out, f)
+		}
+	}
+	return out
+}
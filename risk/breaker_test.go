@@ -0,0 +1,96 @@
+package risk
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"deepseek-trader/hyperliquid"
+)
+
+func TestBreakerTrip(t *testing.T) {
+	daily := Breaker{MaxDailyDrawdown: 0.05}
+	streak := Breaker{MaxLossStreak: 3}
+	// now is 12:00 UTC, so fills older than 12h are from yesterday.
+	tests := []struct {
+		name       string
+		breaker    Breaker
+		balance    float64
+		fills      []hyperliquid.UserFill
+		unrealized float64
+		armed      time.Duration // how long ago the bot was started, zero for long before today
+		want       string        // substring of the reason, empty when the breaker holds
+	}{
+		{name: "no rules", balance: 10000, fills: []hyperliquid.UserFill{fill("-5000", time.Hour)}},
+		{name: "no losses", breaker: daily, balance: 10000},
+		{name: "realized loss below the limit", breaker: daily, balance: 10000,
+			fills: []hyperliquid.UserFill{fill("-400", time.Hour)}},
+		{name: "realized loss over the limit", breaker: daily, balance: 10000,
+			fills: []hyperliquid.UserFill{fill("-600", time.Hour)}, want: "daily drawdown of 5.66%"},
+		{name: "exactly at the limit", breaker: daily, balance: 9500,
+			fills: []hyperliquid.UserFill{fill("-500", time.Hour)}, want: "daily drawdown of 5.00%"},
+		{name: "unrealized loss counts", breaker: daily, balance: 10000, unrealized: -600, want: "daily drawdown"},
+		{name: "unrealized tips a realized loss over", breaker: daily, balance: 10000, unrealized: -300,
+			fills: []hyperliquid.UserFill{fill("-300", time.Hour)}, want: "daily drawdown"},
+		{name: "unrealized gain offsets a realized loss", breaker: daily, balance: 10000, unrealized: 300,
+			fills: []hyperliquid.UserFill{fill("-600", time.Hour)}},
+		{name: "wins offset losses", breaker: daily, balance: 10000,
+			fills: []hyperliquid.UserFill{fill("300", 3*time.Hour), fill("-800", time.Hour)}},
+		{name: "yesterday's losses do not count", breaker: daily, balance: 10000,
+			fills: []hyperliquid.UserFill{fill("-600", 13*time.Hour)}},
+		{name: "losses before the bot was armed do not count", breaker: daily, balance: 10000, armed: time.Hour,
+			fills: []hyperliquid.UserFill{fill("-600", 2*time.Hour), fill("-100", 30*time.Minute)}},
+		{name: "losses since it was armed count", breaker: daily, balance: 10000, armed: 3 * time.Hour,
+			fills: []hyperliquid.UserFill{fill("-600", 2*time.Hour)}, want: "daily drawdown"},
+		{name: "armed yesterday counts from midnight", breaker: daily, balance: 10000, armed: 20 * time.Hour,
+			fills: []hyperliquid.UserFill{fill("-600", 13*time.Hour), fill("-100", time.Hour)}},
+		{name: "account wiped out", breaker: daily, balance: 0,
+			fills: []hyperliquid.UserFill{fill("-600", time.Hour)}, want: "daily drawdown of 100.00%"},
+
+		{name: "loss streak", breaker: streak, balance: 10000,
+			fills: []hyperliquid.UserFill{fill("-1", 3*time.Hour), fill("-1", 2*time.Hour), fill("-1", time.Hour)},
+			want:  "3 consecutive losing trades"},
+		{name: "opening fills do not break a streak", breaker: streak, balance: 10000,
+			fills: []hyperliquid.UserFill{fill("-1", 4*time.Hour), fill("0", 3*time.Hour), fill("-1", 2*time.Hour), fill("-1", time.Hour)},
+			want:  "consecutive"},
+		{name: "a win ends the streak", breaker: streak, balance: 10000,
+			fills: []hyperliquid.UserFill{fill("-1", 4*time.Hour), fill("2", 3*time.Hour), fill("-1", 2*time.Hour), fill("-1", time.Hour)}},
+		{name: "streak before the bot was armed", breaker: streak, balance: 10000, armed: 150 * time.Minute,
+			fills: []hyperliquid.UserFill{fill("-1", 3*time.Hour), fill("-1", 2*time.Hour), fill("-1", time.Hour)}},
+		{name: "streak across midnight", breaker: streak, balance: 10000,
+			fills: []hyperliquid.UserFill{fill("-1", 14*time.Hour), fill("-1", 13*time.Hour), fill("-1", time.Hour)},
+			want:  "consecutive"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			armed := time.Time{}
+			if tt.armed > 0 {
+				armed = now.Add(-tt.armed)
+			}
+			got := tt.breaker.Trip(Account{Now: now, Balance: tt.balance, Fills: tt.fills}, tt.unrealized, armed)
+			if tt.want == "" && got != "" {
+				t.Fatalf("Trip = %q, want it to hold", got)
+			}
+			if !strings.Contains(got, tt.want) {
+				t.Fatalf("Trip = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBreakerFailed(t *testing.T) {
+	tests := []struct {
+		max, failures int
+		want          bool
+	}{
+		{max: 0, failures: 100},
+		{max: 3, failures: 2},
+		{max: 3, failures: 3, want: true},
+		{max: 3, failures: 4, want: true},
+	}
+	for _, tt := range tests {
+		if got := (Breaker{MaxAPIFailures: tt.max}).Failed(tt.failures); got != tt.want {
+			t.Errorf("Failed(%d) with max %d = %v, want %v", tt.failures, tt.max, got, tt.want)
+		}
+	}
+}
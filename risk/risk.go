@@ -0,0 +1,283 @@
+// Package risk enforces the prompt's risk rules in code, between the agent's decision and execution.
+package risk
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"deepseek-trader/hyperliquid"
+	"deepseek-trader/models"
+)
+
+// Limits are the per-bot risk rules. Fractions are of account balance (0.015 = 1.5%).
+type Limits struct {
+	MaxRiskPerTrade  float64 `json:"maxRiskPerTrade"`
+	MaxPositionValue float64 `json:"maxPositionValue"`
+	// DefaultStopDistance sizes the risk of a trade without a stop-loss, as a fraction of the entry.
+	DefaultStopDistance float64       `json:"defaultStopDistance"`
+	SymbolCooldown      time.Duration `json:"symbolCooldown"`
+	MaxTradesPerHour    int           `json:"maxTradesPerHour"`
+	MaxTradesPerDay     int           `json:"maxTradesPerDay"`
+	// LossStreak consecutive losing closes pause new entries for LossPause.
+	LossStreak int           `json:"lossStreak"`
+	LossPause  time.Duration `json:"lossPause"`
+	// MinConfidence vetoes entries the agent is less confident in (0-100); zero disables it.
+	MinConfidence float64 `json:"minConfidence"`
+	// MaxTotalExposure caps the value of all open positions plus new entries; zero disables it.
+	MaxTotalExposure float64 `json:"maxTotalExposure"`
+}
+
+// DefaultLimits are the values written in the system prompt.
+func DefaultLimits() Limits {
+	return Limits{
+		MaxRiskPerTrade:     0.015,
+		MaxPositionValue:    0.20,
+		DefaultStopDistance: 0.03,
+		SymbolCooldown:      15 * time.Minute,
+		MaxTradesPerHour:    4,
+		MaxTradesPerDay:     12,
+		LossStreak:          3,
+		LossPause:           time.Hour,
+		MaxTotalExposure:    1,
+	}
+}
+
+// Account is the state a trade is checked against.
+type Account struct {
+	Now     time.Time
+	Balance float64
+	// Positions are the signed open position sizes by coin, as the clearinghouse reports them.
+	Positions map[string]float64
+	// Fills are the account's recent fills in any order; loss streaks are derived from them.
+	Fills []hyperliquid.UserFill
+	// Decisions should cover at least the last 24h for the daily trade limit.
+	Decisions []models.Decision
+	// Prices (mids by coin) value the open positions for the total exposure limit.
+	Prices map[string]float64
+	// Pending is the value of entries approved earlier in the same cycle, not in Positions yet.
+	Pending float64
+}
+
+// Order is the proposed entry.
+type Order struct {
+	Coin       string
+	IsBuy      bool
+	Size       float64
+	Price      float64 // expected entry: limit price or mid
+	StopLoss   float64
+	SzDecimals int
+	Confidence float64 // the agent's, 0-100
+	// Reverse marks an order that opens on the other side after the position is closed, so it is
+	// checked as a fresh entry.
+	Reverse bool
+}
+
+// Verdict is the outcome of a check. A vetoed order has Approved false and Reason set; an approved
+// order may have had its Size reduced, in which case Reason explains why.
+type Verdict struct {
+	Approved bool
+	Size     float64
+	Reason   string
+}
+
+// CheckAll checks the orders of one cycle in execution order. Each is checked like Check, with the
+// entries approved before it counted toward the total exposure.
+func (l Limits) CheckAll(acct Account, orders []Order) []Verdict {
+	verdicts := make([]Verdict, 0, len(orders))
+	for _, o := range orders {
+		v := l.Check(acct, o)
+		if v.Approved && !reduces(o, acct.Positions[hyperliquid.NormalizeSymbol(o.Coin)]) {
+			acct.Pending += v.Size * o.Price
+		}
+		verdicts = append(verdicts, v)
+	}
+	return verdicts
+}
+
+// Check applies every limit to the order. Orders that only reduce an existing position are always approved.
+func (l Limits) Check(acct Account, o Order) Verdict {
+	if !finite(o.Size, o.Price, o.StopLoss, acct.Balance) || o.Size <= 0 {
+		return Verdict{Reason: "risk: size must be positive and size, price, stop and balance finite"}
+	}
+	coin := hyperliquid.NormalizeSymbol(o.Coin)
+	pos := acct.Positions[coin]
+	if reduces(o, pos) {
+		return Verdict{Approved: true, Size: o.Size}
+	}
+	if o.Reverse {
+		pos = 0
+	}
+
+	if l.MinConfidence > 0 && o.Confidence < l.MinConfidence {
+		return Verdict{Reason: fmt.Sprintf("risk: confidence %v below the minimum of %v", o.Confidence, l.MinConfidence)}
+	}
+	if reason := l.paused(acct); reason != "" {
+		return Verdict{Reason: reason}
+	}
+	if reason := l.frequency(acct, coin); reason != "" {
+		return Verdict{Reason: reason}
+	}
+	if acct.Balance <= 0 || o.Price <= 0 {
+		return Verdict{Reason: "no balance or price to size the trade"}
+	}
+
+	size := o.Size
+	var notes []string
+
+	stop := math.Abs(o.Price - o.StopLoss)
+	if o.StopLoss <= 0 {
+		stop = o.Price * l.DefaultStopDistance
+	}
+	if l.MaxRiskPerTrade > 0 && stop > 0 {
+		if maxSize := acct.Balance * l.MaxRiskPerTrade / stop; size > maxSize {
+			size = maxSize
+			notes = append(notes, fmt.Sprintf("risk per trade capped at %.2f%% of balance", l.MaxRiskPerTrade*100))
+		}
+	}
+
+	if l.MaxPositionValue > 0 {
+		// Only the part of the position on the order's side counts toward its value.
+		existing := math.Max(pos, 0)
+		if !o.IsBuy {
+			existing = math.Max(-pos, 0)
+		}
+		if maxSize := acct.Balance*l.MaxPositionValue/o.Price - existing; size > maxSize {
+			size = maxSize
+			notes = append(notes, fmt.Sprintf("position value capped at %.0f%% of balance", l.MaxPositionValue*100))
+		}
+	}
+
+	if l.MaxTotalExposure > 0 {
+		if maxSize := (acct.Balance*l.MaxTotalExposure - exposure(acct, coin, o.Reverse)) / o.Price; size > maxSize {
+			size = maxSize
+			notes = append(notes, fmt.Sprintf("total exposure capped at %.0f%% of balance", l.MaxTotalExposure*100))
+		}
+	}
+
+	size = hyperliquid.FloorSize(math.Max(size, 0), o.SzDecimals)
+	if size <= 0 {
+		return Verdict{Reason: "risk: " + strings.Join(append(notes, "no size left"), "; ")}
+	}
+	if len(notes) == 0 {
+		return Verdict{Approved: true, Size: size}
+	}
+	return Verdict{
+		Approved: true,
+		Size:     size,
+		Reason:   fmt.Sprintf("risk: size clamped from %v to %v (%s)", o.Size, size, strings.Join(notes, "; ")),
+	}
+}
+
+// paused reports a loss-streak pause: the last LossStreak closing fills all lost and the most recent
+// one is younger than LossPause.
+func (l Limits) paused(acct Account) string {
+	if l.LossStreak <= 0 {
+		return ""
+	}
+	losses, last := lossStreak(acct.Fills, l.LossStreak)
+	if losses < l.LossStreak || acct.Now.Sub(last) >= l.LossPause {
+		return ""
+	}
+	return fmt.Sprintf("risk: %d consecutive losses, new entries paused until %s", losses, last.Add(l.LossPause).UTC().Format(time.RFC3339))
+}
+
+// lossStreak counts the most recent consecutive losing closing fills, up to limit, and returns the time
+// of the newest one.
+func lossStreak(fills []hyperliquid.UserFill, limit int) (int, time.Time) {
+	fills = append([]hyperliquid.UserFill(nil), fills...)
+	sort.Slice(fills, func(i, j int) bool { return fills[i].Time > fills[j].Time })
+
+	losses := 0
+	var last time.Time
+	for _, f := range fills {
+		pnl := parseF(f.ClosedPnl)
+		if pnl == 0 {
+			continue
+		}
+		if pnl > 0 {
+			break
+		}
+		if losses == 0 {
+			last = time.UnixMilli(f.Time)
+		}
+		losses++
+		if losses >= limit {
+			break
+		}
+	}
+	return losses, last
+}
+
+// frequency enforces the per-symbol cooldown and trade limits over executed entries; exits and
+// target adjustments on an open position do not count.
+func (l Limits) frequency(acct Account, coin string) string {
+	var lastTrade time.Time
+	hour, day := 0, 0
+	for _, d := range acct.Decisions {
+		if d.Status != models.DecisionExecuted || hyperliquid.NormalizeSymbol(d.Symbol) != coin || !isEntry(d.Action) {
+			continue
+		}
+		age := acct.Now.Sub(d.CreatedAt)
+		if age < time.Hour {
+			hour++
+		}
+		if age < 24*time.Hour {
+			day++
+		}
+		if d.CreatedAt.After(lastTrade) {
+			lastTrade = d.CreatedAt
+		}
+	}
+
+	switch {
+	case l.SymbolCooldown > 0 && !lastTrade.IsZero() && acct.Now.Sub(lastTrade) < l.SymbolCooldown:
+		return fmt.Sprintf("risk: %s is on cooldown until %s", coin, lastTrade.Add(l.SymbolCooldown).UTC().Format(time.RFC3339))
+	case l.MaxTradesPerHour > 0 && hour >= l.MaxTradesPerHour:
+		return fmt.Sprintf("risk: %d trades on %s in the last hour (max %d)", hour, coin, l.MaxTradesPerHour)
+	case l.MaxTradesPerDay > 0 && day >= l.MaxTradesPerDay:
+		return fmt.Sprintf("risk: %d trades on %s in the last 24h (max %d)", day, coin, l.MaxTradesPerDay)
+	}
+	return ""
+}
+
+// exposure is the value of the open positions and the cycle's pending entries. A reversed position
+// does not count since it is closed before the entry.
+func exposure(acct Account, coin string, reverse bool) float64 {
+	total := acct.Pending
+	for c, p := range acct.Positions {
+		if c != coin || !reverse {
+			total += math.Abs(p) * acct.Prices[c]
+		}
+	}
+	return total
+}
+
+// reduces reports whether the order only reduces the signed position pos.
+func reduces(o Order, pos float64) bool {
+	if o.Reverse {
+		return false
+	}
+	return (o.IsBuy && pos < 0 && o.Size <= -pos) || (!o.IsBuy && pos > 0 && o.Size <= pos)
+}
+
+func isEntry(action string) bool {
+	return action == "buy" || action == "sell" || action == "reverse"
+}
+
+func finite(vs ...float64) bool {
+	for _, v := range vs {
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			return false
+		}
+	}
+	return true
+}
+
+func parseF(s string) float64 {
+	f, _ := strconv.ParseFloat(s, 64)
+	return f
+}
@@ -0,0 +1,262 @@
+package risk
+
+import (
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"deepseek-trader/hyperliquid"
+	"deepseek-trader/models"
+)
+
+var now = time.Date(2025, 11, 20, 12, 0, 0, 0, time.UTC)
+
+func account() Account {
+	return Account{Now: now, Balance: 10000, Prices: map[string]float64{"BTC": 100, "ETH": 100}}
+}
+
+func buy(size float64) Order {
+	return Order{Coin: "BTC", IsBuy: true, Size: size, Price: 100, SzDecimals: 3, Confidence: 70}
+}
+
+func entry(action, status string, age time.Duration) models.Decision {
+	return models.Decision{Action: action, Symbol: "BTCUSDT", Status: status, CreatedAt: now.Add(-age)}
+}
+
+func fill(pnl string, age time.Duration) hyperliquid.UserFill {
+	return hyperliquid.UserFill{Coin: "BTC", ClosedPnl: pnl, Time: now.Add(-age).UnixMilli()}
+}
+
+func TestCheck(t *testing.T) {
+	tests := []struct {
+		name     string
+		limits   Limits
+		acct     func(*Account)
+		order    func(*Order)
+		size     float64 // order size before the tweaks in order
+		want     float64 // approved size, 0 for a veto
+		wantNote string  // substring of the reason, empty for an unclamped approval
+	}{
+		{name: "no limits", size: 1, want: 1},
+		{name: "zero size", size: 0, wantNote: "size must be positive"},
+		{name: "negative size", size: -1, wantNote: "size must be positive"},
+		{name: "NaN size", size: math.NaN(), wantNote: "finite"},
+		{name: "NaN price", size: 1, order: func(o *Order) { o.Price = math.NaN() }, wantNote: "finite"},
+		{name: "infinite stop", size: 1, order: func(o *Order) { o.StopLoss = math.Inf(-1) }, wantNote: "finite"},
+		{name: "NaN balance", size: 1, acct: func(a *Account) { a.Balance = math.NaN() }, wantNote: "finite"},
+		{name: "no balance", size: 1, acct: func(a *Account) { a.Balance = 0 }, wantNote: "no balance or price"},
+		{name: "no price", size: 1, order: func(o *Order) { o.Price = 0 }, wantNote: "no balance or price"},
+		{
+			name:   "reduce skips every limit",
+			limits: Limits{MinConfidence: 90, MaxPositionValue: 0.01},
+			acct:   func(a *Account) { a.Positions = map[string]float64{"BTC": -2} },
+			size:   2, want: 2,
+		},
+		{
+			name:   "reduce by symbol",
+			limits: Limits{MinConfidence: 90},
+			acct:   func(a *Account) { a.Positions = map[string]float64{"BTC": -2} },
+			order:  func(o *Order) { o.Coin = "BTCUSDT" },
+			size:   1, want: 1,
+		},
+		{
+			name:   "more than the position is an entry",
+			limits: Limits{MinConfidence: 90},
+			acct:   func(a *Account) { a.Positions = map[string]float64{"BTC": -2} },
+			size:   2.5, wantNote: "confidence 70 below the minimum of 90",
+		},
+		{name: "confidence at the minimum", limits: Limits{MinConfidence: 70}, size: 1, want: 1},
+		{name: "confidence below the minimum", limits: Limits{MinConfidence: 70.5}, size: 1, wantNote: "below the minimum"},
+		{
+			name:   "risk per trade at the limit",
+			limits: Limits{MaxRiskPerTrade: 0.01},
+			order:  func(o *Order) { o.StopLoss = 95 },
+			size:   20, want: 20,
+		},
+		{
+			name:   "risk per trade over the limit",
+			limits: Limits{MaxRiskPerTrade: 0.01},
+			order:  func(o *Order) { o.StopLoss = 95 },
+			size:   30, want: 20, wantNote: "risk per trade capped at 1.00%",
+		},
+		{
+			name:   "default stop distance",
+			limits: Limits{MaxRiskPerTrade: 0.01, DefaultStopDistance: 0.05},
+			size:   30, want: 20, wantNote: "risk per trade capped",
+		},
+		{
+			name:   "clamp is floored to szDecimals",
+			limits: Limits{MaxRiskPerTrade: 0.01},
+			order:  func(o *Order) { o.StopLoss = 97 },
+			size:   50, want: 33.333, wantNote: "size clamped from 50 to 33.333",
+		},
+		{
+			name:   "position value counts the same side",
+			limits: Limits{MaxPositionValue: 0.2},
+			acct:   func(a *Account) { a.Positions = map[string]float64{"BTC": 5} },
+			size:   20, want: 15, wantNote: "position value capped at 20%",
+		},
+		{
+			name:   "position value ignores the other side",
+			limits: Limits{MaxPositionValue: 0.2},
+			acct:   func(a *Account) { a.Positions = map[string]float64{"BTC": -5} },
+			size:   20, want: 20,
+		},
+		{
+			name:   "position value already full",
+			limits: Limits{MaxPositionValue: 0.2},
+			acct:   func(a *Account) { a.Positions = map[string]float64{"BTC": 20} },
+			size:   1, wantNote: "no size left",
+		},
+		{
+			name:   "total exposure counts open positions",
+			limits: Limits{MaxTotalExposure: 1},
+			acct:   func(a *Account) { a.Positions = map[string]float64{"ETH": 60} },
+			size:   50, want: 40, wantNote: "total exposure capped at 100%",
+		},
+		{
+			name:   "total exposure counts pending entries",
+			limits: Limits{MaxTotalExposure: 1},
+			acct:   func(a *Account) { a.Pending = 9000 },
+			size:   15, want: 10, wantNote: "total exposure capped",
+		},
+		{
+			name:   "total exposure exactly at the limit",
+			limits: Limits{MaxTotalExposure: 1},
+			acct:   func(a *Account) { a.Positions = map[string]float64{"ETH": 100} },
+			size:   1, wantNote: "total exposure capped at 100% of balance; no size left",
+		},
+		{
+			name:   "entry filling the exposure exactly",
+			limits: Limits{MaxTotalExposure: 1},
+			acct:   func(a *Account) { a.Positions = map[string]float64{"ETH": 60} },
+			size:   40, want: 40,
+		},
+		{
+			name:   "reverse does not count the closed position",
+			limits: Limits{MaxTotalExposure: 1, MaxPositionValue: 0.5},
+			acct:   func(a *Account) { a.Positions = map[string]float64{"BTC": -80} },
+			order:  func(o *Order) { o.Reverse = true },
+			size:   50, want: 50,
+		},
+		{
+			name:   "symbol cooldown",
+			limits: Limits{SymbolCooldown: 15 * time.Minute},
+			acct: func(a *Account) {
+				a.Decisions = []models.Decision{entry("buy", models.DecisionExecuted, 10*time.Minute)}
+			},
+			size: 1, wantNote: "BTC is on cooldown until 2025-11-20T12:05:00Z",
+		},
+		{
+			name:   "cooldown over",
+			limits: Limits{SymbolCooldown: 15 * time.Minute},
+			acct: func(a *Account) {
+				a.Decisions = []models.Decision{entry("buy", models.DecisionExecuted, 15*time.Minute)}
+			},
+			size: 1, want: 1,
+		},
+		{
+			name:   "cooldown ignores exits and rejected entries",
+			limits: Limits{SymbolCooldown: 15 * time.Minute},
+			acct: func(a *Account) {
+				a.Decisions = []models.Decision{
+					entry("close", models.DecisionExecuted, time.Minute),
+					entry("buy", models.DecisionRejected, time.Minute),
+					entry("reverse", models.DecisionPartial, time.Minute),
+				}
+			},
+			size: 1, want: 1,
+		},
+		{
+			name:   "hourly trades at the limit",
+			limits: Limits{MaxTradesPerHour: 2},
+			acct: func(a *Account) {
+				a.Decisions = []models.Decision{entry("buy", models.DecisionExecuted, 10*time.Minute), entry("sell", models.DecisionExecuted, 50*time.Minute)}
+			},
+			size: 1, wantNote: "2 trades on BTC in the last hour (max 2)",
+		},
+		{
+			name:   "hourly trades below the limit",
+			limits: Limits{MaxTradesPerHour: 2},
+			acct: func(a *Account) {
+				a.Decisions = []models.Decision{entry("buy", models.DecisionExecuted, 10*time.Minute), entry("sell", models.DecisionExecuted, 61*time.Minute)}
+			},
+			size: 1, want: 1,
+		},
+		{
+			name:   "daily trades",
+			limits: Limits{MaxTradesPerDay: 2},
+			acct: func(a *Account) {
+				a.Decisions = []models.Decision{entry("buy", models.DecisionExecuted, 2*time.Hour), entry("reverse", models.DecisionExecuted, 23*time.Hour)}
+			},
+			size: 1, wantNote: "in the last 24h (max 2)",
+		},
+		{
+			name:   "loss streak pauses entries",
+			limits: Limits{LossStreak: 2, LossPause: time.Hour},
+			acct: func(a *Account) {
+				a.Fills = []hyperliquid.UserFill{fill("-5", 50*time.Minute), fill("0", 15*time.Minute), fill("-1", 10*time.Minute)}
+			},
+			size: 1, wantNote: "2 consecutive losses, new entries paused until 2025-11-20T12:50:00Z",
+		},
+		{
+			name:   "a win ends the streak",
+			limits: Limits{LossStreak: 2, LossPause: time.Hour},
+			acct: func(a *Account) {
+				a.Fills = []hyperliquid.UserFill{fill("-5", 50*time.Minute), fill("3", 20*time.Minute), fill("-1", 10*time.Minute)}
+			},
+			size: 1, want: 1,
+		},
+		{
+			name:   "pause over",
+			limits: Limits{LossStreak: 2, LossPause: time.Hour},
+			acct:   func(a *Account) { a.Fills = []hyperliquid.UserFill{fill("-5", 2*time.Hour), fill("-1", time.Hour)} },
+			size:   1, want: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			acct := account()
+			if tt.acct != nil {
+				tt.acct(&acct)
+			}
+			o := buy(tt.size)
+			if tt.order != nil {
+				tt.order(&o)
+			}
+			v := tt.limits.Check(acct, o)
+			if v.Approved != (tt.want > 0) || (v.Approved && v.Size != tt.want) {
+				t.Fatalf("Check = %+v, want size %v", v, tt.want)
+			}
+			if tt.wantNote == "" && v.Reason != "" {
+				t.Fatalf("unexpected reason %q", v.Reason)
+			}
+			if !strings.Contains(v.Reason, tt.wantNote) {
+				t.Fatalf("reason = %q, want it to mention %q", v.Reason, tt.wantNote)
+			}
+		})
+	}
+}
+
+func TestCheckAll(t *testing.T) {
+	limits := Limits{MaxTotalExposure: 1}
+	acct := account()
+	acct.Positions = map[string]float64{"BTC": 10}
+
+	sellBTC := buy(5)
+	sellBTC.IsBuy = false
+	ethEntry := buy(60)
+	ethEntry.Coin = "ETH"
+
+	verdicts := limits.CheckAll(acct, []Order{sellBTC, buy(50), ethEntry})
+	want := []float64{5, 50, 40} // the reduce adds nothing; the first entry leaves 4000 of exposure
+	for i, v := range verdicts {
+		if !v.Approved || v.Size != want[i] {
+			t.Errorf("verdict %d = %+v, want size %v", i, v, want[i])
+		}
+	}
+	if acct.Pending != 0 {
+		t.Errorf("CheckAll changed the caller's pending value to %v", acct.Pending)
+	}
+}
@@ -0,0 +1,75 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"deepseek-trader/agent"
+	"deepseek-trader/models"
+	"deepseek-trader/repository"
+)
+
+// AgentCallsService archives what the agent was asked and answered in every decision cycle.
+type AgentCallsService struct {
+	repo *repository.AgentCallRepository
+}
+
+func NewAgentCallsService(repo *repository.AgentCallRepository) *AgentCallsService {
+	return &AgentCallsService{repo: repo}
+}
+
+// Archive stores a cycle's snapshot and model call and links the decisions it produced, if any, to it;
+// the first one is kept as the call's decision. callErr is the error the call failed with, if any.
+func (s *AgentCallsService) Archive(
+	ctx context.Context, userID int64, decisionIDs []int64, snap agent.Snapshot, call agent.Call, callErr error,
+) (models.AgentCall, error) {
+	raw, err := json.Marshal(snap)
+	if err != nil {
+		return models.AgentCall{}, err
+	}
+	c := models.AgentCall{
+		UserID:           &userID,
+		Provider:         call.Provider,
+		Model:            call.Model,
+		Snapshot:         raw,
+		SystemPrompt:     call.System,
+		UserPrompt:       call.User,
+		Response:         call.Response,
+		RepairPrompt:     call.RepairPrompt,
+		RepairResponse:   call.RepairResponse,
+		LatencyMS:        call.Latency.Milliseconds(),
+		PromptTokens:     call.PromptTokens,
+		CompletionTokens: call.CompletionTokens,
+	}
+	if len(decisionIDs) > 0 {
+		c.DecisionID = &decisionIDs[0]
+	}
+	if callErr != nil {
+		c.Error = callErr.Error()
+	}
+	if err := s.repo.Create(ctx, &c, decisionIDs); err != nil {
+		return models.AgentCall{}, err
+	}
+	return c, nil
+}
+
+// Call returns one of the user's archived calls in full.
+func (s *AgentCallsService) Call(ctx context.Context, userID, id int64) (models.AgentCall, error) {
+	return s.repo.Find(ctx, userID, id)
+}
+
+// ForDecision returns the archived call that produced the user's decision.
+func (s *AgentCallsService) ForDecision(ctx context.Context, userID, decisionID int64) (models.AgentCall, error) {
+	return s.repo.FindByDecision(ctx, userID, decisionID)
+}
+
+// List returns the user's latest calls, newest first, without their snapshots and prompts.
+func (s *AgentCallsService) List(ctx context.Context, userID int64, limit int) ([]models.AgentCall, error) {
+	return s.repo.List(ctx, userID, limit)
+}
+
+// Range returns the user's calls created in [from, to) in full, oldest first.
+func (s *AgentCallsService) Range(ctx context.Context, userID int64, from, to time.Time, limit int) ([]models.AgentCall, error) {
+	return s.repo.Range(ctx, userID, from, to, limit)
+}
@@ -0,0 +1,55 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"deepseek-trader/models"
+	"deepseek-trader/repository"
+)
+
+type BotsService struct {
+	repo *repository.BotRepository
+}
+
+func NewBotsService(repo *repository.BotRepository) *BotsService {
+	return &BotsService{repo: repo}
+}
+
+// Get returns the user's stored bot; ok is false when the user never started one.
+func (s *BotsService) Get(ctx context.Context, userID int64) (models.Bot, bool, error) {
+	b, err := s.repo.Find(ctx, userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.Bot{UserID: userID}, false, nil
+	}
+	if err != nil {
+		return models.Bot{}, false, err
+	}
+	return b, true, nil
+}
+
+// Save stores the bot's config and whether it should be running.
+func (s *BotsService) Save(ctx context.Context, userID int64, config []byte, enabled bool) (models.Bot, error) {
+	b := models.Bot{UserID: userID, Config: config, Enabled: enabled}
+	if err := s.repo.Upsert(ctx, &b); err != nil {
+		return models.Bot{}, err
+	}
+	return b, nil
+}
+
+// Trip stops the bot for good: it is disabled and records reason until Rearm.
+func (s *BotsService) Trip(ctx context.Context, userID int64, reason string) (time.Time, error) {
+	return s.repo.Trip(ctx, userID, reason)
+}
+
+// Rearm clears a trip; the bot stays stopped until it is started again.
+func (s *BotsService) Rearm(ctx context.Context, userID int64) error {
+	return s.repo.Rearm(ctx, userID)
+}
+
+// Enabled lists the bots that were running when the process last stopped.
+func (s *BotsService) Enabled(ctx context.Context) ([]models.Bot, error) {
+	return s.repo.ListEnabled(ctx)
+}
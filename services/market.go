@@ -0,0 +1,132 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+
+	"deepseek-trader/hyperliquid"
+	"deepseek-trader/models"
+	"deepseek-trader/repository"
+)
+
+// MaxMarketRows caps a single candle or book query.
+const MaxMarketRows = 5000
+
+// MarketService stores candles and sampled order books and serves them back by time range.
+type MarketService struct {
+	repo *repository.MarketRepository
+}
+
+func NewMarketService(repo *repository.MarketRepository) *MarketService {
+	return &MarketService{repo: repo}
+}
+
+// SaveCandles upserts exchange candles; a candle that is still forming is overwritten on the next save.
+func (s *MarketService) SaveCandles(ctx context.Context, candles []hyperliquid.Candle) error {
+	if len(candles) == 0 {
+		return nil
+	}
+	rows := make([]models.Candle, 0, len(candles))
+	for _, c := range candles {
+		rows = append(rows, models.Candle{
+			Coin:      c.Symbol,
+			Interval:  c.Interval,
+			StartTime: c.StartTime,
+			EndTime:   c.EndTime,
+			Open:      parseF(c.Open),
+			High:      parseF(c.High),
+			Low:       parseF(c.Low),
+			Close:     parseF(c.Close),
+			Volume:    parseF(c.Volume),
+			NumTrades: c.NumTrades,
+		})
+	}
+	return s.repo.UpsertCandles(ctx, rows)
+}
+
+// Candles returns stored candles starting in [from, to] (unix ms), oldest first.
+func (s *MarketService) Candles(ctx context.Context, coin, interval string, from, to int64, limit int) ([]models.Candle, error) {
+	return s.repo.Candles(ctx, hyperliquid.NormalizeSymbol(coin), interval, from, to, clampLimit(limit))
+}
+
+// CandleSnapshot serves stored candles in the exchange's format, so the store can stand in for the
+// Hyperliquid client as a backtest candle source.
+func (s *MarketService) CandleSnapshot(ctx context.Context, coin, interval string, startTime, endTime int64) ([]hyperliquid.Candle, error) {
+	var out []hyperliquid.Candle
+	for from := startTime; ; {
+		rows, err := s.repo.Candles(ctx, hyperliquid.NormalizeSymbol(coin), interval, from, endTime, MaxMarketRows)
+		if err != nil {
+			return nil, err
+		}
+		for _, c := range rows {
+			out = append(out, hyperliquid.Candle{
+				StartTime: c.StartTime,
+				EndTime:   c.EndTime,
+				Symbol:    c.Coin,
+				Interval:  c.Interval,
+				Open:      formatF(c.Open),
+				Close:     formatF(c.Close),
+				High:      formatF(c.High),
+				Low:       formatF(c.Low),
+				Volume:    formatF(c.Volume),
+				NumTrades: c.NumTrades,
+			})
+		}
+		if len(rows) < MaxMarketRows {
+			return out, nil
+		}
+		from = rows[len(rows)-1].StartTime + 1
+	}
+}
+
+// Gaps returns the [start, end] ranges of bar start times (unix ms) in [from, to] with no stored
+// candle. from and to must be aligned to the interval step.
+func (s *MarketService) Gaps(ctx context.Context, coin, interval string, from, to, step int64) ([][2]int64, error) {
+	times, err := s.repo.CandleTimes(ctx, coin, interval, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	var gaps [][2]int64
+	next := from
+	for _, t := range append(times, to+step) {
+		if t > next {
+			gaps = append(gaps, [2]int64{next, min(t-step, to)})
+		}
+		next = max(next, t+step)
+	}
+	return gaps, nil
+}
+
+// SaveBook stores an L2 snapshot.
+func (s *MarketService) SaveBook(ctx context.Context, book hyperliquid.OrderBookSnapshot) error {
+	if len(book.Levels) < 2 {
+		return nil
+	}
+	bids, err := json.Marshal(book.Levels[0])
+	if err != nil {
+		return err
+	}
+	asks, err := json.Marshal(book.Levels[1])
+	if err != nil {
+		return err
+	}
+	return s.repo.CreateBookSnapshot(ctx, &models.BookSnapshot{Coin: book.Coin, Time: book.Time, Bids: bids, Asks: asks})
+}
+
+// Books returns stored L2 snapshots taken in [from, to] (unix ms), oldest first.
+func (s *MarketService) Books(ctx context.Context, coin string, from, to int64, limit int) ([]models.BookSnapshot, error) {
+	return s.repo.BookSnapshots(ctx, hyperliquid.NormalizeSymbol(coin), from, to, clampLimit(limit))
+}
+
+func clampLimit(limit int) int {
+	if limit <= 0 || limit > MaxMarketRows {
+		return MaxMarketRows
+	}
+	return limit
+}
+
+func formatF(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
@@ -0,0 +1,75 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"deepseek-trader/models"
+	"deepseek-trader/repository"
+)
+
+// ErrOrderExists is returned by Create for an order whose cloid is already stored, i.e. a leg that was
+// already submitted once.
+var ErrOrderExists = errors.New("order already stored")
+
+type OrdersService struct {
+	repo *repository.OrderRepository
+}
+
+func NewOrdersService(repo *repository.OrderRepository) *OrdersService {
+	return &OrdersService{repo: repo}
+}
+
+// Create stores an order ticket before it is sent to the exchange. When the ticket's cloid is already
+// stored it returns the stored order and ErrOrderExists.
+func (s *OrdersService) Create(ctx context.Context, o models.Order) (models.Order, error) {
+	if o.Status == "" {
+		o.Status = models.OrderPending
+	}
+	if o.Kind == "" {
+		o.Kind = models.OrderKindEntry
+	}
+	err := s.repo.Create(ctx, &o)
+	if errors.Is(err, sql.ErrNoRows) && o.Cloid != "" {
+		existing, ferr := s.repo.FindByCloid(ctx, o.Cloid)
+		if ferr != nil {
+			return models.Order{}, ferr
+		}
+		return existing, fmt.Errorf("%w: order %d has cloid %s", ErrOrderExists, existing.ID, o.Cloid)
+	}
+	if err != nil {
+		return models.Order{}, err
+	}
+	return o, nil
+}
+
+// UpdateResult persists the exchange response (oid, status, fill) for an order.
+func (s *OrdersService) UpdateResult(ctx context.Context, o models.Order) (models.Order, error) {
+	if err := s.repo.UpdateResult(ctx, &o); err != nil {
+		return models.Order{}, err
+	}
+	return o, nil
+}
+
+// Active returns a user's orders still resting on the exchange.
+func (s *OrdersService) Active(ctx context.Context, userID int64) ([]models.Order, error) {
+	return s.repo.ListActive(ctx, userID)
+}
+
+// ByDecision returns all order legs placed for a decision.
+func (s *OrdersService) ByDecision(ctx context.Context, decisionID int64) ([]models.Order, error) {
+	return s.repo.ListByDecision(ctx, decisionID)
+}
+
+// ByExchangeOID finds the user's order tracked under an exchange oid.
+func (s *OrdersService) ByExchangeOID(ctx context.Context, userID, oid int64) (models.Order, error) {
+	return s.repo.FindByExchangeOID(ctx, userID, oid)
+}
+
+// Pending returns a user's orders that were stored but never got the exchange's answer, e.g. because the
+// process stopped while they were being submitted.
+func (s *OrdersService) Pending(ctx context.Context, userID int64) ([]models.Order, error) {
+	return s.repo.ListPending(ctx, userID)
+}
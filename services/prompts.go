@@ -0,0 +1,115 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"deepseek-trader/agent"
+	"deepseek-trader/models"
+	"deepseek-trader/repository"
+)
+
+// ErrInvalidPrompt wraps template errors of a prompt version that is being created.
+var ErrInvalidPrompt = errors.New("invalid prompt template")
+
+// ErrNoPromptToRollBack is returned when a bot already runs on the built-in prompt.
+var ErrNoPromptToRollBack = errors.New("the built-in prompt is active, nothing to roll back")
+
+// DefaultPromptName names the built-in templates, which act as version 0 of every bot.
+const DefaultPromptName = "default"
+
+// PromptsService manages the versioned prompt templates of each user's bot.
+type PromptsService struct {
+	repo *repository.PromptRepository
+}
+
+func NewPromptsService(repo *repository.PromptRepository) *PromptsService {
+	return &PromptsService{repo: repo}
+}
+
+// CreatePromptRequest is a new prompt version. The templates are text/template sources executed with agent.PromptData.
+type CreatePromptRequest struct {
+	Name     string `json:"name"`
+	System   string `json:"system"`
+	User     string `json:"user"`
+	Note     string `json:"note"`
+	Activate bool   `json:"activate"`
+}
+
+// Create compiles and stores a new version, and activates it when asked to.
+func (s *PromptsService) Create(ctx context.Context, userID int64, req CreatePromptRequest) (models.PromptVersion, error) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return models.PromptVersion{}, fmt.Errorf("%w: name is required", ErrInvalidPrompt)
+	}
+	if _, err := agent.NewPrompt(0, req.System, req.User); err != nil {
+		return models.PromptVersion{}, fmt.Errorf("%w: %v", ErrInvalidPrompt, err)
+	}
+	p := models.PromptVersion{UserID: userID, Name: name, SystemTemplate: req.System, UserTemplate: req.User, Note: req.Note}
+	if err := s.repo.Create(ctx, &p); err != nil {
+		return models.PromptVersion{}, err
+	}
+	if !req.Activate {
+		return p, nil
+	}
+	return s.Activate(ctx, userID, p.ID)
+}
+
+// List returns the user's stored versions, newest first.
+func (s *PromptsService) List(ctx context.Context, userID int64) ([]models.PromptVersion, error) {
+	return s.repo.List(ctx, userID)
+}
+
+// Version returns one of the user's stored versions.
+func (s *PromptsService) Version(ctx context.Context, userID, id int64) (models.PromptVersion, error) {
+	return s.repo.Find(ctx, userID, id)
+}
+
+// Active returns the user's active version, or the built-in templates as version 0 when none is active.
+func (s *PromptsService) Active(ctx context.Context, userID int64) (models.PromptVersion, error) {
+	p, err := s.repo.FindActive(ctx, userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		system, user := agent.DefaultTemplates()
+		return models.PromptVersion{UserID: userID, Name: DefaultPromptName, SystemTemplate: system, UserTemplate: user, Active: true}, nil
+	}
+	return p, err
+}
+
+// Activate makes the version the one the user's bot uses from its next cycle on.
+func (s *PromptsService) Activate(ctx context.Context, userID, id int64) (models.PromptVersion, error) {
+	if err := s.repo.Activate(ctx, userID, id); err != nil {
+		return models.PromptVersion{}, err
+	}
+	return s.Active(ctx, userID)
+}
+
+// Rollback activates the version before the active one, or the built-in templates when the first
+// stored version is active.
+func (s *PromptsService) Rollback(ctx context.Context, userID int64) (models.PromptVersion, error) {
+	cur, err := s.Active(ctx, userID)
+	if err != nil {
+		return models.PromptVersion{}, err
+	}
+	if cur.ID == 0 {
+		return models.PromptVersion{}, ErrNoPromptToRollBack
+	}
+	prev, err := s.repo.FindPrevious(ctx, userID, cur.Version)
+	if errors.Is(err, sql.ErrNoRows) {
+		return s.Activate(ctx, userID, 0)
+	}
+	if err != nil {
+		return models.PromptVersion{}, err
+	}
+	return s.Activate(ctx, userID, prev.ID)
+}
+
+// Compile compiles a version for the agent. Version 0 is the built-in prompt.
+func (s *PromptsService) Compile(p models.PromptVersion) (*agent.Prompt, error) {
+	if p.ID == 0 {
+		return agent.DefaultPrompt(), nil
+	}
+	return agent.NewPrompt(p.ID, p.SystemTemplate, p.UserTemplate)
+}
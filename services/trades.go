@@ -2,41 +2,31 @@ package services
 
 import (
 	"context"
+	"time"
 
-	"deepseek-trader/hyperliquid"
 	"deepseek-trader/models"
 	"deepseek-trader/repository"
 )
 
 type TradesService struct {
 	repo *repository.TradeRepository
-	hl   *hyperliquid.Client
 }
 
-func NewTradesService(repo *repository.TradeRepository, hl *hyperliquid.Client) *TradesService {
-	return &TradesService{repo: repo, hl: hl}
-}
-
-func (s *TradesService) Place(ctx context.Context, symbol, side string, qty, price float64) (models.Trade, error) {
-	_, _ = s.hl.PlaceOrder(ctx, symbol, side, qty, price)
-	t := models.Trade{Symbol: symbol, Side: side, Qty: qty, Price: price, PnL: 0}
-	if err := s.repo.Create(ctx, &t); err != nil {
-		return models.Trade{}, err
-	}
-	return t, nil
+func NewTradesService(repo *repository.TradeRepository) *TradesService {
+	return &TradesService{repo: repo}
 }
 
 // Record persists a trade-like decision without placing an exchange order.
-func (s *TradesService) Record(ctx context.Context, symbol, side string, qty, price float64) (models.Trade, error) {
-	t := models.Trade{Symbol: symbol, Side: side, Qty: qty, Price: price, PnL: 0}
+func (s *TradesService) Record(ctx context.Context, userID int64, symbol, side string, qty, price float64) (models.Trade, error) {
+	t := models.Trade{UserID: &userID, Symbol: symbol, Side: side, Qty: qty, Price: price, PnL: 0}
 	if err := s.repo.Create(ctx, &t); err != nil {
 		return models.Trade{}, err
 	}
 	return t, nil
 }
 
-func (s *TradesService) History(ctx context.Context, limit int) ([]models.Trade, error) {
-	return s.repo.List(ctx, limit)
+func (s *TradesService) History(ctx context.Context, userID int64, limit int) ([]models.Trade, error) {
+	return s.repo.List(ctx, userID, limit)
 }
 
 // RecordDecision persists an AI decision to the decisions table.
@@ -47,7 +37,27 @@ func (s *TradesService) RecordDecision(ctx context.Context, d models.Decision) (
 	return d, nil
 }
 
-// LatestDecisions retrieves the latest decisions with limit.
-func (s *TradesService) LatestDecisions(ctx context.Context, limit int) ([]models.Decision, error) {
-	return s.repo.LatestDecisions(ctx, limit)
+// LatestDecisions retrieves a user's latest decisions with limit.
+func (s *TradesService) LatestDecisions(ctx context.Context, userID int64, limit int) ([]models.Decision, error) {
+	return s.repo.LatestDecisions(ctx, userID, limit)
+}
+
+// DecisionsSince returns every decision of a user created at or after since, newest first.
+func (s *TradesService) DecisionsSince(ctx context.Context, userID int64, since time.Time) ([]models.Decision, error) {
+	return s.repo.DecisionsSince(ctx, userID, since)
+}
+
+// Decision loads a single decision by id.
+func (s *TradesService) Decision(ctx context.Context, id int64) (models.Decision, error) {
+	return s.repo.FindDecision(ctx, id)
+}
+
+// DecisionsByCall returns the user's decisions made from one agent call, in execution order.
+func (s *TradesService) DecisionsByCall(ctx context.Context, userID, callID int64) ([]models.Decision, error) {
+	return s.repo.DecisionsByCall(ctx, userID, callID)
+}
+
+// MarkDecision stores the execution outcome of a decision.
+func (s *TradesService) MarkDecision(ctx context.Context, id int64, status, reason string) error {
+	return s.repo.UpdateDecisionStatus(ctx, id, status, reason)
 }
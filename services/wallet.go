@@ -5,7 +5,6 @@ import (
 	"errors"
 
 	"deepseek-trader/config"
-	"deepseek-trader/hyperliquid"
 	"deepseek-trader/models"
 	cryptoutil "deepseek-trader/pkg/crypto"
 	"deepseek-trader/repository"
@@ -13,12 +12,11 @@ import (
 
 type WalletService struct {
 	repo *repository.WalletRepository
-	hl   *hyperliquid.Client
 	cfg  *config.Settings
 }
 
-func NewWalletService(repo *repository.WalletRepository, hl *hyperliquid.Client, cfg *config.Settings) *WalletService {
-	return &WalletService{repo: repo, hl: hl, cfg: cfg}
+func NewWalletService(repo *repository.WalletRepository, cfg *config.Settings) *WalletService {
+	return &WalletService{repo: repo, cfg: cfg}
 }
 
 type ConnectRequest struct {
@@ -31,10 +29,6 @@ func (s *WalletService) Connect(ctx context.Context, req ConnectRequest, userID
 		return nil, errors.New("address required")
 	}
 
-	// Try to validate by fetching balance (sandbox/live)
-	// Update runtime wallet address for live client
-	s.hl.SetWalletAddress(req.Address)
-
 	encrypted := ""
 	if req.APIKey != "" {
 		encKey := []byte(s.cfg.SecretKey)
@@ -58,6 +52,5 @@ func (s *WalletService) FindLatestByUser(ctx context.Context, userID int64) (mod
 }
 
 func (s *WalletService) Disconnect(ctx context.Context, userID int64) error {
-	s.hl.SetWalletAddress("")
 	return s.repo.DeleteByUser(ctx, userID)
 }